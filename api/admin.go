@@ -0,0 +1,188 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/hobeone/gonab/categorize"
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/unrolled/render.v1"
+)
+
+// adminRelease is what the admin endpoints return for a release.
+type adminRelease struct {
+	ID             int64
+	Name           string
+	SearchName     string
+	Category       types.Category
+	Status         string
+	NameLocked     bool
+	CategoryLocked bool
+	StatusLocked   bool
+}
+
+// adminAuth only lets requests with the admin key as apikey through.  An
+// empty key disables the admin endpoints.
+func adminAuth(key string, h http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if key == "" {
+			http.Error(rw, "Admin API disabled", http.StatusForbidden)
+			return
+		}
+		given := r.FormValue("apikey")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
+			http.Error(rw, "Invalid admin key", http.StatusUnauthorized)
+			return
+		}
+		h(rw, r)
+	}
+}
+
+// adminReleaseID returns the release id argument of an admin request.
+func adminReleaseID(r *http.Request) (int64, error) {
+	id := r.FormValue("id")
+	if id == "" {
+		return 0, fmt.Errorf("Missing Required Argument(s): id")
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+// releaseEditFromForm returns the edits given in the form of an admin
+// request.  Only the arguments present are changed.
+func releaseEditFromForm(r *http.Request) (db.ReleaseEdit, error) {
+	edit := db.ReleaseEdit{}
+	if _, ok := r.Form["name"]; ok {
+		name := r.Form.Get("name")
+		edit.Name = &name
+	}
+	if _, ok := r.Form["searchname"]; ok {
+		searchName := r.Form.Get("searchname")
+		edit.SearchName = &searchName
+	}
+	if _, ok := r.Form["cat"]; ok {
+		c, err := strconv.ParseInt(r.Form.Get("cat"), 10, 64)
+		if err != nil {
+			return edit, fmt.Errorf("Invalid cat: %v", err)
+		}
+		cat := types.Category(c)
+		edit.Category = &cat
+	}
+	if _, ok := r.Form["hidden"]; ok {
+		hidden, err := strconv.ParseBool(r.Form.Get("hidden"))
+		if err != nil {
+			return edit, fmt.Errorf("Invalid hidden: %v", err)
+		}
+		edit.Hidden = &hidden
+	}
+	return edit, nil
+}
+
+func adminEditReleaseHandler(rw http.ResponseWriter, r *http.Request) {
+	rend := render.New()
+	err := r.ParseForm()
+	if err != nil {
+		rend.Text(rw, http.StatusBadRequest, fmt.Sprintf("Error: %v", err))
+		return
+	}
+	id, err := adminReleaseID(r)
+	if err != nil {
+		rend.Text(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+	edit, err := releaseEditFromForm(r)
+	if err != nil {
+		rend.Text(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dbh := getDB(r)
+	rel, err := dbh.EditRelease(id, edit)
+	if err == gorm.RecordNotFound {
+		rend.Text(rw, http.StatusNotFound, "No release found")
+		return
+	}
+	if err != nil {
+		rend.Text(rw, http.StatusBadRequest, fmt.Sprintf("Error: %v", err))
+		return
+	}
+	rend.JSON(rw, http.StatusOK, adminRelease{
+		ID:             rel.ID,
+		Name:           rel.Name,
+		SearchName:     rel.SearchName,
+		Category:       rel.CategoryName(),
+		Status:         types.ReleaseStatusNames[rel.Status],
+		NameLocked:     rel.NameLocked,
+		CategoryLocked: rel.CategoryLocked,
+		StatusLocked:   rel.StatusLocked,
+	})
+}
+
+func adminDeleteReleaseHandler(rw http.ResponseWriter, r *http.Request) {
+	rend := render.New()
+	id, err := adminReleaseID(r)
+	if err != nil {
+		rend.Text(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dbh := getDB(r)
+	err = dbh.DeleteRelease(id)
+	if err == gorm.RecordNotFound {
+		rend.Text(rw, http.StatusNotFound, "No release found")
+		return
+	}
+	if err != nil {
+		rend.Text(rw, http.StatusInternalServerError, fmt.Sprintf("Error: %v", err))
+		return
+	}
+	rend.Text(rw, http.StatusOK, fmt.Sprintf("Deleted release %d", id))
+}
+
+// adminCategorization is how the categorizer handled a release name.  Steps
+// is empty when a category rule decided.
+type adminCategorization struct {
+	Category     types.Category
+	CategoryName string
+	RuleID       int64
+	Steps        []categorize.Step
+}
+
+func adminCategorizeHandler(rw http.ResponseWriter, r *http.Request) {
+	rend := render.New()
+	name := r.FormValue("name")
+	if name == "" {
+		rend.Text(rw, http.StatusBadRequest, "Missing Required Argument(s): name")
+		return
+	}
+	group := r.FormValue("group")
+	var size int64
+	if s := r.FormValue("size"); s != "" {
+		var err error
+		size, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			rend.Text(rw, http.StatusBadRequest, fmt.Sprintf("Invalid size: %v", err))
+			return
+		}
+	}
+
+	dbh := getDB(r)
+	rules, err := dbh.GetCategoryRules(false)
+	if err != nil {
+		rend.Text(rw, http.StatusInternalServerError, fmt.Sprintf("Error: %v", err))
+		return
+	}
+	res := adminCategorization{Steps: []categorize.Step{}}
+	cat, rule := categorize.FromRules(rules, name, group, size)
+	if rule != nil {
+		res.RuleID = rule.ID
+	} else {
+		cat, res.Steps = categorize.Explain(name, group)
+	}
+	res.Category = cat
+	res.CategoryName = cat.String()
+	rend.JSON(rw, http.StatusOK, res)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+)
+
+func TestAdminRelease(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	rel := types.Release{Name: "bad name", SearchName: "bad name", Hash: "h1"}
+	if err := dbh.DB.Save(&rel).Error; err != nil {
+		t.Fatalf("Error saving release: %v", err)
+	}
+	n := configRoutes(dbh, "secret")
+
+	tests := []struct {
+		method string
+		url    string
+		code   int
+	}{
+		{"POST", "/gonab/admin/release?id=1&name=Good.Name-GRP", http.StatusUnauthorized},
+		{"POST", "/gonab/admin/release?apikey=wrong&id=1&name=Good.Name-GRP", http.StatusUnauthorized},
+		{"POST", "/gonab/admin/release?apikey=secret&name=Good.Name-GRP", http.StatusBadRequest},
+		{"POST", "/gonab/admin/release?apikey=secret&id=2&name=Good.Name-GRP", http.StatusNotFound},
+		{"POST", "/gonab/admin/release?apikey=secret&id=1&cat=nope", http.StatusBadRequest},
+		{"POST", "/gonab/admin/release?apikey=secret&id=1&name=Good.Name-GRP&cat=2040&hidden=true", http.StatusOK},
+	}
+	for _, tc := range tests {
+		respRec := serve(t, n, tc.method, tc.url)
+		if respRec.Code != tc.code {
+			t.Errorf("%s %s: expected status %d, got %d: %s", tc.method, tc.url, tc.code, respRec.Code, respRec.Body)
+		}
+	}
+
+	edited, err := dbh.FindReleaseByHash("h1")
+	if err != nil {
+		t.Fatalf("Error finding release: %v", err)
+	}
+	if edited.Name != "Good.Name-GRP" || edited.CategoryName() != types.Movie_HD || edited.Status != types.ReleaseHidden {
+		t.Errorf("Release not edited: %s %d %d", edited.Name, edited.CategoryName(), edited.Status)
+	}
+	if !edited.NameLocked || !edited.CategoryLocked || !edited.StatusLocked {
+		t.Errorf("Edited fields of release not locked")
+	}
+
+	respRec := serve(t, n, "DELETE", "/gonab/admin/release?apikey=secret&id=1")
+	if respRec.Code != http.StatusOK || !strings.Contains(respRec.Body.String(), "Deleted release 1") {
+		t.Fatalf("Error deleting release: %d %s", respRec.Code, respRec.Body)
+	}
+	if _, err := dbh.FindReleaseByHash("h1"); err == nil {
+		t.Errorf("Release wasn't deleted")
+	}
+}
+
+func TestAdminDisabled(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	n := configRoutes(dbh, "")
+
+	respRec := serve(t, n, "DELETE", "/gonab/admin/release?apikey=&id=1")
+	if respRec.Code != http.StatusForbidden {
+		t.Fatalf("Expected admin API to be disabled, got %d", respRec.Code)
+	}
+}
+
+func TestAdminCategorize(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	n := configRoutes(dbh, "secret")
+
+	respRec := serve(t, n, "GET", "/gonab/admin/categorize?apikey=secret&group=alt.binaries.teevee")
+	if respRec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected a missing name to fail, got %d", respRec.Code)
+	}
+
+	respRec = serve(t, n, "GET", "/gonab/admin/categorize?apikey=secret&group=alt.binaries.teevee&name=Sleepy.Hollow.S03E11.720p.HDTV.x264-AVS")
+	if respRec.Code != http.StatusOK {
+		t.Fatalf("Error explaining category: %d %s", respRec.Code, respRec.Body)
+	}
+	res := adminCategorization{}
+	if err := json.Unmarshal(respRec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if res.Category != types.TV_HD || res.RuleID != 0 {
+		t.Errorf("Expected %s without a rule, got %s and rule %d", types.TV_HD, res.Category, res.RuleID)
+	}
+	last := res.Steps[len(res.Steps)-1]
+	if last.Test != "isTV" || len(last.Steps) == 0 || last.Steps[len(last.Steps)-1].Test != "isHDTV" {
+		t.Errorf("Expected isTV to decide with isHDTV, got %+v", res.Steps)
+	}
+
+	rule := &types.CategoryRule{NameRegex: `Sleepy\.Hollow`, CategoryID: int64(types.TV_Foreign), Enabled: true}
+	if err := dbh.AddCategoryRule(rule); err != nil {
+		t.Fatalf("Error adding rule: %v", err)
+	}
+	respRec = serve(t, n, "GET", "/gonab/admin/categorize?apikey=secret&group=alt.binaries.teevee&name=Sleepy.Hollow.S03E11.720p.HDTV.x264-AVS")
+	res = adminCategorization{}
+	if err := json.Unmarshal(respRec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if res.Category != types.TV_Foreign || res.RuleID != rule.ID || len(res.Steps) != 0 {
+		t.Errorf("Expected rule %d to decide, got %+v", rule.ID, res)
+	}
+}
@@ -26,17 +26,28 @@ const (
 // RunAPIServer sets up and starts a server to provide the NewzNab API
 func RunAPIServer(cfg *config.Config) {
 	dbh := db.NewDBHandle(cfg.DB.Name, cfg.DB.Username, cfg.DB.Password, cfg.DB.Verbose)
-	n := configRoutes(dbh)
+	dbh.NZBDir = cfg.NZB.Dir
+	err := dbh.CheckCategories()
+	if err != nil {
+		logrus.Fatalf("%v.  Fix them with gonab categories sync.", err)
+	}
+	n := configRoutes(dbh, cfg.API.AdminKey)
 	fmt.Println("Starting server on :8078")
 	n.Run(":8078")
 }
 
-func configRoutes(dbh *db.Handle) *negroni.Negroni {
+func configRoutes(dbh *db.Handle, adminKey string) *negroni.Negroni {
 	r := mux.NewRouter().PathPrefix(webroot).Subrouter()
 	r.HandleFunc("/api", capsHandler).Queries("t", "caps")
 	r.HandleFunc("/api", searchHandler).Queries("t", "search")
 	r.HandleFunc("/api", tvSearchHandler).Queries("t", "tvsearch")
+	r.HandleFunc("/api", movieSearchHandler).Queries("t", "movie")
+	r.HandleFunc("/api", musicSearchHandler).Queries("t", "music")
+	r.HandleFunc("/api", bookSearchHandler).Queries("t", "book")
 	r.HandleFunc("/getnzb", nzbDownloadHandler)
+	r.HandleFunc("/admin/release", adminAuth(adminKey, adminEditReleaseHandler)).Methods("POST")
+	r.HandleFunc("/admin/release", adminAuth(adminKey, adminDeleteReleaseHandler)).Methods("DELETE")
+	r.HandleFunc("/admin/categorize", adminAuth(adminKey, adminCategorizeHandler)).Methods("GET")
 	r.HandleFunc("/", homeHandler)
 	n := negroni.Classic()
 	n.Use(gzip.Gzip(gzip.DefaultCompression))
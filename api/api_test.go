@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serve sends a request to the API routes in n and returns the response.
+func serve(t *testing.T, n http.Handler, method, url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("Error setting up request: %s", err)
+	}
+	respRec := httptest.NewRecorder()
+	n.ServeHTTP(respRec, req)
+	return respRec
+}
+
+// searchTitles returns the titles of the releases in the search response to
+// url.
+func searchTitles(t *testing.T, url string, respRec *httptest.ResponseRecorder) []string {
+	var res struct {
+		Items []struct {
+			Title string `xml:"title"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(respRec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s: error parsing response: %v", url, err)
+	}
+	titles := make([]string, len(res.Items))
+	for i, item := range res.Items {
+		titles[i] = item.Title
+	}
+	return titles
+}
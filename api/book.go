@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/unrolled/render.v1"
+
+	"github.com/mholt/binding"
+)
+
+type bookSearchReq struct {
+	searchReq
+	Author string
+	Title  string
+}
+
+func (s *bookSearchReq) FieldMap(req *http.Request) binding.FieldMap {
+	fm := s.searchReq.FieldMap(req)
+	// Books can be searched by author and title instead of a query.
+	fm[&s.Query] = "q"
+	fm[&s.Author] = "author"
+	fm[&s.Title] = "title"
+	return fm
+}
+
+func bookSearchHandler(rw http.ResponseWriter, r *http.Request) {
+	searchrequest := new(bookSearchReq)
+	errs := binding.Bind(r, searchrequest)
+	if errs.Handle(rw) {
+		return
+	}
+	rend := render.New()
+
+	if searchrequest.Limit == 0 {
+		searchrequest.Limit = defaultLimit
+	}
+
+	search := db.BookSearch{
+		Query:  searchrequest.Query,
+		Author: searchrequest.Author,
+		Title:  searchrequest.Title,
+		Offset: searchrequest.Offset,
+		Limit:  searchrequest.Limit,
+	}
+	for _, c := range searchrequest.Categories {
+		search.Categories = append(search.Categories, types.CategoryFromInt(c))
+	}
+	dbh := getDB(r)
+	releases, err := dbh.SearchBookReleases(search)
+	if err != nil {
+		rend.Text(rw, http.StatusInternalServerError, fmt.Sprintf("Error: %v", err))
+		return
+	}
+
+	writeSearchResponse(rw, r, "gonab book search", searchrequest.Offset, releases)
+}
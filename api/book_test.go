@@ -0,0 +1,65 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+)
+
+func TestBookSearch(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	releases := []struct {
+		name string
+		cat  types.Category
+	}{
+		{"Andy Weir - The Martian (2014) (retail) (epub)", types.Book_Ebook},
+		{"Andy Weir - The Martian (2014) [M4B]", types.Audio_Audiobook},
+		{"Stephen.King-The.Stand.Unabridged-AUDIOBOOK", types.Audio_Audiobook},
+	}
+	for i, r := range releases {
+		rel := types.Release{Name: r.name, SearchName: r.name, Hash: string(rune('a' + i)), CategoryID: sql.NullInt64{Int64: int64(r.cat), Valid: true}}
+		if err := dbh.DB.Save(&rel).Error; err != nil {
+			t.Fatalf("Error saving release: %v", err)
+		}
+	}
+	if _, _, err := dbh.BackfillBookInfo(10); err != nil {
+		t.Fatalf("Error parsing book info: %v", err)
+	}
+	n := configRoutes(dbh, "")
+
+	tests := []struct {
+		url   string
+		code  int
+		count int
+	}{
+		{"/gonab/api?t=book&author=andy+weir&apikey=123", http.StatusOK, 2},
+		{"/gonab/api?t=book&author=andy+weir&title=the+martian&cat=3030&apikey=123", http.StatusOK, 1},
+		{"/gonab/api?t=book&title=the+stand&apikey=123", http.StatusOK, 1},
+		{"/gonab/api?t=book&q=stand&apikey=123", http.StatusOK, 1},
+		{"/gonab/api?t=book&author=nobody&apikey=123", http.StatusOK, 0},
+		{"/gonab/api?t=book&apikey=123", http.StatusOK, 3},
+		{"/gonab/api?t=book&offset=none&apikey=123", http.StatusBadRequest, 0},
+	}
+	for _, tc := range tests {
+		respRec := serve(t, n, "GET", tc.url)
+		if respRec.Code != tc.code {
+			t.Fatalf("%s: expected status %d, got %d: %s", tc.url, tc.code, respRec.Code, respRec.Body)
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+		titles := searchTitles(t, tc.url, respRec)
+		if len(titles) != tc.count {
+			t.Errorf("%s: expected %d releases, got %d", tc.url, tc.count, len(titles))
+		}
+	}
+
+	respRec := serve(t, n, "GET", "/gonab/api?t=caps")
+	if !strings.Contains(respRec.Body.String(), "book-search") {
+		t.Errorf("Caps don't advertise book-search: %s", respRec.Body)
+	}
+}
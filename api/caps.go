@@ -105,6 +105,21 @@ func capsHandler(rw http.ResponseWriter, r *http.Request) {
 			Available:       "yes",
 			SupportedParams: "q,rid,tvdbid,vid,traktid,tvmazeid,imdbid,tmdbid,season,ep",
 		},
+		{
+			Name:            "movie-search",
+			Available:       "yes",
+			SupportedParams: "q,imdbid,tmdbid",
+		},
+		{
+			Name:            "music-search",
+			Available:       "yes",
+			SupportedParams: "q,artist,album,year",
+		},
+		{
+			Name:            "book-search",
+			Available:       "yes",
+			SupportedParams: "q,author,title",
+		},
 	}
 	b := bytes.NewBuffer([]byte{})
 	capsResponseTemplate.Execute(b, cr)
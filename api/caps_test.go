@@ -10,7 +10,7 @@ import (
 
 func TestCaps(t *testing.T) {
 	dbh := db.NewMemoryDBHandle(false, true)
-	n := configRoutes(dbh)
+	n := configRoutes(dbh, "")
 
 	req, err := http.NewRequest("GET", "/gonab/api?t=caps&o=json", nil)
 	if err != nil {
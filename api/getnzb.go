@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -53,8 +54,14 @@ func nzbDownloadHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	doc, err := dbh.GetNZB(rel)
+	if err != nil {
+		http.Error(rw, "Error reading NZB", http.StatusInternalServerError)
+		return
+	}
+
 	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.nzb", rel.Name))
 	rw.Header().Set("Content-Type", "application/x-nzb")
-	rw.Header().Set("Content-Length", strconv.Itoa(len(rel.NZB)))
-	fmt.Fprintf(rw, rel.NZB)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(doc)))
+	io.WriteString(rw, doc)
 }
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+)
+
+func TestNZBDownload(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	doc := `<?xml version="1.0" encoding="UTF-8"?><nzb></nzb>`
+	rel := types.Release{Name: "rel", Hash: "h1", NZB: doc}
+	if err := dbh.DB.Save(&rel).Error; err != nil {
+		t.Fatalf("Error saving release: %v", err)
+	}
+	if _, err := dbh.ConvertNZBs(10); err != nil {
+		t.Fatalf("Error converting NZBs: %v", err)
+	}
+	n := configRoutes(dbh, "")
+
+	respRec := serve(t, n, "GET", "/gonab/getnzb?h=h1&apikey=123")
+
+	if respRec.Code != http.StatusOK {
+		t.Fatalf("Error downloading NZB: %d", respRec.Code)
+	}
+	if respRec.Body.String() != doc {
+		t.Fatalf("Expected NZB %q, got %q", doc, respRec.Body.String())
+	}
+}
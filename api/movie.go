@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/unrolled/render.v1"
+
+	"github.com/mholt/binding"
+)
+
+type movieSearchReq struct {
+	searchReq
+	IMDBID string
+	TMDBID int64
+}
+
+func (s *movieSearchReq) FieldMap(req *http.Request) binding.FieldMap {
+	fm := s.searchReq.FieldMap(req)
+	// Movies can be searched by ID instead of title.
+	fm[&s.Query] = "q"
+	fm[&s.IMDBID] = "imdbid"
+	fm[&s.TMDBID] = "tmdbid"
+	return fm
+}
+
+func movieSearchHandler(rw http.ResponseWriter, r *http.Request) {
+	searchrequest := new(movieSearchReq)
+	errs := binding.Bind(r, searchrequest)
+	if errs.Handle(rw) {
+		return
+	}
+	rend := render.New()
+
+	if searchrequest.Limit == 0 {
+		searchrequest.Limit = defaultLimit
+	}
+
+	search := db.MovieSearch{
+		Title:  searchrequest.Query,
+		TMDBID: searchrequest.TMDBID,
+		Offset: searchrequest.Offset,
+		Limit:  searchrequest.Limit,
+	}
+	if searchrequest.IMDBID != "" {
+		id, err := processing.ParseIMDBID(searchrequest.IMDBID)
+		if err != nil {
+			rend.Text(rw, http.StatusBadRequest, fmt.Sprintf("Error: %v", err))
+			return
+		}
+		search.IMDBID = id
+	}
+	for _, c := range searchrequest.Categories {
+		search.Categories = append(search.Categories, types.CategoryFromInt(c))
+	}
+	dbh := getDB(r)
+	releases, err := dbh.SearchMovieReleases(search)
+	if err != nil {
+		rend.Text(rw, http.StatusInternalServerError, fmt.Sprintf("Error: %v", err))
+		return
+	}
+
+	writeSearchResponse(rw, r, "gonab movie search", searchrequest.Offset, releases)
+}
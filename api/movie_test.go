@@ -0,0 +1,66 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+)
+
+func TestMovieSearch(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	movies := []*types.Movie{{IMDBID: 3659388, TMDBID: 286217, Title: "The Martian", Year: 2015}}
+	if _, _, err := dbh.SaveMovies(movies); err != nil {
+		t.Fatalf("Error saving movie: %v", err)
+	}
+	names := []string{
+		"The.Martian.2015.1080p.BluRay.x264-SPARKS",
+		"The.Martian.2015.720p.WEB-DL.DD5.1.H264-FGT",
+		"Inside.Out.2015.1080p.BluRay.x264-SPARKS",
+	}
+	for i, name := range names {
+		rel := types.Release{Name: name, Hash: string(rune('a' + i)), CategoryID: sql.NullInt64{Int64: int64(types.Movie_HD), Valid: true}}
+		if err := dbh.DB.Save(&rel).Error; err != nil {
+			t.Fatalf("Error saving release: %v", err)
+		}
+	}
+	if _, _, err := dbh.BackfillMovieInfo(10); err != nil {
+		t.Fatalf("Error parsing movie info: %v", err)
+	}
+	n := configRoutes(dbh, "")
+
+	tests := []struct {
+		url   string
+		code  int
+		count int
+	}{
+		{"/gonab/api?t=movie&imdbid=3659388&apikey=123", http.StatusOK, 2},
+		{"/gonab/api?t=movie&imdbid=tt3659388&apikey=123", http.StatusOK, 2},
+		{"/gonab/api?t=movie&tmdbid=286217&apikey=123", http.StatusOK, 2},
+		{"/gonab/api?t=movie&imdbid=1&apikey=123", http.StatusOK, 0},
+		{"/gonab/api?t=movie&q=inside+out&apikey=123", http.StatusOK, 1},
+		{"/gonab/api?t=movie&apikey=123", http.StatusOK, 3},
+		{"/gonab/api?t=movie&imdbid=nope&apikey=123", http.StatusBadRequest, 0},
+	}
+	for _, tc := range tests {
+		respRec := serve(t, n, "GET", tc.url)
+		if respRec.Code != tc.code {
+			t.Fatalf("%s: expected status %d, got %d: %s", tc.url, tc.code, respRec.Code, respRec.Body)
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+		titles := searchTitles(t, tc.url, respRec)
+		if len(titles) != tc.count {
+			t.Errorf("%s: expected %d releases, got %d", tc.url, tc.count, len(titles))
+		}
+	}
+
+	respRec := serve(t, n, "GET", "/gonab/api?t=caps")
+	if !strings.Contains(respRec.Body.String(), "movie-search") {
+		t.Errorf("Caps don't advertise movie-search: %s", respRec.Body)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/unrolled/render.v1"
+
+	"github.com/mholt/binding"
+)
+
+type musicSearchReq struct {
+	searchReq
+	Artist string
+	Album  string
+	Year   int
+	// Release names don't carry the label and genre, searches by them are
+	// rejected instead of returning every release.
+	Label string
+	Genre string
+}
+
+func (s *musicSearchReq) FieldMap(req *http.Request) binding.FieldMap {
+	fm := s.searchReq.FieldMap(req)
+	// Music can be searched by artist and album instead of a query.
+	fm[&s.Query] = "q"
+	fm[&s.Artist] = "artist"
+	fm[&s.Album] = "album"
+	fm[&s.Year] = "year"
+	fm[&s.Label] = "label"
+	fm[&s.Genre] = "genre"
+	return fm
+}
+
+func musicSearchHandler(rw http.ResponseWriter, r *http.Request) {
+	searchrequest := new(musicSearchReq)
+	errs := binding.Bind(r, searchrequest)
+	if errs.Handle(rw) {
+		return
+	}
+	if searchrequest.Label != "" || searchrequest.Genre != "" {
+		writeNewznabError(rw, newznabIncorrectParameter, "Searching by label or genre is not supported")
+		return
+	}
+	rend := render.New()
+
+	if searchrequest.Limit == 0 {
+		searchrequest.Limit = defaultLimit
+	}
+
+	search := db.MusicSearch{
+		Query:  searchrequest.Query,
+		Artist: searchrequest.Artist,
+		Album:  searchrequest.Album,
+		Year:   searchrequest.Year,
+		Offset: searchrequest.Offset,
+		Limit:  searchrequest.Limit,
+	}
+	for _, c := range searchrequest.Categories {
+		search.Categories = append(search.Categories, types.CategoryFromInt(c))
+	}
+	dbh := getDB(r)
+	releases, err := dbh.SearchMusicReleases(search)
+	if err != nil {
+		rend.Text(rw, http.StatusInternalServerError, fmt.Sprintf("Error: %v", err))
+		return
+	}
+
+	writeSearchResponse(rw, r, "gonab music search", searchrequest.Offset, releases)
+}
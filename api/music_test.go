@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+)
+
+func TestMusicSearch(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	releases := []struct {
+		name string
+		cat  types.Category
+	}{
+		{"Adele - 25 (2015) [FLAC]", types.Audio_Lossless},
+		{"Adele-25-WEB-2015-GRP", types.Audio_MP3},
+		{"Taylor_Swift-1989-2014-GRP", types.Audio_MP3},
+	}
+	for i, r := range releases {
+		rel := types.Release{Name: r.name, SearchName: r.name, Hash: string(rune('a' + i)), CategoryID: sql.NullInt64{Int64: int64(r.cat), Valid: true}}
+		if err := dbh.DB.Save(&rel).Error; err != nil {
+			t.Fatalf("Error saving release: %v", err)
+		}
+	}
+	if _, _, err := dbh.BackfillMusicInfo(10); err != nil {
+		t.Fatalf("Error parsing music info: %v", err)
+	}
+	n := configRoutes(dbh, "")
+
+	tests := []struct {
+		url   string
+		code  int
+		count int
+	}{
+		{"/gonab/api?t=music&artist=adele&apikey=123", http.StatusOK, 2},
+		{"/gonab/api?t=music&artist=adele&album=25&cat=3040&apikey=123", http.StatusOK, 1},
+		{"/gonab/api?t=music&year=2014&apikey=123", http.StatusOK, 1},
+		{"/gonab/api?t=music&q=swift&apikey=123", http.StatusOK, 1},
+		// Release names don't carry the label and genre.
+		{"/gonab/api?t=music&label=XL&apikey=123", http.StatusBadRequest, 0},
+		{"/gonab/api?t=music&genre=Pop&apikey=123", http.StatusBadRequest, 0},
+		{"/gonab/api?t=music&apikey=123", http.StatusOK, 3},
+		{"/gonab/api?t=music&year=soon&apikey=123", http.StatusBadRequest, 0},
+	}
+	for _, tc := range tests {
+		respRec := serve(t, n, "GET", tc.url)
+		if respRec.Code != tc.code {
+			t.Fatalf("%s: expected status %d, got %d: %s", tc.url, tc.code, respRec.Code, respRec.Body)
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+		titles := searchTitles(t, tc.url, respRec)
+		if len(titles) != tc.count {
+			t.Errorf("%s: expected %d releases, got %d", tc.url, tc.count, len(titles))
+		}
+	}
+
+	respRec := serve(t, n, "GET", "/gonab/api?t=music&genre=Pop&apikey=123")
+	if !strings.Contains(respRec.Body.String(), `<error code="201"`) {
+		t.Errorf("Expected a newznab error for a genre search: %s", respRec.Body)
+	}
+
+	respRec = serve(t, n, "GET", "/gonab/api?t=caps")
+	if !strings.Contains(respRec.Body.String(), "music-search") {
+		t.Errorf("Caps don't advertise music-search: %s", respRec.Body)
+	}
+	if strings.Contains(respRec.Body.String(), "genre") {
+		t.Errorf("Caps advertise searching by genre: %s", respRec.Body)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/xml"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -80,6 +81,7 @@ type NZB struct {
 	Author      string
 	Date        time.Time
 	Group       string
+	Completion  float64
 }
 
 const maxSearchResults = 100
@@ -109,15 +111,21 @@ func searchHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	writeSearchResponse(rw, r, "gonab", searchrequest.Offset, releases)
+}
+
+// writeSearchResponse writes releases as an RSS search response titled
+// title.
+func writeSearchResponse(rw http.ResponseWriter, r *http.Request, title string, offset int, releases []types.Release) {
 	sr := &searchResponse{
 		Header:       template.HTML(`<?xml version="1.0" encoding="UTF-8"?>`),
 		URL:          r.RequestURI,
 		ContactEmail: "foo@bar.com",
-		Offset:       searchrequest.Offset,
+		Offset:       offset,
 		Total:        len(releases),
 		Image: &rssImage{
 			URL:         "http://localhost/foo.jpg",
-			Title:       "gonab",
+			Title:       title,
 			Link:        "myurl",
 			Description: "visit gonab",
 		},
@@ -135,12 +143,27 @@ func searchHandler(rw http.ResponseWriter, r *http.Request) {
 			Comments:    fmt.Sprintf("https://%s/nzb/details/%s#comments", r.Host, rel.Hash),
 			Date:        rel.Posted,
 			Group:       rel.Group.Name,
+			Completion:  rel.Completion,
 		}
 	}
-
 	searchResponseTemplate.Execute(rw, sr)
 }
 
+// Newznab error code for parameters gonab doesn't support.
+const newznabIncorrectParameter = 201
+
+// newznabError is the error response of the newznab API.
+type newznabError struct {
+	XMLName     xml.Name `xml:"error"`
+	Code        int      `xml:"code,attr"`
+	Description string   `xml:"description,attr"`
+}
+
+// writeNewznabError writes a newznab error response with the given code.
+func writeNewznabError(rw http.ResponseWriter, code int, description string) {
+	render.New().XML(rw, http.StatusBadRequest, newznabError{Code: code, Description: description})
+}
+
 func makeNZBUrl(rel types.Release, r *http.Request) string {
 	return fmt.Sprintf("%s/getnzb?h=%s&apikey=123", getLink(r), rel.Hash)
 }
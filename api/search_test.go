@@ -11,7 +11,7 @@ import (
 
 func TestSearch(t *testing.T) {
 	dbh := db.NewMemoryDBHandle(false, false)
-	n := configRoutes(dbh)
+	n := configRoutes(dbh, "")
 
 	req, err := http.NewRequest("GET", "/gonab/api?t=search&q=foo&apikey=123", nil)
 	if err != nil {
@@ -38,6 +38,7 @@ var (
       <newznab:attr name="category" value="5000" />
       <newznab:attr name="category" value="5040" />
       <newznab:attr name="size" value="{{.Size}}" />
+      <newznab:attr name="completion" value="{{printf "%.1f" .Completion}}" />
     </item>
     {{- end }}
   </channel>
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
 	"gopkg.in/unrolled/render.v1"
 
 	"github.com/mholt/binding"
@@ -11,14 +13,20 @@ import (
 
 type tvSearchReq struct {
 	searchReq
-	TvRageID string
+	TvRageID int64
+	TVDBID   int64
+	TVMazeID int64
 	Season   string
 	Episode  string
 }
 
 func (s *tvSearchReq) FieldMap(req *http.Request) binding.FieldMap {
 	fm := s.searchReq.FieldMap(req)
+	// Shows can be searched by ID instead of name.
+	fm[&s.Query] = "q"
 	fm[&s.TvRageID] = "rid"
+	fm[&s.TVDBID] = "tvdbid"
+	fm[&s.TVMazeID] = "tvmazeid"
 	fm[&s.Season] = "season"
 	fm[&s.Episode] = "ep"
 	return fm
@@ -33,39 +41,29 @@ func tvSearchHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 	rend := render.New()
 
+	if searchrequest.Limit == 0 {
+		searchrequest.Limit = defaultLimit
+	}
+
 	dbh := getDB(r)
-	releases, err := dbh.SearchReleasesByName(searchrequest.Query)
+	search := db.TVSearch{
+		Show:     searchrequest.Query,
+		TVRageID: searchrequest.TvRageID,
+		TVDBID:   searchrequest.TVDBID,
+		TVMazeID: searchrequest.TVMazeID,
+		Season:   searchrequest.Season,
+		Episode:  searchrequest.Episode,
+		Offset:   searchrequest.Offset,
+		Limit:    searchrequest.Limit,
+	}
+	for _, c := range searchrequest.Categories {
+		search.Categories = append(search.Categories, types.CategoryFromInt(c))
+	}
+	releases, err := dbh.SearchTVReleases(search)
 	if err != nil {
 		rend.Text(rw, http.StatusInternalServerError, fmt.Sprintf("Error: %v", err))
 		return
 	}
 
-	sr := searchResponse{
-		URL:          r.RequestURI,
-		ContactEmail: "foo@bar.com",
-		Offset:       searchrequest.Offset,
-		Total:        len(releases),
-		Image: &rssImage{
-			URL:         "http://localhost/foo.jpg",
-			Title:       "gonab tvsearch",
-			Link:        "myurl",
-			Description: "visit gonab",
-		},
-	}
-	sr.NZBs = make([]NZB, len(releases))
-	for i, rel := range releases {
-		sr.NZBs[i] = NZB{
-			Title:       rel.Name,
-			Link:        "https://www.packetspike.net:81/getnzb/1487ae6e44de505ffe43bae63269d2c693142ce0.nzb&i=1&r=d46718373be42282260a342878962f35",
-			Size:        rel.Size,
-			Category:    "TV > HD",
-			Description: rel.Name,
-			GUID:        rel.Hash,
-			PermaLink:   true,
-			Comments:    "http://localhost/details/nzbhash22222222222222222222222222222222222222222222222222222#comments",
-			Date:        rel.Posted,
-		}
-	}
-
-	searchResponseTemplate.Execute(rw, sr)
+	writeSearchResponse(rw, r, "gonab tvsearch", searchrequest.Offset, releases)
 }
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+)
+
+func TestTVSearch(t *testing.T) {
+	dbh := db.NewMemoryDBHandle(false, false)
+	names := []string{
+		"Sleepy.Hollow.S02E03.720p.HDTV.x264-KILLERS",
+		"Sleepy.Hollow.S02E04.720p.HDTV.x264-KILLERS",
+	}
+	for i, name := range names {
+		rel := types.Release{Name: name, Hash: string(rune('a' + i)), CategoryID: sql.NullInt64{Int64: int64(types.TV_HD), Valid: true}}
+		if err := dbh.DB.Save(&rel).Error; err != nil {
+			t.Fatalf("Error saving release: %v", err)
+		}
+	}
+	shows := []*types.TVShow{{TVMazeID: 67, TVDBID: 269578, TVRageID: 35598, Title: "Sleepy Hollow", Year: 2013}}
+	if _, _, err := dbh.SaveTVShows(shows); err != nil {
+		t.Fatalf("Error saving show: %v", err)
+	}
+	if _, _, err := dbh.BackfillTVInfo(10); err != nil {
+		t.Fatalf("Error parsing TV info: %v", err)
+	}
+	n := configRoutes(dbh, "")
+
+	tests := []struct {
+		url   string
+		count int
+	}{
+		{"/gonab/api?t=tvsearch&q=sleepy+hollow&apikey=123", 2},
+		{"/gonab/api?t=tvsearch&q=sleepy+hollow&season=2&ep=4&apikey=123", 1},
+		{"/gonab/api?t=tvsearch&q=sleepy+hollow&season=3&apikey=123", 0},
+		{"/gonab/api?t=tvsearch&q=other+show&apikey=123", 0},
+		{"/gonab/api?t=tvsearch&rid=35598&season=2&ep=3&apikey=123", 1},
+		{"/gonab/api?t=tvsearch&tvdbid=269578&apikey=123", 2},
+		{"/gonab/api?t=tvsearch&tvmazeid=67&season=3&apikey=123", 0},
+		{"/gonab/api?t=tvsearch&tvmazeid=1&apikey=123", 0},
+	}
+	for _, tc := range tests {
+		respRec := serve(t, n, "GET", tc.url)
+		if respRec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", tc.url, respRec.Code, respRec.Body)
+		}
+		titles := searchTitles(t, tc.url, respRec)
+		if len(titles) != tc.count {
+			t.Errorf("%s: expected %d releases, got %d", tc.url, tc.count, len(titles))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package categorize
+
+import (
+	"github.com/hobeone/gonab/types"
+)
+
+var (
+	audiobookRegex  = wordRegex(`Audio[-._ ]?books?|ABOOK|H(oe|ö)rbuch|Unabridged|M4B`)
+	musicVideoRegex = wordRegex(`MVID|MDVDR|MBluRay|Music[-._ ]?Video|Concert`)
+	losslessRegex   = wordRegex(`FLAC|APE|ALAC|WAV|Lossless|24[-._ ]?bit|24[-._ ]96|SACD|DSD`)
+	// Scene tags like -WEB-2016- and bitrates.
+	mp3Regex          = wordRegex(`MP3|V0|V2|VBR|CBR|(128|192|256|320)[-._ ]?kbps|(CD|CDM|CDS|CDR|EP|LP|Vinyl|WEB|SAT|DAB|FM|Bootleg|Promo)[-._ ](19|20)\d\d`)
+	musicForeignRegex = wordRegex(`DE|FR|ES|IT|NL|German|French|Spanish|Italian|Dutch|Schlager|Chanson`)
+	audioOtherRegex   = wordRegex(`Discography|Soundtrack|OST|Album|Single|Radio[-._ ]?Show`)
+)
+
+func isMusic(name, group string, s *Step) types.Category {
+	switch {
+	case s.hit(audiobookRegex, name) && isAudiobook(name):
+		return types.Audio_Audiobook
+	case s.hit(musicVideoRegex, name):
+		return types.Audio_Video
+	}
+	if losslessRegex.MatchString(name) || mp3Regex.MatchString(name) {
+		if s.hit(musicForeignRegex, name) {
+			return types.Audio_Foreign
+		}
+	}
+	switch {
+	case s.hit(losslessRegex, name):
+		return types.Audio_Lossless
+	case s.hit(mp3Regex, name):
+		return types.Audio_MP3
+	case s.hit(audioOtherRegex, name):
+		return types.Audio_Other
+	}
+	return types.Unknown
+}
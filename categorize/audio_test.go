@@ -0,0 +1,30 @@
+package categorize
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+)
+
+var audioMatches = []categoryMatches{
+	{"Artist-Album-CD-FLAC-2016-GRP", "", types.Audio_Lossless},
+	{"Artist-Album-24bit-WEB-2016-GRP", "", types.Audio_Lossless},
+	{"Artist-Album-WEB-2016-GRP", "", types.Audio_MP3},
+	{"Artist - Album (2016) [MP3 320kbps]", "", types.Audio_MP3},
+	{"Artist-Album-DE-CD-FLAC-2016-GRP", "", types.Audio_Foreign},
+	{"Stephen.King-The.Stand.Unabridged-AUDIOBOOK", "", types.Audio_Audiobook},
+	{"Stephen King - It (2016) [M4B + PDF]", "", types.Audio_Audiobook},
+	{"Author - Title (Audiobook Companion) [PDF]", "", types.Unknown},
+	{"Artist-Live.At.Wembley-MBluRay-2016-GRP", "", types.Audio_Video},
+	{"Artist-Discography", "", types.Audio_Other},
+	{"Some.Random.Name-GROUP", "", types.Unknown},
+}
+
+func TestIsMusic(t *testing.T) {
+	for _, m := range audioMatches {
+		cat := isMusic(m.Name, m.Group, nil)
+		if cat != m.Category {
+			t.Errorf("Expected name:'%s' group:'%s' to get category: '%s'. Got: '%s'", m.Name, m.Group, m.Category, cat)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package categorize
+
+import (
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+)
+
+var (
+	bookRegex         = wordRegex(`e-?books?|EPUB|MOBI|AZW3?|PDF|DJVU|CHM`)
+	bookComicsRegex   = wordRegex(`CBR|CBZ|Comics?|Graphic[-._ ]Novel|Manga|Marvel|c2c|\(Digital\)`)
+	bookMagazineRegex = wordRegex(`Magazines?|Mag|(No|Nr|Issue|Vol)[-._ ]?\d{1,3}[-._ ]((Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\w*[-._ ])?(19|20)\d\d`)
+	bookTechRegex     = wordRegex(`Apress|Addison[-._ ]Wesley|Manning|No[-._ ]Starch|O'?Reilly|Packt|Pragmatic|Sams|Wiley|Wrox|Microsoft[-._ ]Press|Programming|Cookbook|for[-._ ]Dummies`)
+	bookForeignRegex  = wordRegex(`German|Deutsch|French|Francais|Dutch|Nederlands|Spanish|Espanol|Italian|Italiano`)
+)
+
+func isBook(name, group string, s *Step) types.Category {
+	switch {
+	case s.hit(bookComicsRegex, name):
+		return types.Book_Comics
+	case s.hit(bookMagazineRegex, name):
+		return types.Book_Magazines
+	case !s.hit(bookRegex, name):
+		return types.Unknown
+	case s.hit(bookTechRegex, name):
+		return types.Book_Technical
+	case s.hit(bookForeignRegex, name):
+		return types.Book_Foreign
+	}
+	return types.Book_Ebook
+}
+
+// isAudiobook weighs the audiobook tags of a name against its ebook tags
+// with the book parser, so ebooks like Author - Title (Audiobook Companion)
+// [PDF] aren't taken for audiobooks.  Names the parser can't read count as
+// audiobooks.
+func isAudiobook(name string) bool {
+	res, err := processing.ParseBookName(name)
+	return err != nil || res.IsAudiobook()
+}
+
+// isEbook returns the book category of ebooks posted to audiobook groups.
+func isEbook(name, group string, s *Step) types.Category {
+	if res, err := processing.ParseBookName(name); err == nil && !res.IsAudiobook() {
+		return isBook(name, group, s)
+	}
+	return types.Unknown
+}
@@ -0,0 +1,28 @@
+package categorize
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+)
+
+var bookMatches = []categoryMatches{
+	{"Author - Title (retail) (epub)", "", types.Book_Ebook},
+	{"Author.Title.2016.RETAIL.EBOOK-GRP", "", types.Book_Ebook},
+	{"OReilly.Learning.Go.2016.RETAIL.EPUB.eBook-GRP", "", types.Book_Technical},
+	{"Author - Titel (German) (epub)", "", types.Book_Foreign},
+	{"Batman 050 (2016) (Digital) (Zone-Empire).cbr", "", types.Book_Comics},
+	{"Wired.Magazine.2016.03", "", types.Book_Magazines},
+	{"Linux.Format.Issue.207.February.2016", "", types.Book_Magazines},
+	{"Author - Title (Audiobook Companion) [PDF]", "", types.Book_Ebook},
+	{"Some.Random.Name-GROUP", "", types.Unknown},
+}
+
+func TestIsBook(t *testing.T) {
+	for _, m := range bookMatches {
+		cat := isBook(m.Name, m.Group, nil)
+		if cat != m.Category {
+			t.Errorf("Expected name:'%s' group:'%s' to get category: '%s'. Got: '%s'", m.Name, m.Group, m.Category, cat)
+		}
+	}
+}
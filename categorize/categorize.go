@@ -6,19 +6,58 @@ import (
 	"github.com/hobeone/gonab/types"
 )
 
-type testFunc func(string, string) types.Category
+// testFunc is a test Categorize runs.  s records what the test decided on
+// for Explain and is nil otherwise.
+type testFunc func(name, group string, s *Step) types.Category
+
+// regexCategory assigns Category to names matching Regex.
+type regexCategory struct {
+	Regex    *regexp.Regexp
+	Category types.Category
+}
+
+// firstMatch returns the category of the first of rcs matching name.
+func firstMatch(name string, rcs []regexCategory, s *Step) types.Category {
+	for _, rc := range rcs {
+		if s.hit(rc.Regex, name) {
+			return rc.Category
+		}
+	}
+	return types.Unknown
+}
+
+// wordRegex returns a case insensitive regex matching words only when they
+// aren't part of a longer word.  Unlike \b it treats _ as a separator.
+func wordRegex(words string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^a-z0-9])(` + words + `)([^a-z0-9]|$)`)
+}
+
+var (
+	// Episode numbers and the resolution and source of a video.  PC and XXX
+	// words are common in TV and movie names, their tests use these to leave
+	// those to isTV and isMovie.
+	episodeRegex = wordRegex(`s\d{1,3}[-._ ]?e\d{1,3}|\d{1,2}x\d{2}`)
+	videoRegex   = wordRegex(`(360|480|576|720|1080|2160)[ip]|(H|P|SD)[-._ ]?TV|BluRay|BD[-._ ]?Rip|BRRip|DVD[-._ ]?(Rip|Scr)|WEB[-._ ]?(DL|Rip)|HDRip|x26[45]|h[-._ ]?26[45]|XviD|DivX`)
+)
+
+// The tests Categorize runs in order.
+var categorizeTests = []testFunc{
+	isMisc,
+	categoryFromGroup,
+	isPC,
+	isXXX,
+	isTV,
+	isMovie,
+	isConsole,
+	isMusic,
+	isBook,
+}
 
 // Categorize takes a release name and usenet group name and tries to
 // categorize it.
 func Categorize(name, group string) types.Category {
-	tests := []testFunc{
-		isMisc,
-		categoryFromGroup,
-		isTV,
-		isMovie,
-	}
-	for _, f := range tests {
-		if cat := f(name, group); cat != types.Unknown {
+	for _, f := range categorizeTests {
+		if cat := f(name, group, nil); cat != types.Unknown {
 			return cat
 		}
 	}
@@ -34,13 +73,13 @@ var (
 	miscNotMiscRegex = regexp.MustCompile(`(?i)[^a-z0-9]((480|720|1080)[ip]|s\d{1,3}[-._ ]?[ed]\d{1,3}([ex]\d{1,3}|[-.\w ]))[^a-z0-9]`)
 )
 
-func isMisc(name, group string) types.Category {
+func isMisc(name, group string, s *Step) types.Category {
 	switch {
 	case miscNotMiscRegex.MatchString(name):
 		return types.Unknown
-	case miscHashRegex.MatchString(name):
+	case s.hit(miscHashRegex, name):
 		return types.Other_Hashed
-	case miscMiscRegex1.MatchString(name) || miscMiscRegex2.MatchString(name):
+	case s.hit(miscMiscRegex1, name) || s.hit(miscMiscRegex2, name):
 		return types.Other_Misc
 	}
 
@@ -51,21 +90,61 @@ var (
 	animeGroupRegex = regexp.MustCompile(`alt\.binaries\.(multimedia\.erotica\.|cartoons\.french\.|dvd\.|multimedia\.)?anime(\.highspeed|\.repost|s-fansub|\.german)?`)
 )
 
-func categoryFromGroup(name, group string) types.Category {
+// Groups whose releases all belong to one parent category.  Test picks the
+// subcategory, Fallback is used if it can't.
+var groupCategories = []struct {
+	Group    *regexp.Regexp
+	Test     testFunc
+	Fallback types.Category
+}{
+	{regexp.MustCompile(`^alt\.binaries\.e-?book\.magazines`), nil, types.Book_Magazines},
+	{regexp.MustCompile(`^alt\.binaries\.e-?book\.technical`), nil, types.Book_Technical},
+	{regexp.MustCompile(`^alt\.binaries\.(pictures\.)?comics`), nil, types.Book_Comics},
+	{regexp.MustCompile(`^alt\.binaries\.e-?books?`), isBook, types.Book_Other},
+	{regexp.MustCompile(`^alt\.binaries\.(sounds\.)?audio-?books?`), isEbook, types.Audio_Audiobook},
+	{regexp.MustCompile(`^alt\.binaries\.(sounds\.)?(lossless|flac)`), isMusic, types.Audio_Lossless},
+	{regexp.MustCompile(`^alt\.binaries\.(mp3|sounds|music)`), isMusic, types.Audio_MP3},
+	{regexp.MustCompile(`^alt\.binaries\.(games\.)?xbox360`), isConsole, types.Console_Xbox360},
+	{regexp.MustCompile(`^alt\.binaries\.(games\.)?wii`), isConsole, types.Console_Wii},
+	{regexp.MustCompile(`^alt\.binaries\.(games\.)?nintendo(\.?ds|\.3ds)`), isConsole, types.Console_NDS},
+	{regexp.MustCompile(`^alt\.binaries\.(sony\.)?psp`), isConsole, types.Console_PSP},
+	{regexp.MustCompile(`^alt\.binaries\.(sony\.)?ps3`), isConsole, types.Console_PS3},
+	{regexp.MustCompile(`^alt\.binaries\.(cd\.)?games$`), isConsole, types.PC_Games},
+	{regexp.MustCompile(`^alt\.binaries\.mac`), nil, types.PC_Mac},
+	{regexp.MustCompile(`^alt\.binaries\.(cd\.image|iso)`), isPC, types.PC_ISO},
+	{regexp.MustCompile(`^alt\.binaries\.(0day\.stuffz|apps|warez)`), isPC, types.PC_0day},
+	{regexp.MustCompile(`^alt\.binaries\.(erotica|multimedia\.erotica|pictures\.erotica|xxx)`), xxxSubcategory, types.XXX_Other},
+}
+
+// categoryFromGroup decides by the group rather than the name.
+func categoryFromGroup(name, group string, s *Step) types.Category {
 	switch {
 	case group == "alt.binaries.audio.warez":
+		s.record("", group)
 		return types.PC_0day
-	case animeGroupRegex.MatchString(group):
+	case s.hit(animeGroupRegex, group):
 		return types.TV_Anime
 	case group == "alt.binaries.moovee":
-		if cat := isTV(name, group); cat != types.Unknown {
+		s.record("", group)
+		if cat := s.run(isTV, name, group); cat != types.Unknown {
 			return cat
 		}
-		if cat := isMovieHD(name, group); cat != types.Unknown {
+		if cat := s.run(isMovieHD, name, group); cat != types.Unknown {
 			return cat
 		}
 		return types.Movie_SD
 	}
+	for _, gc := range groupCategories {
+		if !s.hit(gc.Group, group) {
+			continue
+		}
+		if gc.Test != nil {
+			if cat := s.run(gc.Test, name, group); cat != types.Unknown {
+				return cat
+			}
+		}
+		return gc.Fallback
+	}
 	return types.Unknown
 }
 
@@ -87,18 +166,18 @@ var (
 	}
 )
 
-func isTV(name, group string) types.Category {
-	if tvRegex.MatchString(name) && !tvRegexNegative.MatchString(name) {
+func isTV(name, group string, s *Step) types.Category {
+	if !tvRegexNegative.MatchString(name) && s.hit(tvRegex, name) {
 		for _, f := range tvMatchFuncs {
-			if cat := f(name, group); cat != types.Unknown {
+			if cat := s.run(f, name, group); cat != types.Unknown {
 				return cat
 			}
 		}
 		return types.TV_Other
 	}
 
-	if tvSportsRegex.MatchString(name) {
-		if cat := isSportTV(name, group); cat != types.Unknown {
+	if s.hit(tvSportsRegex, name) {
+		if cat := s.run(isSportTV, name, group); cat != types.Unknown {
 			return types.TV_Sport
 		}
 		return types.TV_Other
@@ -111,8 +190,8 @@ var (
 	webdlRegex = regexp.MustCompile(`(?i)web[-._ ]dl|web-?rip`)
 )
 
-func isHDTV(name, group string) types.Category {
-	if hdtvRegex.MatchString(name) {
+func isHDTV(name, group string, s *Step) types.Category {
+	if s.hit(hdtvRegex, name) {
 		return types.TV_HD
 	}
 	return types.Unknown
@@ -125,18 +204,18 @@ var (
 	sdtvRegex4 = regexp.MustCompile(`(?i)(H|P)D[-._ ]?TV|BDRip[-._ ]x264`)
 )
 
-func isSDTV(name, group string) types.Category {
-	if sdtvRegex1.MatchString(name) || sdtvRegex2.MatchString(name) {
+func isSDTV(name, group string, s *Step) types.Category {
+	if s.hit(sdtvRegex1, name) || s.hit(sdtvRegex2, name) {
 		return types.TV_SD
 	}
-	if sdtvRegex3.MatchString(name) && sdtvRegex4.MatchString(name) {
+	if sdtvRegex3.MatchString(name) && s.hit(sdtvRegex4, name) {
 		return types.TV_SD
 	}
 	return types.Unknown
 }
 
-func isWebDL(name, group string) types.Category {
-	if webdlRegex.MatchString(name) {
+func isWebDL(name, group string, s *Step) types.Category {
+	if s.hit(webdlRegex, name) {
 		return types.TV_WEBDL
 	}
 	return types.Unknown
@@ -147,15 +226,15 @@ var (
 	otherTVRegex2 = regexp.MustCompile(`(?i)[-._ ]s\d{1,3}[-._ ]?(e|d(isc)?)\d{1,3}([-._ ]|$)`)
 )
 
-func isOtherTV(name, group string) types.Category {
-	if otherTVRegex.MatchString(name) {
+func isOtherTV(name, group string, s *Step) types.Category {
+	if s.hit(otherTVRegex, name) {
 		return types.TV_Other
 	}
 	return types.Unknown
 }
 
-func isOtherTV2(name, group string) types.Category {
-	if otherTVRegex2.MatchString(name) {
+func isOtherTV2(name, group string, s *Step) types.Category {
+	if s.hit(otherTVRegex2, name) {
 		return types.TV_Other
 	}
 	return types.Unknown
@@ -170,9 +249,9 @@ var (
 	foreignRegexes = []*regexp.Regexp{foreignTV1, foreignTV2, foreignTV3, foreignTV4, foreignTV5}
 )
 
-func isForeignTV(name, group string) types.Category {
+func isForeignTV(name, group string, s *Step) types.Category {
 	for _, i := range foreignRegexes {
-		if i.MatchString(name) {
+		if s.hit(i, name) {
 			return types.TV_Foreign
 		}
 	}
@@ -181,8 +260,8 @@ func isForeignTV(name, group string) types.Category {
 
 var animeTVRegex = regexp.MustCompile(`(?i)[-._ ]Anime[-._ ]|^\[[a-zA-Z\.\-]+\].*[-_].*\d{1,3}[-_. ]((\[|\()((\d{1,4}x\d{1,4})|(h264-)?\d{3,4}(p|i))(\]|\))\s?(\[AAC\])?|\[[a-fA-F0-9]{8}\]|(8|10)BIT|hi10p)(\[[a-fA-F0-9]{8}\])?`)
 
-func isAnimeTV(name, group string) types.Category {
-	if animeTVRegex.MatchString(name) {
+func isAnimeTV(name, group string, s *Step) types.Category {
+	if s.hit(animeTVRegex, name) {
 		return types.TV_Anime
 	}
 	return types.Unknown
@@ -197,12 +276,12 @@ var (
 	sportRegexes = []*regexp.Regexp{sportTVRegex1, sportTVRegex2, sportTVRegex3}
 )
 
-func isSportTV(name, group string) types.Category {
+func isSportTV(name, group string, s *Step) types.Category {
 	if sportTVNegRegex.MatchString(name) {
 		return types.Unknown
 	}
 	for _, i := range sportRegexes {
-		if i.MatchString(name) {
+		if s.hit(i, name) {
 			return types.TV_Sport
 		}
 	}
@@ -213,8 +292,8 @@ var (
 	documentaryRegex = regexp.MustCompile(`(?i)[-._ ](Docu|Documentary)[-._ ]`)
 )
 
-func isDocumentaryTV(name, group string) types.Category {
-	if documentaryRegex.MatchString(name) {
+func isDocumentaryTV(name, group string, s *Step) types.Category {
+	if s.hit(documentaryRegex, name) {
 		return types.TV_Documentary
 	}
 	return types.Unknown
@@ -235,10 +314,10 @@ var (
 	}
 )
 
-func isMovie(name, group string) types.Category {
-	if movieRegex.MatchString(name) && !movieNegRegex.MatchString(name) {
+func isMovie(name, group string, s *Step) types.Category {
+	if !movieNegRegex.MatchString(name) && s.hit(movieRegex, name) {
 		for _, f := range movieClassifiers {
-			if cat := f(name, group); cat != types.Unknown {
+			if cat := s.run(f, name, group); cat != types.Unknown {
 				return cat
 			}
 		}
@@ -254,9 +333,9 @@ var (
 	}
 )
 
-func isMovieForeign(name, group string) types.Category {
+func isMovieForeign(name, group string, s *Step) types.Category {
 	for _, i := range movieForeignRegexes {
-		if i.MatchString(name) {
+		if s.hit(i, name) {
 			return types.Movie_Foreign
 		}
 	}
@@ -267,8 +346,8 @@ var (
 	movieDVDRegex = regexp.MustCompile(`(?i)(dvd\-?r|[-._ ]dvd|dvd9|dvd5|[-._ ]r5)[-._ ]`)
 )
 
-func isMovieDVD(name, group string) types.Category {
-	if movieDVDRegex.MatchString(name) {
+func isMovieDVD(name, group string, s *Step) types.Category {
+	if s.hit(movieDVDRegex, name) {
 		return types.Movie_DVD
 	}
 	return types.Unknown
@@ -278,8 +357,8 @@ var (
 	movieSDRegex = regexp.MustCompile(`(?i)(divx|dvdscr|extrascene|dvdrip|\.CAM|HDTS(-LINE)?|vhsrip|xvid(vd)?)[-._ ]`)
 )
 
-func isMovieSD(name, group string) types.Category {
-	if movieSDRegex.MatchString(name) {
+func isMovieSD(name, group string, s *Step) types.Category {
+	if s.hit(movieSDRegex, name) {
 		return types.Movie_SD
 	}
 	return types.Unknown
@@ -289,8 +368,8 @@ var (
 	movie3DRegex = regexp.MustCompile(`(?i)[-._ ]3D\s?[\.\-_\[ ](1080p|(19|20)\d\d|AVC|BD(25|50)|Blu[-._ ]?ray|CEE|Complete|GER|MVC|MULTi|SBS|H(-)?SBS)[-._ ]`)
 )
 
-func isMovie3D(name, group string) types.Category {
-	if movie3DRegex.MatchString(name) {
+func isMovie3D(name, group string, s *Step) types.Category {
+	if s.hit(movie3DRegex, name) {
 		return types.Movie_3D
 	}
 	return types.Unknown
@@ -301,8 +380,8 @@ var (
 	movieBluRayNegRegex = regexp.MustCompile(`(?i)SecretUsenet\.com`)
 )
 
-func isMovieBluRay(name, group string) types.Category {
-	if movieBluRayRegex.MatchString(name) && !movieBluRayNegRegex.MatchString(name) {
+func isMovieBluRay(name, group string, s *Step) types.Category {
+	if !movieBluRayNegRegex.MatchString(name) && s.hit(movieBluRayRegex, name) {
 		return types.Movie_BluRay
 	}
 	return types.Unknown
@@ -312,8 +391,8 @@ var (
 	movieHDRegex = regexp.MustCompile(`(?i)720p|1080p|AVC|VC1|VC\-1|web\-dl|wmvhd|x264|XvidHD|bdrip`)
 )
 
-func isMovieHD(name, group string) types.Category {
-	if movieHDRegex.MatchString(name) {
+func isMovieHD(name, group string, s *Step) types.Category {
+	if s.hit(movieHDRegex, name) {
 		return types.Movie_HD
 	}
 	return types.Unknown
@@ -323,8 +402,8 @@ var (
 	movieOtherRegex = regexp.MustCompile(`(?i)[-._ ]cam[-._ ]`)
 )
 
-func isMovieOther(name, group string) types.Category {
-	if movieOtherRegex.MatchString(name) {
+func isMovieOther(name, group string, s *Step) types.Category {
+	if s.hit(movieOtherRegex, name) {
 		return types.Movie_Other
 	}
 	return types.Unknown
@@ -334,8 +413,8 @@ var (
 	movieWebDLRegex = regexp.MustCompile(`(?i)web[-._ ]dl|web-?rip`)
 )
 
-func isMovieWebDL(name, group string) types.Category {
-	if movieWebDLRegex.MatchString(name) {
+func isMovieWebDL(name, group string, s *Step) types.Category {
+	if s.hit(movieWebDLRegex, name) {
 		return types.Movie_WEBDL
 	}
 	return types.Unknown
@@ -20,7 +20,7 @@ var miscGroupTuples = []categoryMatches{
 
 func TestIsMisc(t *testing.T) {
 	for _, m := range miscGroupTuples {
-		cat := isMisc(m.Name, m.Group)
+		cat := isMisc(m.Name, m.Group, nil)
 		if cat != m.Category {
 			t.Errorf("Expected name:%s group%s to get category %s. Got: %s", m.Name, m.Group, m.Category, cat)
 		}
@@ -35,11 +35,34 @@ var groupTuples = []categoryMatches{
 	{"foobar-Season 01-1080p", "alt.binaries.moovee", types.TV_HD},
 	{"foobar-1080p", "alt.binaries.moovee", types.Movie_HD},
 	{"foobar", "alt.binaries.moovee", types.Movie_SD},
+	{"foobar", "alt.binaries.e-book.magazines", types.Book_Magazines},
+	{"foobar", "alt.binaries.e-book.technical", types.Book_Technical},
+	{"foobar", "alt.binaries.pictures.comics.dcp", types.Book_Comics},
+	{"Author - Title (retail) (epub)", "alt.binaries.e-book", types.Book_Ebook},
+	{"foobar", "alt.binaries.ebooks", types.Book_Other},
+	{"foobar", "alt.binaries.sounds.audiobooks", types.Audio_Audiobook},
+	{"Stephen King - It (2016) [M4B]", "alt.binaries.sounds.audiobooks", types.Audio_Audiobook},
+	{"Author - Title (retail) (epub)", "alt.binaries.sounds.audiobooks", types.Book_Ebook},
+	{"foobar", "alt.binaries.sounds.lossless", types.Audio_Lossless},
+	{"Artist-Album-CD-FLAC-2016-GRP", "alt.binaries.sounds.mp3", types.Audio_Lossless},
+	{"foobar", "alt.binaries.sounds.mp3", types.Audio_MP3},
+	{"Forza.Horizon.2.XBOX360-COMPLEX", "alt.binaries.games.xbox360", types.Console_Xbox360},
+	{"foobar", "alt.binaries.games.wii", types.Console_Wii},
+	{"foobar", "alt.binaries.games.nintendods", types.Console_NDS},
+	{"foobar", "alt.binaries.psp", types.Console_PSP},
+	{"foobar", "alt.binaries.sony.ps3", types.Console_PS3},
+	{"foobar", "alt.binaries.games", types.PC_Games},
+	{"foobar", "alt.binaries.mac", types.PC_Mac},
+	{"foobar", "alt.binaries.cd.image", types.PC_ISO},
+	{"Angry.Birds.Android.Games.v1.2.APK", "alt.binaries.apps", types.PC_PhoneAndroid},
+	{"foobar", "alt.binaries.warez", types.PC_0day},
+	{"Some.Site.XXX.Imageset-GRP", "alt.binaries.erotica", types.XXX_Imageset},
+	{"foobar", "alt.binaries.xxx", types.XXX_Other},
 }
 
 func TestCategoryFromGroup(t *testing.T) {
 	for _, m := range groupTuples {
-		cat := categoryFromGroup(m.Name, m.Group)
+		cat := categoryFromGroup(m.Name, m.Group, nil)
 		if cat != m.Category {
 			t.Errorf("Expected name:%s group%s to get category %s. Got: %s", m.Name, m.Group, m.Category, cat)
 		}
@@ -61,7 +84,7 @@ var tvMatches = []categoryMatches{
 
 func TestIsTV(t *testing.T) {
 	for _, m := range tvMatches {
-		cat := isTV(m.Name, m.Group)
+		cat := isTV(m.Name, m.Group, nil)
 		if cat != m.Category {
 			t.Errorf("Expected name:%s group%s to get category %s. Got: %s", m.Name, m.Group, m.Category, cat)
 		}
@@ -83,10 +106,65 @@ var movieMatches = []categoryMatches{
 
 func TestIsMovie(t *testing.T) {
 	for _, m := range movieMatches {
-		cat := isMovie(m.Name, m.Group)
+		cat := isMovie(m.Name, m.Group, nil)
 		if cat != m.Category {
 			t.Errorf("Expected name:'%s' group:'%s' to get category: '%s'. Got: '%s'", m.Name, m.Group, m.Category, cat)
 		}
 	}
 
 }
+
+func TestFromPreSection(t *testing.T) {
+	sections := map[string]types.Category{
+		"TV-X264":      types.TV_HD,
+		"tv-xvid":      types.TV_SD,
+		"TV-WEB-HD":    types.TV_WEBDL,
+		"X264-HD":      types.Movie_HD,
+		"XVID":         types.Movie_SD,
+		"DVDR":         types.Movie_DVD,
+		"MP3":          types.Audio_MP3,
+		"FLAC":         types.Audio_Lossless,
+		"XXX-0DAY":     types.XXX_Other,
+		"XXX-IMGSET":   types.XXX_Imageset,
+		"EBOOK":        types.Book_Ebook,
+		"ABOOK":        types.Audio_Audiobook,
+		"PS4":          types.Console_PS4,
+		"GAMES_NFOFIX": types.PC_Games,
+		"":             types.Unknown,
+		"PRE":          types.Unknown,
+	}
+	for section, expected := range sections {
+		if cat := FromPreSection(section); cat != expected {
+			t.Errorf("Expected section %q to get category %s. Got: %s", section, expected, cat)
+		}
+	}
+}
+
+var categorizeMatches = []categoryMatches{
+	{"Sleepy.Hollow.S03E11.720p.HDTV.x264-AVS", "alt.binaries.teevee", types.TV_HD},
+	{"Movie.Name.2001-bluray-1080p.x264", "alt.binaries.hdtv", types.Movie_BluRay},
+	{"Adobe.Photoshop.CC.2015.x64.Incl.Keygen-XFORCE", "alt.binaries.misc", types.PC_0day},
+	{"Brazzers.16.02.18.Some.Name.XXX.720p.MP4-KTR", "alt.binaries.misc", types.XXX_x264},
+	{"Bloodborne.PS4-DUPLEX", "alt.binaries.misc", types.Console_PS4},
+	{"Artist-Album-CD-FLAC-2016-GRP", "alt.binaries.misc", types.Audio_Lossless},
+	{"Author - Title (retail) (epub)", "alt.binaries.misc", types.Book_Ebook},
+	{"Author - Title (Audiobook Companion) [PDF]", "alt.binaries.misc", types.Book_Ebook},
+	{"Author - Title (Audiobook) [eBook]", "alt.binaries.misc", types.Book_Ebook},
+	{"Author - Title (Audiobook) [eBook]", "alt.binaries.sounds.audiobooks", types.Book_Ebook},
+	{"Android.Apocalypse.2006.DVDRip.XviD-GRP", "alt.binaries.misc", types.Movie_SD},
+	{"Cracked.S01E01.720p.HDTV.x264-KILLERS", "alt.binaries.misc", types.TV_HD},
+	{"Portable.Life.2011.720p.BluRay.x264-SPARKS", "alt.binaries.misc", types.Movie_HD},
+	{"Android.Apps.S01E02.HDTV", "alt.binaries.misc", types.TV_SD},
+	{"Inside.Porn.Valley.S01E01.HDTV.x264-BATV", "alt.binaries.misc", types.TV_SD},
+	{"Some.Site.S01E02.XXX.1080p.MP4-GRP", "alt.binaries.misc", types.XXX_x264},
+	{"Microsoft.Office.2016.Portable-GRP", "alt.binaries.misc", types.PC_0day},
+}
+
+func TestCategorize(t *testing.T) {
+	for _, m := range categorizeMatches {
+		cat := Categorize(m.Name, m.Group)
+		if cat != m.Category {
+			t.Errorf("Expected name:'%s' group:'%s' to get category: '%s'. Got: '%s'", m.Name, m.Group, m.Category, cat)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package categorize
+
+import (
+	"github.com/hobeone/gonab/types"
+)
+
+// Checked in order, newer consoles first so their names aren't mistaken for
+// the ones they're named after.
+var consoleCategories = []regexCategory{
+	{wordRegex(`Wii[-._ ]?U`), types.Console_WiiU},
+	{wordRegex(`Wii[-._ ]?(Ware|VC)|VC[-._ ]?Wii`), types.Console_WiiWareVC},
+	{wordRegex(`Wii`), types.Console_Wii},
+	{wordRegex(`3DS|Nintendo[-._ ]?3DS`), types.Console_3DS},
+	{wordRegex(`NDS|Nintendo[-._ ]?DS`), types.Console_NDS},
+	{wordRegex(`PS[-._ ]?Vita|PSV`), types.Console_PSVita},
+	{wordRegex(`PSP`), types.Console_PSP},
+	{wordRegex(`PS4`), types.Console_PS4},
+	{wordRegex(`PS3`), types.Console_PS3},
+	{wordRegex(`XBOX[-._ ]?ONE|XBONE`), types.Console_XboxOne},
+	{wordRegex(`(XBOX[-._ ]?360|X360)[-._ ].*(DLC|XBLA)|(DLC|XBLA)[-._ ].*(XBOX[-._ ]?360|X360)`), types.Console_XBOX360DLC},
+	{wordRegex(`XBOX[-._ ]?360|X360`), types.Console_Xbox360},
+	{wordRegex(`XBOX`), types.Console_Xbox},
+	{wordRegex(`Dreamcast|GameCube|GBA|GBC|N64|NES|NGC|PS2(DVD)?|PSX|Sega|SNES`), types.Console_Other},
+}
+
+func isConsole(name, group string, s *Step) types.Category {
+	return firstMatch(name, consoleCategories, s)
+}
@@ -0,0 +1,34 @@
+package categorize
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+)
+
+var consoleMatches = []categoryMatches{
+	{"Halo.5.Guardians.XBOXONE-COMPLEX", "", types.Console_XboxOne},
+	{"Forza.Horizon.2.XBOX360-COMPLEX", "", types.Console_Xbox360},
+	{"Minecraft.Battle.Map.Pack.XBLA.XBOX360-MoNGoLS", "", types.Console_XBOX360DLC},
+	{"Fable.PAL.XBOX-WAM", "", types.Console_Xbox},
+	{"Bloodborne.PS4-DUPLEX", "", types.Console_PS4},
+	{"The.Last.of.Us.PS3-DUPLEX", "", types.Console_PS3},
+	{"Persona.4.Golden.PS.Vita-VENOM", "", types.Console_PSVita},
+	{"God.of.War.Chains.of.Olympus.EUR.PSP-PLAYASiA", "", types.Console_PSP},
+	{"Mario.Kart.8.USA.WiiU-LoCAL", "", types.Console_WiiU},
+	{"Super.Mario.Bros.3.WiiWare.PAL-OneUp", "", types.Console_WiiWareVC},
+	{"Super.Mario.Galaxy.PAL.Wii-ZRY", "", types.Console_Wii},
+	{"Pokemon.X.3DS-PUSSYCAT", "", types.Console_3DS},
+	{"New.Super.Mario.Bros.EUR.NDS-XPA", "", types.Console_NDS},
+	{"Shadow.of.the.Colossus.PAL.PS2DVD-Ps2Ps", "", types.Console_Other},
+	{"Some.Random.Name-GROUP", "", types.Unknown},
+}
+
+func TestIsConsole(t *testing.T) {
+	for _, m := range consoleMatches {
+		cat := isConsole(m.Name, m.Group, nil)
+		if cat != m.Category {
+			t.Errorf("Expected name:'%s' group:'%s' to get category: '%s'. Got: '%s'", m.Name, m.Group, m.Category, cat)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package categorize
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/hobeone/gonab/types"
+)
+
+// Step records how a single test handled a release.
+type Step struct {
+	Test     string         // Name of the test function.
+	Category types.Category // What the test returned, Unknown if it didn't match.
+	Regex    string         // Regex that decided the test, if it matched.
+	Match    string         // Text the regex matched, the alternation that hit.
+	Steps    []Step         // Sub-tests the test ran.
+}
+
+// Matched returns true if the test placed the release in a category.
+func (s Step) Matched() bool {
+	return s.Category != types.Unknown
+}
+
+// testName returns the name of a test function without its package.
+func testName(f testFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// Explain categorizes a release like Categorize and returns a trace of every
+// test it ran.
+func Explain(name, group string) (types.Category, []Step) {
+	return explainTests(categorizeTests, name, group)
+}
+
+// explainTests runs tests until one matches, like Categorize does.
+func explainTests(tests []testFunc, name, group string) (types.Category, []Step) {
+	steps := []Step{}
+	for _, f := range tests {
+		step := explainTest(f, name, group)
+		steps = append(steps, step)
+		if step.Matched() {
+			return step.Category, steps
+		}
+	}
+	return types.Unknown, steps
+}
+
+// explainTest runs f and returns what it decided on.  The regex of a test
+// that matched is the last one it hit.
+func explainTest(f testFunc, name, group string) Step {
+	step := Step{Test: testName(f)}
+	step.Category = f(name, group, &step)
+	if !step.Matched() {
+		step.Regex, step.Match = "", ""
+	}
+	return step
+}
+
+// record notes what a test matched.  s is nil unless called from Explain.
+func (s *Step) record(regex, match string) {
+	if s != nil {
+		s.Regex = regex
+		s.Match = strings.Trim(match, "-._ ")
+	}
+}
+
+// hit returns true if r matches text and records the match in s.
+func (s *Step) hit(r *regexp.Regexp, text string) bool {
+	if s == nil {
+		return r.MatchString(text)
+	}
+	m := r.FindStringIndex(text)
+	if m == nil {
+		return false
+	}
+	s.record(r.String(), text[m[0]:m[1]])
+	return true
+}
+
+// run runs the sub-test f and adds its trace to s.
+func (s *Step) run(f testFunc, name, group string) types.Category {
+	if s == nil {
+		return f(name, group, nil)
+	}
+	sub := explainTest(f, name, group)
+	s.Steps = append(s.Steps, sub)
+	return sub.Category
+}
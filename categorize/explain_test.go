@@ -0,0 +1,72 @@
+package categorize
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+)
+
+func TestExplain(t *testing.T) {
+	name := "Lilyhammer.3x08.Un.Nuovo.Inizio.ITA.BDMux.x264-NovaRip"
+	cat, steps := Explain(name, "alt.binaries.teevee")
+	if cat != types.TV_Foreign {
+		t.Fatalf("Expected category %s, got %s", types.TV_Foreign, cat)
+	}
+	if cat != Categorize(name, "alt.binaries.teevee") {
+		t.Errorf("Explain and Categorize disagree about %s", name)
+	}
+	tests := []string{}
+	for _, s := range steps {
+		tests = append(tests, s.Test)
+	}
+	expected := []string{"isMisc", "categoryFromGroup", "isPC", "isXXX", "isTV"}
+	if len(tests) != len(expected) {
+		t.Fatalf("Expected tests %v, got %v", expected, tests)
+	}
+	for i := range expected {
+		if tests[i] != expected[i] {
+			t.Fatalf("Expected tests %v, got %v", expected, tests)
+		}
+	}
+	tv := steps[len(steps)-1]
+	if tv.Match != "3x08" {
+		t.Errorf("Expected isTV to match '3x08', got '%s'", tv.Match)
+	}
+	if len(tv.Steps) != 2 || tv.Steps[0].Test != "isOtherTV" || tv.Steps[0].Matched() {
+		t.Fatalf("Expected isOtherTV not to match then isForeignTV, got %+v", tv.Steps)
+	}
+	foreign := tv.Steps[1]
+	if foreign.Test != "isForeignTV" || foreign.Category != types.TV_Foreign || foreign.Match == "" {
+		t.Errorf("Expected isForeignTV to match, got %+v", foreign)
+	}
+
+	cat, steps = Explain("Forza.Horizon.2.XBOX360-COMPLEX", "alt.binaries.games.xbox360")
+	if cat != types.Console_Xbox360 || len(steps) != 2 {
+		t.Fatalf("Expected the group to decide, got %s after %+v", cat, steps)
+	}
+	if steps[1].Match != "alt.binaries.games.xbox360" {
+		t.Errorf("Expected the group regex to match the group, got %+v", steps[1])
+	}
+
+	// The regex reported is the one that decided, not the first of the test
+	// to match.
+	cat, steps = Explain("Artist-Album-DE-CD-FLAC-2016-GRP", "")
+	music := steps[len(steps)-1]
+	if cat != types.Audio_Foreign || music.Regex != musicForeignRegex.String() || music.Match != "DE" {
+		t.Errorf("Expected musicForeignRegex to decide, got %s after %+v", cat, music)
+	}
+
+	cat, steps = Explain("Brazzers.16.02.18.Some.Name.XXX.720p.MP4-KTR", "")
+	xxx := steps[len(steps)-1]
+	if cat != types.XXX_x264 || xxx.Regex != xxxRegex.String() || len(xxx.Steps) != 1 {
+		t.Fatalf("Expected isXXX to match with xxxRegex, got %s after %+v", cat, xxx)
+	}
+	if sub := xxx.Steps[0]; sub.Test != "xxxSubcategory" || sub.Match != "720p" {
+		t.Errorf("Expected xxxSubcategory to match 720p, got %+v", sub)
+	}
+
+	cat, steps = Explain("foo bar", "alt.binaries.test")
+	if cat != types.Unknown || len(steps) != len(categorizeTests) {
+		t.Errorf("Expected all tests to run and fail, got %s after %d tests", cat, len(steps))
+	}
+}
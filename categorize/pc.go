@@ -0,0 +1,32 @@
+package categorize
+
+import (
+	"regexp"
+
+	"github.com/hobeone/gonab/types"
+)
+
+var (
+	// Release groups that only release PC games.
+	pcGameGroupRegex = regexp.MustCompile(`(?i)-(ALiAS|CODEX|CPY|DARKSiDERS|FLT|GOG|HI2U|PLAZA|POSTMORTEM|PROPHET|RAZOR1911|RELOADED|SiMPLEX|SKIDROW|TiNYiSO)$`)
+
+	// Phone names need an app marker, a lot of movies have Android or
+	// iPhone in their name.
+	pcCategories = []regexCategory{
+		{wordRegex(`Android[-._ ](Apps?|Games?|v?\d+(\.\d+)+)|APK`), types.PC_PhoneAndroid},
+		{wordRegex(`(iOS|iPad|iPhone|iPod)[-._ ](Apps?|Games?|v?\d+(\.\d+)+)|IPA`), types.PC_PhoneIOS},
+		{wordRegex(`BlackBerry|Palm[-._ ]?OS|Symbian|Windows[-._ ]?Phone|WP[78]`), types.PC_PhoneOther},
+		{wordRegex(`Mac[-._ ]?OS([-._ ]?X)?|OSX|Macintosh`), types.PC_Mac},
+		{wordRegex(`PC[-._ ]?Games?|Steam[-._ ]?Rip|Repack[-._ ]by[-._ ]\w+`), types.PC_Games},
+		{pcGameGroupRegex, types.PC_Games},
+		{wordRegex(`CD[-._ ]?ISO|ISO[-._ ](Win(dows)?|x(32|64|86))|(Win(dows)?|x(32|64|86))[-._ ]([\w]+[-._ ])*ISO`), types.PC_ISO},
+		{wordRegex(`Incl[-._ ]?(Keygen|Patch|Crack|Serial)|Keygen|Cracked|Portable|x(32|64|86)|Win(dows)?[-._ ]?(7|8|10|XP|Vista)|Adobe|Autodesk|Microsoft[-._ ]Office`), types.PC_0day},
+	}
+)
+
+func isPC(name, group string, s *Step) types.Category {
+	if episodeRegex.MatchString(name) || videoRegex.MatchString(name) {
+		return types.Unknown
+	}
+	return firstMatch(name, pcCategories, s)
+}
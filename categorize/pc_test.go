@@ -0,0 +1,32 @@
+package categorize
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+)
+
+var pcMatches = []categoryMatches{
+	{"Adobe.Photoshop.CC.2015.x64.Incl.Keygen-XFORCE", "", types.PC_0day},
+	{"Microsoft.Office.Professional.Plus.2016-TEAM", "", types.PC_0day},
+	{"WinRAR.5.31.Portable-FOO", "", types.PC_0day},
+	{"Windows.10.Pro.x64.ISO-TEAM", "", types.PC_ISO},
+	{"Some.Tool.v2.CD.ISO-TEAM", "", types.PC_ISO},
+	{"Fallout.4-CODEX", "", types.PC_Games},
+	{"XCOM.2.PC.Games.Repack.by.FitGirl", "", types.PC_Games},
+	{"Logic.Pro.X.v10.2.Mac.OSX-FOO", "", types.PC_Mac},
+	{"Angry.Birds.Android.Games.v1.2.APK", "", types.PC_PhoneAndroid},
+	{"Monument.Valley.iOS.v2.1.IPA", "", types.PC_PhoneIOS},
+	{"Maps.For.Symbian-TEAM", "", types.PC_PhoneOther},
+	{"Android.Apocalypse.2006.DVDRip.XviD-GRP", "", types.Unknown},
+	{"Sleepy.Hollow.S03E11.720p.HDTV.x264-AVS", "", types.Unknown},
+}
+
+func TestIsPC(t *testing.T) {
+	for _, m := range pcMatches {
+		cat := isPC(m.Name, m.Group, nil)
+		if cat != m.Category {
+			t.Errorf("Expected name:'%s' group:'%s' to get category: '%s'. Got: '%s'", m.Name, m.Group, m.Category, cat)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package categorize
+
+import (
+	"strings"
+
+	"github.com/hobeone/gonab/types"
+)
+
+// Scene sections pres are made in and the category releases in them belong
+// to.
+var preSections = map[string]types.Category{
+	"0DAY":       types.PC_0day,
+	"3DS":        types.Console_3DS,
+	"ABOOK":      types.Audio_Audiobook,
+	"ANDROID":    types.PC_PhoneAndroid,
+	"ANIME":      types.TV_Anime,
+	"APPS":       types.PC_0day,
+	"AUDIOBOOK":  types.Audio_Audiobook,
+	"BLURAY":     types.Movie_BluRay,
+	"BLURAY-UHD": types.Movie_BluRay,
+	"COMICS":     types.Book_Comics,
+	"DOX":        types.PC_0day,
+	"DVDR":       types.Movie_DVD,
+	"EBOOK":      types.Book_Ebook,
+	"EBOOKS":     types.Book_Ebook,
+	"FLAC":       types.Audio_Lossless,
+	"GAMES":      types.PC_Games,
+	"IOS":        types.PC_PhoneIOS,
+	"ISO":        types.PC_ISO,
+	"MAC":        types.PC_Mac,
+	"MAGS":       types.Book_Magazines,
+	"MDVDR":      types.Audio_Video,
+	"MP3":        types.Audio_MP3,
+	"MVID":       types.Audio_Video,
+	"NDS":        types.Console_NDS,
+	"NGC":        types.Console_Other,
+	"PC":         types.PC_Games,
+	"PS3":        types.Console_PS3,
+	"PS4":        types.Console_PS4,
+	"PSP":        types.Console_PSP,
+	"PSV":        types.Console_PSVita,
+	"WII":        types.Console_Wii,
+	"WIIU":       types.Console_WiiU,
+	"X264":       types.Movie_HD,
+	"X265":       types.Movie_HD,
+	"XBOX":       types.Console_Xbox,
+	"XBOX360":    types.Console_Xbox360,
+	"XBOXONE":    types.Console_XboxOne,
+	"XVID":       types.Movie_SD,
+	"XXX":        types.XXX_Other,
+	"XXX-IMGSET": types.XXX_Imageset,
+}
+
+// Sections that are a family of their own like TV-X264 or XXX-0DAY.
+var preSectionPrefixes = []struct {
+	Prefix   string
+	Category types.Category
+}{
+	{"TV-X264", types.TV_HD},
+	{"TV-HD", types.TV_HD},
+	{"TV-BLURAY", types.TV_HD},
+	{"TV-WEB", types.TV_WEBDL},
+	{"TV", types.TV_SD},
+	{"X264", types.Movie_HD},
+	{"XXX", types.XXX_Other},
+	{"GAMES", types.PC_Games},
+	{"EBOOK", types.Book_Ebook},
+}
+
+// FromPreSection returns the category of releases pred in the given scene
+// section, or Unknown if it isn't a section we know about.
+func FromPreSection(section string) types.Category {
+	section = strings.ToUpper(strings.TrimSpace(section))
+	section = strings.Replace(section, "_", "-", -1)
+	if section == "" {
+		return types.Unknown
+	}
+	if cat, ok := preSections[section]; ok {
+		return cat
+	}
+	for _, p := range preSectionPrefixes {
+		if strings.HasPrefix(section, p.Prefix) {
+			return p.Category
+		}
+	}
+	return types.Unknown
+}
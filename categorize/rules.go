@@ -0,0 +1,16 @@
+package categorize
+
+import (
+	"github.com/hobeone/gonab/types"
+)
+
+// FromRules returns the category of the first of rules matching a release and
+// that rule, or Unknown and nil if none match.  Rules must be compiled.
+func FromRules(rules []*types.CategoryRule, name, group string, size int64) (types.Category, *types.CategoryRule) {
+	for _, r := range rules {
+		if r.Matches(name, group, size) {
+			return types.Category(r.CategoryID), r
+		}
+	}
+	return types.Unknown, nil
+}
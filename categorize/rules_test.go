@@ -0,0 +1,41 @@
+package categorize
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+)
+
+func TestFromRules(t *testing.T) {
+	rules := []*types.CategoryRule{
+		{ID: 1, NameRegex: `(?i)\.S\d\dE\d\d\.`, GroupRegex: `^alt\.binaries\.teevee$`, MinSize: 100, MaxSize: 1000, CategoryID: int64(types.TV_Foreign)},
+		{ID: 2, NameRegex: `(?i)flac`, CategoryID: int64(types.Audio_Lossless)},
+	}
+	for _, r := range rules {
+		if err := r.Compile(); err != nil {
+			t.Fatalf("Error compiling rule %d: %v", r.ID, err)
+		}
+	}
+	tests := []struct {
+		Name  string
+		Group string
+		Size  int64
+		types.Category
+		RuleID int64
+	}{
+		{"Show.S01E01.720p", "alt.binaries.teevee", 500, types.TV_Foreign, 1},
+		{"Show.S01E01.720p", "alt.binaries.teevee", 50, types.Unknown, 0},
+		{"Show.S01E01.720p", "alt.binaries.teevee", 5000, types.Unknown, 0},
+		{"Show.S01E01.720p", "alt.binaries.hdtv", 500, types.Unknown, 0},
+		{"Show.S01E01.FLAC", "alt.binaries.hdtv", 500, types.Audio_Lossless, 2},
+	}
+	for _, tc := range tests {
+		cat, rule := FromRules(rules, tc.Name, tc.Group, tc.Size)
+		if cat != tc.Category {
+			t.Errorf("Expected %s in %s (%d bytes) to get category %s. Got: %s", tc.Name, tc.Group, tc.Size, tc.Category, cat)
+		}
+		if tc.RuleID == 0 && rule != nil || tc.RuleID != 0 && (rule == nil || rule.ID != tc.RuleID) {
+			t.Errorf("Expected %s in %s (%d bytes) to match rule %d. Got: %v", tc.Name, tc.Group, tc.Size, tc.RuleID, rule)
+		}
+	}
+}
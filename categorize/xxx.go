@@ -0,0 +1,45 @@
+package categorize
+
+import (
+	"regexp"
+
+	"github.com/hobeone/gonab/types"
+)
+
+var (
+	xxxRegex = wordRegex(`XXX|Porn(o|lation)?|Err?oti(ca|k)|Imageset|PictureSet|JAV[-._ ]Uncensored|SWE6RUS|Brazzers|Bang[-._ ]?Bros|Naughty[-._ ]?America|Reality[-._ ]?Kings|Evil[-._ ]?Angel`)
+
+	xxxTagRegex = wordRegex(`XXX`)
+
+	xxxCategories = []regexCategory{
+		{wordRegex(`Imageset|PictureSet|Pics?[-._ ]?Pack|Photo[-._ ]?Set`), types.XXX_Imageset},
+		{wordRegex(`Pack|SiteRip|Compilation`), types.XXX_Packs},
+		{wordRegex(`WEB[-._ ]?DL|WEB[-._ ]?Rip`), types.XXX_WEBDL},
+		{wordRegex(`720p|1080p|2160p|x264|x265|h[-._ ]?264|HEVC`), types.XXX_x264},
+		{wordRegex(`WMV`), types.XXX_WMV},
+		{wordRegex(`XviD|DivX|AVI`), types.XXX_XviD},
+		{regexp.MustCompile(`(?i)DVDR([^i]|$)|DVD[59]|[-._ ]DVD([-._ ]|$)`), types.XXX_DVD},
+		{wordRegex(`(480|576)p|SD[-._ ]?(TV|Rip)|MP4|MPEG`), types.XXX_SD},
+	}
+)
+
+func isXXX(name, group string, s *Step) types.Category {
+	if !s.hit(xxxRegex, name) {
+		return types.Unknown
+	}
+	// Episodes are only XXX if they say so, not for having Porn or Erotic in
+	// the name of the show.
+	if episodeRegex.MatchString(name) && !xxxTagRegex.MatchString(name) {
+		return types.Unknown
+	}
+	return s.run(xxxSubcategory, name, group)
+}
+
+// xxxSubcategory returns the XXX subcategory of name, which is known to be
+// XXX.
+func xxxSubcategory(name, group string, s *Step) types.Category {
+	if cat := firstMatch(name, xxxCategories, s); cat != types.Unknown {
+		return cat
+	}
+	return types.XXX_Other
+}
@@ -0,0 +1,29 @@
+package categorize
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+)
+
+var xxxMatches = []categoryMatches{
+	{"Brazzers.16.02.18.Some.Name.XXX.720p.MP4-KTR", "", types.XXX_x264},
+	{"Some.Site.16.02.18.Some.Name.XXX.WEB-DL.MP4-KTR", "", types.XXX_WEBDL},
+	{"Some.Site.16.02.18.Some.Name.XXX.SD.MP4-KTR", "", types.XXX_SD},
+	{"Some.Name.XXX.WMV-GRP", "", types.XXX_WMV},
+	{"Some.Name.XXX.XviD-GRP", "", types.XXX_XviD},
+	{"Some.Movie.XXX.DVDR-GRP", "", types.XXX_DVD},
+	{"Some.Site.XXX.Imageset-GRP", "", types.XXX_Imageset},
+	{"Some.Site.XXX.SiteRip-GRP", "", types.XXX_Packs},
+	{"Some.Name.XXX-GRP", "", types.XXX_Other},
+	{"Sleepy.Hollow.S03E11.720p.HDTV.x264-AVS", "", types.Unknown},
+}
+
+func TestIsXXX(t *testing.T) {
+	for _, m := range xxxMatches {
+		cat := isXXX(m.Name, m.Group, nil)
+		if cat != m.Category {
+			t.Errorf("Expected name:'%s' group:'%s' to get category: '%s'. Got: '%s'", m.Name, m.Group, m.Category, cat)
+		}
+	}
+}
@@ -36,15 +36,54 @@ func SetupCommands() {
 
 	regexcmd := &RegexImporter{}
 	App.Command("importregex", "Import regexes from nzedb").Action(regexcmd.run)
+
+	rxcmd := &RegexCommand{}
+	rxcmd.configure(App)
+
+	purge := &PurgeCommand{}
+	purge.configure(App)
+
+	predb := &PreDBCommand{}
+	predb.configure(App)
+
+	reqid := &RequestIDCommand{}
+	reqid.configure(App)
+
+	post := &PostProcessCommand{}
+	post.configure(App)
+
+	cats := &CategoriesCommand{}
+	cats.configure(App)
+
+	catz := &CategorizeCommand{}
+	catz.configure(App)
+
+	tv := &TVCommand{}
+	tv.configure(App)
+
+	movies := &MoviesCommand{}
+	movies.configure(App)
 }
 
 func commonInit() (*config.Config, *db.Handle) {
+	cfg, dbh := uncheckedInit()
+	err := dbh.CheckCategories()
+	if err != nil {
+		logrus.Fatalf("%v.  Fix them with gonab categories sync.", err)
+	}
+	return cfg, dbh
+}
+
+// uncheckedInit is commonInit without checking the categories in the
+// database, for commands that create or repair them.
+func uncheckedInit() (*config.Config, *db.Handle) {
 	if *debug {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
 	cfg := loadConfig(*configfile)
 
 	dbh := db.NewDBHandle(cfg.DB.Name, cfg.DB.Username, cfg.DB.Password, cfg.DB.Verbose)
+	dbh.NZBDir = cfg.NZB.Dir
 
 	return cfg, dbh
 }
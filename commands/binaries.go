@@ -6,10 +6,15 @@ import (
 	"gopkg.in/alecthomas/kingpin.v2"
 )
 
-type BinariesCommand struct{}
+type BinariesCommand struct {
+	BatchSize int
+	CacheSize int
+}
 
 func (s *BinariesCommand) configure(app *kingpin.Application) {
-	app.Command("makebinaries", "Create binaries from parts").Action(s.run)
+	cmd := app.Command("makebinaries", "Create binaries from parts").Action(s.run)
+	cmd.Flag("batch", "Number of parts to process and commit at a time.").Default("10000").IntVar(&s.BatchSize)
+	cmd.Flag("cache", "Maximum number of binaries to remember between batches.").Default("100000").IntVar(&s.CacheSize)
 }
 
 func (s *BinariesCommand) run(c *kingpin.ParseContext) error {
@@ -19,5 +24,5 @@ func (s *BinariesCommand) run(c *kingpin.ParseContext) error {
 	cfg := loadConfig(*configfile)
 
 	dbh := db.NewDBHandle(cfg.DB.Name, cfg.DB.Username, cfg.DB.Password, cfg.DB.Verbose)
-	return dbh.MakeBinaries()
+	return dbh.MakeBinariesBatched(s.BatchSize, s.CacheSize)
 }
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/categorize"
+	"github.com/hobeone/gonab/config"
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// CategoriesCommand manages categories and the rules releases are put in
+// them by.
+type CategoriesCommand struct {
+	Name        string
+	Group       string
+	MinSize     int64
+	MaxSize     int64
+	Size        int64
+	Category    int64
+	Ordinal     int
+	Disabled    bool
+	Description string
+	All         bool
+
+	IDs            []int64
+	Parent         int64
+	CategoryName   string
+	CategoryMin    int
+	Preview        bool
+	DisablePreview bool
+}
+
+func (cc *CategoriesCommand) configure(app *kingpin.Application) {
+	catCmd := app.Command("categories", "Manage categories")
+	catCmd.Command("list", "List all categories").Action(cc.list)
+
+	enable := catCmd.Command("enable", "Show releases in categories again").Action(cc.enable)
+	enable.Arg("id", "IDs of the categories").Required().Int64ListVar(&cc.IDs)
+
+	disable := catCmd.Command("disable", "Hide categories and their subcategories from caps and searches").Action(cc.disable)
+	disable.Arg("id", "IDs of the categories").Required().Int64ListVar(&cc.IDs)
+
+	set := catCmd.Command("set", "Change settings of categories").Action(cc.set)
+	set.Arg("id", "IDs of the categories").Required().Int64ListVar(&cc.IDs)
+	set.Flag("name", "New name, only for custom categories").StringVar(&cc.CategoryName)
+	set.Flag("description", "Description of the category").StringVar(&cc.Description)
+	set.Flag("min-size", "Minimum size of a release in bytes, 0 uses the parent's").Default("-1").IntVar(&cc.CategoryMin)
+	set.Flag("preview", "Show previews for releases in the category").BoolVar(&cc.Preview)
+	set.Flag("no-preview", "Don't show previews for releases in the category").BoolVar(&cc.DisablePreview)
+
+	add := catCmd.Command("add", "Add a site specific subcategory").Action(cc.add)
+	add.Flag("parent", "ID of the parent category").Required().Int64Var(&cc.Parent)
+	add.Flag("name", "Name of the category").Required().StringVar(&cc.CategoryName)
+
+	catCmd.Command("sync", "Rewrite the names and parents of the built in categories and add missing ones").Action(cc.sync)
+
+	rules := catCmd.Command("rules", "Manage rules tried before the built in categorizer")
+
+	addRule := rules.Command("add", "Add a category rule").Action(cc.addRule)
+	addRule.Flag("name", "Regex matching the names of releases, empty matches all").StringVar(&cc.Name)
+	addRule.Flag("group", "Regex matching the groups of releases, empty matches all").StringVar(&cc.Group)
+	addRule.Flag("min-size", "Minimum size of a release in bytes, 0 for no limit").Int64Var(&cc.MinSize)
+	addRule.Flag("max-size", "Maximum size of a release in bytes, 0 for no limit").Int64Var(&cc.MaxSize)
+	addRule.Flag("category", "Category ID to put matching releases in").Required().Int64Var(&cc.Category)
+	addRule.Flag("ordinal", "Rules with a lower ordinal are tried first").Default("0").IntVar(&cc.Ordinal)
+	addRule.Flag("disabled", "Add the rule without using it yet").BoolVar(&cc.Disabled)
+	addRule.Flag("description", "Why the rule was added").StringVar(&cc.Description)
+
+	listRules := rules.Command("list", "List category rules in the order they are tried").Action(cc.listRules)
+	listRules.Flag("all", "Also list disabled rules").BoolVar(&cc.All)
+
+	test := rules.Command("test", "Show which category a release would get").Action(cc.testRules)
+	test.Flag("group", "Group the release was posted to").Required().StringVar(&cc.Group)
+	test.Flag("size", "Size of the release in bytes").Int64Var(&cc.Size)
+	test.Arg("name", "Name of the release").Required().StringVar(&cc.Name)
+}
+
+// categoriesInit is commonInit that only warns about categories not matching
+// the code, so they can be fixed.
+func categoriesInit() (*config.Config, *db.Handle) {
+	cfg, dbh := uncheckedInit()
+	err := dbh.CheckCategories()
+	if err != nil {
+		logrus.Warn(err)
+	}
+	return cfg, dbh
+}
+
+func (cc *CategoriesCommand) list(c *kingpin.ParseContext) error {
+	_, dbh := categoriesInit()
+
+	cats, err := dbh.GetAllCategories()
+	if err != nil {
+		return fmt.Errorf("Error getting categories: %v", err)
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 5, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tParent\tActive\tCustom\tMin Size\tPreview\tDescription")
+	for _, dbc := range cats {
+		parent := ""
+		if dbc.ParentID.Valid {
+			parent = fmt.Sprintf("%d", dbc.ParentID.Int64)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%t\t%d\t%t\t%s\n", dbc.ID, dbc.Name, parent, dbc.Active, types.Category(dbc.ID).IsCustom(), dbc.MinSize, !dbc.DisablePreview, dbc.Description)
+	}
+	w.Flush()
+	return nil
+}
+
+func (cc *CategoriesCommand) sync(c *kingpin.ParseContext) error {
+	_, dbh := uncheckedInit()
+
+	changed, err := dbh.SyncCategories()
+	if err != nil {
+		return fmt.Errorf("Error syncing categories: %v", err)
+	}
+	fmt.Printf("Changed %d categories\n", changed)
+	return dbh.CheckCategories()
+}
+
+func (cc *CategoriesCommand) enable(c *kingpin.ParseContext) error {
+	return cc.setActive(true)
+}
+
+func (cc *CategoriesCommand) disable(c *kingpin.ParseContext) error {
+	return cc.setActive(false)
+}
+
+func (cc *CategoriesCommand) setActive(active bool) error {
+	_, dbh := categoriesInit()
+
+	action := "Disabled"
+	if active {
+		action = "Enabled"
+	}
+	for _, id := range cc.IDs {
+		err := dbh.SetCategoryActive(id, active)
+		if err != nil {
+			return fmt.Errorf("Error changing category %d: %v", id, err)
+		}
+		fmt.Printf("%s category %d\n", action, id)
+	}
+	return nil
+}
+
+func (cc *CategoriesCommand) set(c *kingpin.ParseContext) error {
+	_, dbh := categoriesInit()
+
+	edit := db.CategoryEdit{}
+	if cc.CategoryName != "" {
+		edit.Name = &cc.CategoryName
+	}
+	if cc.Description != "" {
+		edit.Description = &cc.Description
+	}
+	if cc.CategoryMin >= 0 {
+		edit.MinSize = &cc.CategoryMin
+	}
+	if cc.Preview && cc.DisablePreview {
+		return fmt.Errorf("--preview and --no-preview can't be used together")
+	}
+	if cc.Preview || cc.DisablePreview {
+		edit.DisablePreview = &cc.DisablePreview
+	}
+	if edit == (db.CategoryEdit{}) {
+		return fmt.Errorf("Nothing to change, give at least one of --name, --description, --min-size, --preview or --no-preview")
+	}
+	for _, id := range cc.IDs {
+		dbc, err := dbh.EditCategory(id, edit)
+		if err != nil {
+			return fmt.Errorf("Error changing category %d: %v", id, err)
+		}
+		fmt.Printf("Changed category %d (%s)\n", dbc.ID, dbc.Name)
+	}
+	return nil
+}
+
+func (cc *CategoriesCommand) add(c *kingpin.ParseContext) error {
+	_, dbh := categoriesInit()
+
+	dbc, err := dbh.AddCustomCategory(types.Category(cc.Parent), cc.CategoryName)
+	if err != nil {
+		return fmt.Errorf("Error adding category: %v", err)
+	}
+	fmt.Printf("Added category %d (%s)\n", dbc.ID, types.Category(dbc.ID))
+	return nil
+}
+
+func (cc *CategoriesCommand) addRule(c *kingpin.ParseContext) error {
+	_, dbh := categoriesInit()
+
+	rule := &types.CategoryRule{
+		Ordinal:     cc.Ordinal,
+		NameRegex:   cc.Name,
+		GroupRegex:  cc.Group,
+		MinSize:     cc.MinSize,
+		MaxSize:     cc.MaxSize,
+		CategoryID:  cc.Category,
+		Enabled:     !cc.Disabled,
+		Description: cc.Description,
+	}
+	err := dbh.AddCategoryRule(rule)
+	if err != nil {
+		return fmt.Errorf("Error adding category rule: %v", err)
+	}
+	fmt.Printf("Added category rule %d\n", rule.ID)
+	return nil
+}
+
+func (cc *CategoriesCommand) listRules(c *kingpin.ParseContext) error {
+	_, dbh := categoriesInit()
+
+	rules, err := dbh.GetCategoryRules(cc.All)
+	if err != nil {
+		return fmt.Errorf("Error getting category rules: %v", err)
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 5, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "ID\tOrdinal\tEnabled\tName\tGroup\tMin Size\tMax Size\tCategory\tDescription")
+	for _, r := range rules {
+		fmt.Fprintf(w, "%d\t%d\t%t\t%s\t%s\t%d\t%d\t%s\t%s\n", r.ID, r.Ordinal, r.Enabled, r.NameRegex, r.GroupRegex, r.MinSize, r.MaxSize, types.Category(r.CategoryID), r.Description)
+	}
+	w.Flush()
+	return nil
+}
+
+func (cc *CategoriesCommand) testRules(c *kingpin.ParseContext) error {
+	_, dbh := categoriesInit()
+
+	rules, err := dbh.GetCategoryRules(false)
+	if err != nil {
+		return fmt.Errorf("Error getting category rules: %v", err)
+	}
+	cat, rule := categorize.FromRules(rules, cc.Name, cc.Group, cc.Size)
+	if rule != nil {
+		fmt.Printf("Matched category rule %d (%s)\n", rule.ID, rule.Description)
+	} else {
+		fmt.Println("No category rule matched, used the built in categorizer.")
+		cat = categorize.Categorize(cc.Name, cc.Group)
+	}
+	fmt.Printf("Category: %s (%d)\n", cat, int64(cat))
+	return nil
+}
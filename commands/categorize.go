@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hobeone/gonab/categorize"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// CategorizeCommand shows how releases are categorized.
+type CategorizeCommand struct {
+	Group string
+	Size  int64
+	Name  string
+}
+
+func (cc *CategorizeCommand) configure(app *kingpin.Application) {
+	catCmd := app.Command("categorize", "Inspect the categorizer")
+	explain := catCmd.Command("explain", "Show every test the categorizer runs on a release name").Action(cc.explain)
+	explain.Flag("group", "Group the release was posted to").Required().StringVar(&cc.Group)
+	explain.Flag("size", "Size of the release in bytes, used by category rules").Int64Var(&cc.Size)
+	explain.Arg("name", "Name of the release").Required().StringVar(&cc.Name)
+}
+
+func (cc *CategorizeCommand) explain(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	rules, err := dbh.GetCategoryRules(false)
+	if err != nil {
+		return fmt.Errorf("Error getting category rules: %v", err)
+	}
+	fmt.Printf("Name:  %s\n", cc.Name)
+	fmt.Printf("Group: %s\n", cc.Group)
+	if cat, rule := categorize.FromRules(rules, cc.Name, cc.Group, cc.Size); rule != nil {
+		fmt.Printf("Category rule %d matched (%s), the categorizer isn't run.\n", rule.ID, rule.Description)
+		fmt.Printf("Category: %s (%d)\n", cat, int64(cat))
+		return nil
+	}
+
+	cat, steps := categorize.Explain(cc.Name, cc.Group)
+	printSteps(os.Stdout, steps, 0)
+	fmt.Printf("Category: %s (%d)\n", cat, int64(cat))
+	return nil
+}
+
+// printSteps prints a categorizer trace with sub-tests indented below their
+// test.
+func printSteps(out io.Writer, steps []categorize.Step, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, s := range steps {
+		if !s.Matched() {
+			fmt.Fprintf(out, "%s%s: no match\n", indent, s.Test)
+			continue
+		}
+		fmt.Fprintf(out, "%s%s: %s", indent, s.Test, s.Category)
+		if s.Match != "" {
+			fmt.Fprintf(out, " (matched %q)", s.Match)
+		}
+		fmt.Fprintln(out)
+		printSteps(out, s.Steps, depth+1)
+	}
+}
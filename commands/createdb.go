@@ -7,7 +7,7 @@ import (
 )
 
 func createdb(c *kingpin.ParseContext) error {
-	_, dbh := commonInit()
+	_, dbh := uncheckedInit()
 
 	d := dbh.DB.DB()
 	migrator, err := gomigrate.NewMigrator(d, gomigrate.Mysql{}, "db/migrations/mysql")
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dumpRecord is a single record of a CSV or JSON dump keyed by lower case
+// field name.
+type dumpRecord map[string]string
+
+// get returns the first of the given fields the record has.
+func (d dumpRecord) get(names ...string) string {
+	for _, n := range names {
+		if v, ok := d[n]; ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// readDumpFile reads all records of a dump, guessing the format from the
+// file extension if format is auto.
+func readDumpFile(path, format string) ([]dumpRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if format == "auto" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch format {
+	case "csv":
+		return readCSVDump(f)
+	case "json":
+		return readJSONDump(f)
+	}
+	return nil, fmt.Errorf("Can't guess the format of %s, use --format", path)
+}
+
+// readCSVDump reads records from a CSV file whose first line names the
+// columns.
+func readCSVDump(r io.Reader) ([]dumpRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading CSV header: %v", err)
+	}
+	for i, h := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+	records := []dumpRecord{}
+	line := 1
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CSV line %d: %v", line, err)
+		}
+		rec := dumpRecord{}
+		for i, v := range row {
+			if i < len(header) {
+				rec[header[i]] = v
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// readJSONDump reads records from a JSON array of objects.  Fields of nested
+// objects are named parent.field and arrays are joined with |, using the name
+// of objects in them.
+func readJSONDump(r io.Reader) ([]dumpRecord, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var objs []map[string]interface{}
+	err := dec.Decode(&objs)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding JSON: %v", err)
+	}
+	records := make([]dumpRecord, 0, len(objs))
+	for _, obj := range objs {
+		rec := dumpRecord{}
+		addJSONFields(rec, "", obj)
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func addJSONFields(rec dumpRecord, prefix string, obj map[string]interface{}) {
+	for k, v := range obj {
+		k = prefix + strings.ToLower(k)
+		switch v := v.(type) {
+		case nil:
+		case map[string]interface{}:
+			addJSONFields(rec, k+".", v)
+		case []interface{}:
+			vals := []string{}
+			for _, e := range v {
+				if m, ok := e.(map[string]interface{}); ok {
+					e = m["name"]
+				}
+				if e != nil {
+					vals = append(vals, fmt.Sprint(e))
+				}
+			}
+			rec[k] = strings.Join(vals, "|")
+		default:
+			rec[k] = fmt.Sprint(v)
+		}
+	}
+}
+
+// dumpImport saves the items parsed from a dump and matches the releases
+// made before they were known to them.
+type dumpImport struct {
+	Item     string // What the dump holds, e.g. show.
+	Releases string // What releases are matched to items, e.g. TV.
+	// Save saves items from up to to and returns how many were added and
+	// updated.
+	Save  func(from, to int) (int, int, error)
+	Count func() (int64, error)
+	// Match returns how many releases were checked and matched.
+	Match func(batch int) (int, int, error)
+}
+
+// run saves count items batch at a time and then matches releases to them.
+func (di *dumpImport) run(count, batch int) error {
+	if batch < 1 {
+		batch = 1000
+	}
+	added, updated := 0, 0
+	for i := 0; i < count; i += batch {
+		end := i + batch
+		if end > count {
+			end = count
+		}
+		a, u, err := di.Save(i, end)
+		if err != nil {
+			return err
+		}
+		added += a
+		updated += u
+	}
+	total, err := di.Count()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %d %ss and updated %d, %d %ss in total\n", added, di.Item, updated, total, di.Item)
+
+	checked, matched, err := di.Match(batch)
+	if err != nil {
+		return fmt.Errorf("Error matching %s releases: %v", di.Releases, err)
+	}
+	fmt.Printf("Matched %d of %d %s releases without a %s\n", matched, checked, di.Releases, di.Item)
+	return nil
+}
@@ -7,7 +7,12 @@ import (
 )
 
 type GroupCommand struct {
-	Groups []string
+	Groups        []string
+	MinCompletion float64
+	MinFiles      int
+	MaxFiles      int
+	MinSize       int64
+	MaxSize       int64
 }
 
 func (g *GroupCommand) configure(app *kingpin.Application) {
@@ -19,6 +24,14 @@ func (g *GroupCommand) configure(app *kingpin.Application) {
 
 	dis := grpCmd.Command("disable", "Disable a group").Action(g.disable)
 	dis.Arg("group", "Group name to disable").Required().StringsVar(&g.Groups)
+
+	set := grpCmd.Command("set", "Change settings of a group").Action(g.set)
+	set.Arg("group", "Group name to change").Required().StringsVar(&g.Groups)
+	set.Flag("min-completion", "Percentage of segments needed to make a release, 0 uses the default").Default("-1").Float64Var(&g.MinCompletion)
+	set.Flag("min-files", "Minimum number of files in a release").Default("-1").IntVar(&g.MinFiles)
+	set.Flag("max-files", "Maximum number of files in a release, 0 for no limit").Default("-1").IntVar(&g.MaxFiles)
+	set.Flag("min-size", "Minimum size of a release in bytes").Default("-1").Int64Var(&g.MinSize)
+	set.Flag("max-size", "Maximum size of a release in bytes, 0 for no limit").Default("-1").Int64Var(&g.MaxSize)
 }
 
 func (g *GroupCommand) list(c *kingpin.ParseContext) error {
@@ -30,7 +43,8 @@ func (g *GroupCommand) list(c *kingpin.ParseContext) error {
 	}
 
 	for _, g := range groups {
-		fmt.Printf("Name: %s, First %d, Last: %d\n", g.Name, g.First, g.Last)
+		fmt.Printf("Name: %s, First %d, Last: %d, Min Completion: %.1f%%, Files: %d-%d, Size: %d-%d\n",
+			g.Name, g.First, g.Last, g.MinCompletion, g.MinFiles, g.MaxFiles, g.MinSize, g.MaxSize)
 	}
 	return nil
 }
@@ -61,4 +75,39 @@ func (g *GroupCommand) disable(c *kingpin.ParseContext) error {
 	return nil
 }
 
+func (g *GroupCommand) set(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	for _, group := range g.Groups {
+		dbgroup, err := dbh.FindGroupByName(group)
+		if err != nil {
+			return fmt.Errorf("Error finding group %s: %v", group, err)
+		}
+		if g.MinCompletion >= 0 {
+			if g.MinCompletion > 100 {
+				return fmt.Errorf("Min completion must be between 0 and 100")
+			}
+			dbgroup.MinCompletion = g.MinCompletion
+		}
+		if g.MinFiles >= 0 {
+			dbgroup.MinFiles = g.MinFiles
+		}
+		if g.MaxFiles >= 0 {
+			dbgroup.MaxFiles = g.MaxFiles
+		}
+		if g.MinSize >= 0 {
+			dbgroup.MinSize = g.MinSize
+		}
+		if g.MaxSize >= 0 {
+			dbgroup.MaxSize = g.MaxSize
+		}
+		err = dbh.SaveGroup(dbgroup)
+		if err != nil {
+			return fmt.Errorf("Error saving group %s: %v", group, err)
+		}
+		fmt.Printf("Updated group %s\n", group)
+	}
+	return nil
+}
+
 //TODO: add delete group
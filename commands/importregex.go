@@ -279,7 +279,7 @@ func (regeximporter *RegexImporter) run(c *kingpin.ParseContext) error {
 	dbh := db.NewDBHandle(cfg.DB.Name, cfg.DB.Username, cfg.DB.Password, cfg.DB.Verbose)
 	newcount := 0
 	tx := dbh.DB.Begin()
-	tx.Where("id < ?", 100000).Delete(&types.Regex{})
+	tx.Where("id < ?", db.LocalRegexMinID).Delete(&types.Regex{})
 	for _, dbregex := range regexes {
 		err = tx.Create(dbregex).Error
 		if err != nil {
@@ -289,7 +289,7 @@ func (regeximporter *RegexImporter) run(c *kingpin.ParseContext) error {
 		}
 		newcount++
 	}
-	tx.Where("id < ?", 100000).Delete(&types.Regex{Kind: "collection"})
+	tx.Where("id < ?", db.LocalRegexMinID).Delete(&types.Regex{Kind: "collection"})
 	for _, dbregex := range collectionRegexes {
 		err = tx.Create(dbregex).Error
 		if err != nil {
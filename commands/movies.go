@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// MoviesCommand manages the local movie database.
+type MoviesCommand struct {
+	File      string
+	Format    string
+	BatchSize int
+}
+
+func (m *MoviesCommand) configure(app *kingpin.Application) {
+	mgrp := app.Command("movies", "Manage the local movie database")
+	imp := mgrp.Command("import", "Import movies from a CSV or JSON dump and match movie releases to them").Action(m.importMovies)
+	imp.Flag("format", "Format of the dump, auto guesses from the file extension").Default("auto").EnumVar(&m.Format, "auto", "csv", "json")
+	imp.Flag("batch", "Number of movies to save per transaction").Default("1000").IntVar(&m.BatchSize)
+	imp.Arg("file", "Dump to import").Required().ExistingFileVar(&m.File)
+}
+
+func (m *MoviesCommand) importMovies(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	records, err := readDumpFile(m.File, m.Format)
+	if err != nil {
+		return err
+	}
+	movies := moviesFromRecords(records)
+	logrus.Infof("Parsed %d movies from %s", len(movies), m.File)
+
+	imp := dumpImport{
+		Item:     "movie",
+		Releases: "movie",
+		Save: func(from, to int) (int, int, error) {
+			return dbh.SaveMovies(movies[from:to])
+		},
+		Count: dbh.CountMovies,
+		Match: dbh.MatchMovies,
+	}
+	return imp.run(len(movies), m.BatchSize)
+}
+
+// moviesFromRecords converts the records of a dump into movies, skipping the
+// ones that can't be parsed.  See movieFieldNames for the field names
+// understood.
+func moviesFromRecords(records []dumpRecord) []*types.Movie {
+	movies := []*types.Movie{}
+	for i, rec := range records {
+		movie, err := movieFromRecord(rec)
+		if err != nil {
+			logrus.Errorf("Skipping record %d: %v", i+1, err)
+			continue
+		}
+		movies = append(movies, movie)
+	}
+	return movies
+}
+
+// Alternative names used by IMDB and TMDB dumps.
+var movieFieldNames = map[string][]string{
+	"imdb":  {"imdb_id", "imdbid", "imdb", "tconst"},
+	"tmdb":  {"tmdb_id", "tmdbid", "tmdb", "id"},
+	"title": {"title", "name", "primarytitle", "original_title"},
+	"year":  {"year", "startyear", "release_date"},
+}
+
+func getMovieField(rec dumpRecord, name string) string {
+	return rec.get(movieFieldNames[name]...)
+}
+
+// movieFromRecord converts a single record into a Movie.  The title and one of
+// the IDs are required.
+func movieFromRecord(rec dumpRecord) (*types.Movie, error) {
+	movie := &types.Movie{
+		Title: getMovieField(rec, "title"),
+	}
+	if movie.Title == "" {
+		return nil, fmt.Errorf("no title")
+	}
+	var err error
+	if v := getMovieField(rec, "imdb"); v != "" {
+		movie.IMDBID, err = processing.ParseIMDBID(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v := getMovieField(rec, "tmdb"); v != "" {
+		movie.TMDBID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TMDB id %q", v)
+		}
+	}
+	if movie.IMDBID == 0 && movie.TMDBID == 0 {
+		return nil, fmt.Errorf("no IMDB or TMDB id for %s", movie.Title)
+	}
+	// Dates like 2015-09-30 give the year.
+	if v := getMovieField(rec, "year"); len(v) >= 4 {
+		movie.Year, err = strconv.Atoi(v[:4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid year %q", v)
+		}
+	}
+	return movie, nil
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseMoviesCSV(t *testing.T) {
+	RegisterTestingT(t)
+	f, err := os.Open("testdata/movies.csv")
+	if err != nil {
+		t.Fatalf("Error opening testdata: %v", err)
+	}
+	defer f.Close()
+	records, err := readCSVDump(f)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	movies := moviesFromRecords(records)
+	// Lines without an ID or with a broken one are skipped.
+	Expect(movies).To(HaveLen(2))
+	Expect(movies[0]).To(Equal(&types.Movie{IMDBID: 3659388, Title: "The Martian", Year: 2015}))
+	Expect(movies[1]).To(Equal(&types.Movie{IMDBID: 62622, Title: "2001: A Space Odyssey", Year: 1968}))
+}
+
+func TestParseMoviesJSON(t *testing.T) {
+	RegisterTestingT(t)
+	f, err := os.Open("testdata/movies.json")
+	if err != nil {
+		t.Fatalf("Error opening testdata: %v", err)
+	}
+	defer f.Close()
+	records, err := readJSONDump(f)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	movies := moviesFromRecords(records)
+	Expect(movies).To(HaveLen(2))
+	Expect(movies[0]).To(Equal(&types.Movie{IMDBID: 3659388, TMDBID: 286217, Title: "The Martian", Year: 2015}))
+	Expect(movies[1]).To(Equal(&types.Movie{TMDBID: 76341, Title: "Mad Max: Fury Road", Year: 2015}))
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/hobeone/gonab/processing"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// PostProcessCommand runs releases through the post processing stages.
+type PostProcessCommand struct {
+	BatchSize   int
+	MaxAttempts int
+	Stages      []string
+}
+
+func (p *PostProcessCommand) configure(app *kingpin.Application) {
+	cmd := app.Command("postprocess", "Run new releases through the post processing stages").Action(p.run)
+	cmd.Flag("batch", "Number of releases to load at a time").Default("100").IntVar(&p.BatchSize)
+	cmd.Flag("max-attempts", "Mark releases failed after a stage failed this many times").Default("3").IntVar(&p.MaxAttempts)
+	names := []string{}
+	for _, s := range processing.Stages() {
+		names = append(names, s.Name)
+	}
+	cmd.Flag("stage", "Only run this stage, can be repeated").EnumsVar(&p.Stages, names...)
+}
+
+func (p *PostProcessCommand) run(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	only := map[string]bool{}
+	for _, s := range p.Stages {
+		only[s] = true
+	}
+	for _, stage := range processing.Stages() {
+		if len(only) > 0 && !only[stage.Name] {
+			continue
+		}
+		stats, err := dbh.RunStage(stage, p.BatchSize, p.MaxAttempts)
+		if err != nil {
+			return fmt.Errorf("Error running stage %s: %v", stage.Name, err)
+		}
+		total := stats.Advanced + stats.Retried + stats.Failed + stats.Hidden
+		fmt.Printf("%s: %d releases, %d advanced, %d to retry, %d failed, %d hidden\n", stage.Name, total, stats.Advanced, stats.Retried, stats.Failed, stats.Hidden)
+	}
+	return nil
+}
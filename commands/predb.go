@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// PreDBCommand manages the local copy of the predb.
+type PreDBCommand struct {
+	File      string
+	Format    string
+	BatchSize int
+}
+
+func (p *PreDBCommand) configure(app *kingpin.Application) {
+	pgrp := app.Command("predb", "Manage the local predb")
+	imp := pgrp.Command("import", "Import pres from a CSV or JSON dump").Action(p.importPres)
+	imp.Flag("format", "Format of the dump, auto guesses from the file extension").Default("auto").EnumVar(&p.Format, "auto", "csv", "json")
+	imp.Flag("batch", "Number of pres to save per transaction").Default("1000").IntVar(&p.BatchSize)
+	imp.Arg("file", "Dump to import").Required().ExistingFileVar(&p.File)
+}
+
+func (p *PreDBCommand) importPres(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	records, err := readDumpFile(p.File, p.Format)
+	if err != nil {
+		return err
+	}
+	pres := presFromRecords(records)
+	logrus.Infof("Parsed %d pres from %s", len(pres), p.File)
+
+	batch := p.BatchSize
+	if batch < 1 {
+		batch = 1000
+	}
+	added, updated := 0, 0
+	for i := 0; i < len(pres); i += batch {
+		end := i + batch
+		if end > len(pres) {
+			end = len(pres)
+		}
+		a, u, err := dbh.SavePreDBs(pres[i:end])
+		if err != nil {
+			return err
+		}
+		added += a
+		updated += u
+	}
+	total, err := dbh.CountPreDBs()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %d pres and updated %d, %d pres in total\n", added, updated, total)
+	return nil
+}
+
+// presFromRecords converts the records of a dump into pres, skipping the ones
+// that can't be parsed.  See preDBFieldNames for the field names understood.
+func presFromRecords(records []dumpRecord) []*types.PreDB {
+	pres := []*types.PreDB{}
+	for i, rec := range records {
+		pre, err := preDBFromRecord(rec)
+		if err != nil {
+			logrus.Errorf("Skipping record %d: %v", i+1, err)
+			continue
+		}
+		pres = append(pres, pre)
+	}
+	return pres
+}
+
+// Alternative names used by the different predb dumps.
+var preDBFieldNames = map[string][]string{
+	"title":      {"title", "name", "release"},
+	"category":   {"category", "section", "cat"},
+	"size":       {"size"},
+	"files":      {"files"},
+	"nuked":      {"nuked", "nuke", "status"},
+	"nukereason": {"nukereason", "nuke_reason", "reason"},
+	"pretime":    {"pretime", "pre_time", "predate", "time"},
+	"source":     {"source"},
+}
+
+func getPreDBField(rec dumpRecord, name string) string {
+	return rec.get(preDBFieldNames[name]...)
+}
+
+// preDBFromRecord converts a single record into a PreDB.  Only the title is
+// required.
+func preDBFromRecord(rec dumpRecord) (*types.PreDB, error) {
+	pre := &types.PreDB{
+		Title:      getPreDBField(rec, "title"),
+		Category:   getPreDBField(rec, "category"),
+		NukeReason: getPreDBField(rec, "nukereason"),
+		Source:     getPreDBField(rec, "source"),
+	}
+	if pre.Title == "" {
+		return nil, fmt.Errorf("no title")
+	}
+	var err error
+	if v := getPreDBField(rec, "size"); v != "" {
+		pre.Size, err = parsePreSize(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v := getPreDBField(rec, "files"); v != "" {
+		pre.Files, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file count %q", v)
+		}
+	}
+	if v := getPreDBField(rec, "nuked"); v != "" {
+		pre.Nuked = parsePreNuked(v)
+	}
+	if v := getPreDBField(rec, "pretime"); v != "" {
+		pre.PreTime, err = parsePreTime(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pre, nil
+}
+
+var preSizeRegex = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([KMGT]i?B?|B)?$`)
+
+// parsePreSize parses a size in bytes or with a unit like 700MB or 4.3 GB.
+func parsePreSize(s string) (int64, error) {
+	m := preSizeRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	size, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if m[2] != "" {
+		switch strings.ToUpper(m[2][:1]) {
+		case "K":
+			size *= 1 << 10
+		case "M":
+			size *= 1 << 20
+		case "G":
+			size *= 1 << 30
+		case "T":
+			size *= 1 << 40
+		}
+	}
+	return int64(size), nil
+}
+
+// parsePreNuked understands booleans and nzedb's nuke status where 0 and 3
+// mean not or no longer nuked.
+func parsePreNuked(s string) bool {
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	switch strings.ToLower(s) {
+	case "yes", "nuked", "modnuked":
+		return true
+	case "no", "unnuked", "":
+		return false
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return i != 0 && i != 3
+	}
+	return false
+}
+
+var preTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parsePreTime parses unix timestamps and the usual date formats in UTC.
+func parsePreTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	for _, f := range preTimeFormats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid pre time %q", s)
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestParsePreDBCSV(t *testing.T) {
+	RegisterTestingT(t)
+	f, err := os.Open("testdata/predb.csv")
+	if err != nil {
+		t.Fatalf("Error opening testdata: %v", err)
+	}
+	defer f.Close()
+	records, err := readCSVDump(f)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	pres := presFromRecords(records)
+	// Lines without a title or with a broken size are skipped.
+	Expect(pres).To(HaveLen(2))
+	Expect(pres[0]).To(Equal(&types.PreDB{
+		Title:    "Sleepy.Hollow.S03E11.720p.HDTV.x264-AVS",
+		Category: "TV-X264",
+		Size:     1288490188,
+		Files:    27,
+		PreTime:  time.Unix(1455683100, 0).UTC(),
+		Source:   "orlydb",
+	}))
+	Expect(pres[1].Nuked).To(BeTrue())
+	Expect(pres[1].NukeReason).To(Equal("mislabeled"))
+	Expect(pres[1].Size).To(Equal(int64(7340032000)))
+	Expect(pres[1].PreTime).To(Equal(time.Date(2016, 2, 17, 4, 25, 0, 0, time.UTC)))
+}
+
+func TestParsePreDBJSON(t *testing.T) {
+	RegisterTestingT(t)
+	f, err := os.Open("testdata/predb.json")
+	if err != nil {
+		t.Fatalf("Error opening testdata: %v", err)
+	}
+	defer f.Close()
+	records, err := readJSONDump(f)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	pres := presFromRecords(records)
+	Expect(pres).To(HaveLen(2))
+	Expect(pres[0].Size).To(Equal(int64(1288490188)))
+	Expect(pres[0].Nuked).To(BeFalse())
+	Expect(pres[1].Title).To(Equal("Artist-Album-WEB-2016-GRP"))
+	Expect(pres[1].Category).To(Equal("MP3"))
+	Expect(pres[1].Size).To(Equal(int64(95 << 20)))
+	Expect(pres[1].Nuked).To(BeTrue())
+	Expect(pres[1].NukeReason).To(Equal("dupe"))
+}
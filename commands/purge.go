@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hobeone/gonab/db"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// PurgeCommand deletes stale parts, binaries and releases.
+type PurgeCommand struct {
+	PartAge       time.Duration
+	RetentionDays int
+	BatchSize     int
+	DryRun        bool
+}
+
+func (p *PurgeCommand) configure(app *kingpin.Application) {
+	cmd := app.Command("purge", "Delete incomplete binaries and parts and releases past retention").Action(p.run)
+	cmd.Flag("age", "Purge parts and binaries added longer ago than this (e.g. 72h).  Defaults to Purge.PartAgeHours from the config.").DurationVar(&p.PartAge)
+	cmd.Flag("retention", "Purge releases older than this many days, 0 keeps all releases.  Defaults to NewsServer.RetentionDays from the config.").Default("-1").IntVar(&p.RetentionDays)
+	cmd.Flag("batch", "Number of rows to delete per transaction.  Defaults to Purge.BatchSize from the config.").IntVar(&p.BatchSize)
+	cmd.Flag("dry-run", "Only show what would be deleted.").BoolVar(&p.DryRun)
+}
+
+func (p *PurgeCommand) run(c *kingpin.ParseContext) error {
+	cfg, dbh := commonInit()
+
+	age := p.PartAge
+	if age == 0 {
+		age = time.Duration(cfg.Purge.PartAgeHours) * time.Hour
+	}
+	if age <= 0 {
+		return fmt.Errorf("Part age must be greater than zero")
+	}
+	retention := p.RetentionDays
+	if retention < 0 {
+		retention = cfg.NewsServer.RetentionDays
+	}
+	batch := p.BatchSize
+	if batch == 0 {
+		batch = cfg.Purge.BatchSize
+	}
+
+	now := time.Now()
+	opts := db.PurgeOptions{
+		PartsBefore: now.Add(-age),
+		BatchSize:   batch,
+		DryRun:      p.DryRun,
+	}
+	if retention > 0 {
+		opts.ReleasesBefore = now.AddDate(0, 0, -retention)
+	}
+
+	stats, err := dbh.Purge(opts)
+	if err != nil {
+		return err
+	}
+	verb := "Purged"
+	if p.DryRun {
+		verb = "Would purge"
+	}
+	fmt.Printf("%s parts, binaries and missed messages added before %s:\n", verb, opts.PartsBefore.Format(time.RFC3339))
+	fmt.Printf("  %d binaries\n  %d parts\n  %d segments\n  %d missed messages\n", stats.Binaries, stats.Parts, stats.Segments, stats.MissedMessages)
+	if retention > 0 {
+		fmt.Printf("%s %d releases older than %d days\n", verb, stats.Releases, retention)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/hobeone/gonab/db"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// RegexCommand shows information about the collection and release regexes.
+type RegexCommand struct {
+	Kind        string
+	Unused      bool
+	Group       string
+	Poster      string
+	Subject     string
+	Regex       string
+	Description string
+	Ordinal     int
+	Disabled    bool
+}
+
+func (r *RegexCommand) configure(app *kingpin.Application) {
+	rgrp := app.Command("regex", "Inspect regexes")
+	stats := rgrp.Command("stats", "Show how often each regex has matched").Action(r.stats)
+	stats.Flag("kind", "Kind of regex to show: collection or release").Default(db.CollectionRegexKind).EnumVar(&r.Kind, db.CollectionRegexKind, db.ReleaseRegexKind)
+	stats.Flag("unused", "Only show regexes that have never matched").BoolVar(&r.Unused)
+
+	test := rgrp.Command("test", "Show how a subject is turned into binary and release names").Action(r.test)
+	test.Flag("group", "Group the subject was posted to").Required().StringVar(&r.Group)
+	test.Flag("poster", "Poster of the subject").StringVar(&r.Poster)
+	test.Arg("subject", "Subject to test").Required().StringVar(&r.Subject)
+
+	add := rgrp.Command("add", "Add a local regex that survives importing regexes").Action(r.add)
+	add.Flag("kind", "Kind of regex to add: collection or release").Default(db.ReleaseRegexKind).EnumVar(&r.Kind, db.CollectionRegexKind, db.ReleaseRegexKind)
+	add.Flag("group", "Regex matching the groups to use the regex for").Required().StringVar(&r.Group)
+	add.Flag("description", "What the regex matches, usually an example subject").StringVar(&r.Description)
+	add.Flag("ordinal", "Regexes with a lower ordinal are tried first").Default("0").IntVar(&r.Ordinal)
+	add.Flag("disabled", "Add the regex without using it yet").BoolVar(&r.Disabled)
+	add.Arg("regex", "Regex with named captures that are joined to make the name").Required().StringVar(&r.Regex)
+}
+
+func (r *RegexCommand) stats(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	stats, err := dbh.GetRegexStats(r.Kind)
+	if err != nil {
+		return fmt.Errorf("Error getting regex stats: %v", err)
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 5, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "ID\tHits\tLast Matched\tGroup\tDescription")
+	unused := 0
+	for _, s := range stats {
+		if s.Hits == 0 {
+			unused++
+		} else if r.Unused {
+			continue
+		}
+		lastMatched := "never"
+		if !s.LastMatched.IsZero() {
+			lastMatched = s.LastMatched.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", s.RegexID, s.Hits, lastMatched, s.GroupRegex, s.Description)
+	}
+	w.Flush()
+	fmt.Printf("%d %s regexes, %d never matched\n", len(stats), r.Kind, unused)
+	return nil
+}
+
+func (r *RegexCommand) test(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	collectionRegexes, err := dbh.GetCollectionRegexes()
+	if err != nil {
+		return fmt.Errorf("Error getting collection regexes: %v", err)
+	}
+	releaseRegexes, err := dbh.GetReleaseRegexes()
+	if err != nil {
+		return fmt.Errorf("Error getting release regexes: %v", err)
+	}
+	nameCleaner := db.NewNameCleaner(collectionRegexes)
+	releaseCleaner, err := db.NewReleaseCleaner(releaseRegexes)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Subject: %s\n", r.Subject)
+	fmt.Printf("Group:   %s\n", r.Group)
+	part, total := db.GetPartsFromSubject(r.Subject)
+	fmt.Printf("Parts:   %d of %d\n", part, total)
+
+	fmt.Println("\nCollection regexes:")
+	printRegexTraces(nameCleaner.Explain(r.Subject, r.Group))
+	name, regexID := nameCleaner.Match(r.Subject, r.Poster, r.Group)
+	if regexID == db.SubjectCleanerRegexID {
+		fmt.Println("No collection regex matched, used the generic subject cleaner.")
+	}
+	fmt.Printf("Binary name: %s\n", name)
+
+	// Releases are named from the subject of the binary, not the cleaned name.
+	fmt.Println("\nRelease regexes:")
+	printRegexTraces(releaseCleaner.RegexCleaner.Explain(r.Subject, r.Group))
+	releaseCleaner.PreDB = dbh
+	releaseCleaner.RequestIDs = dbh
+	cleaned, err := releaseCleaner.Resolve(r.Subject, r.Poster, r.Group, 0)
+	if err != nil {
+		return err
+	}
+	if cleaned.RegexID == db.NoRegexID {
+		_, cleanerName := db.HardcodedReleaseName(r.Subject, r.Group)
+		fmt.Printf("No release regex matched, used the %s cleaner.\n", cleanerName)
+	}
+	if cleaned.RequestID != 0 {
+		if cleaned.RequestResolved {
+			fmt.Printf("Named from request ID %d\n", cleaned.RequestID)
+		} else {
+			fmt.Printf("Request ID %d is unknown, will be retried\n", cleaned.RequestID)
+		}
+	}
+	if cleaned.PreDB != nil {
+		fmt.Printf("Named from pre %d (%s)\n", cleaned.PreDB.ID, cleaned.PreDB.Category)
+	}
+	fmt.Printf("Release name: %s\n", cleaned.Name)
+	return nil
+}
+
+func (r *RegexCommand) add(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	regex := &types.Regex{
+		Regex:       r.Regex,
+		GroupRegex:  r.Group,
+		Description: r.Description,
+		Ordinal:     r.Ordinal,
+		Status:      !r.Disabled,
+		Kind:        r.Kind,
+	}
+	err := dbh.AddLocalRegex(regex)
+	if err != nil {
+		return fmt.Errorf("Error adding regex: %v", err)
+	}
+	fmt.Printf("Added %s regex %d\n", r.Kind, regex.ID)
+	return nil
+}
+
+func printRegexTraces(traces []db.RegexTrace) {
+	if len(traces) == 0 {
+		fmt.Println("  No regexes for this group.")
+		return
+	}
+	won := false
+	for _, t := range traces {
+		status := "no match"
+		if t.Matched {
+			status = "match"
+			if !won {
+				status = "match (used)"
+				won = true
+			}
+		}
+		fmt.Printf("  [%d] %s: %s\n", t.Regex.ID, status, t.Regex.Description)
+		fmt.Printf("      %s\n", t.Regex.Regex)
+		if !t.Matched {
+			continue
+		}
+		keys := make([]string, 0, len(t.Captures))
+		for k := range t.Captures {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("      %s = %q\n", k, t.Captures[k])
+		}
+		fmt.Printf("      result: %s\n", t.Result)
+	}
+}
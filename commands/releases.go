@@ -2,10 +2,15 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/hobeone/gonab/db"
@@ -22,6 +27,21 @@ type ReleasesCommand struct {
 
 	Categories []int64
 	SearchTerm string
+
+	Group     string
+	From      string
+	To        string
+	DryRun    bool
+	BatchSize int
+	Unlock    bool
+
+	Name         string
+	SearchName   string
+	EditCategory string
+	Hide         bool
+	Unhide       bool
+
+	Duplicates bool
 }
 
 func (r *ReleasesCommand) configure(app *kingpin.Application) {
@@ -32,11 +52,56 @@ func (r *ReleasesCommand) configure(app *kingpin.Application) {
 	rgrpList.Flag("limit", "Number of releases to list").Short('l').Default("10").IntVar(&r.Limit)
 	rgrpList.Flag("categories", "Only show releases from this category").Short('c').Int64ListVar(&r.Categories)
 	rgrpList.Flag("search", "Only show releases that match this search term").Short('s').StringVar(&r.SearchTerm)
+	rgrpList.Flag("duplicates", "Also show reposts and copies of other releases").BoolVar(&r.Duplicates)
 
 	rgrpExportNZB := rgrp.Command("exportnzb", "Write NZB for release to file").Action(r.exportNZB)
 	rgrpExportNZB.Flag("id", "ID of release to export").Required().Int64Var(&r.ReleaseID)
 	rgrpExportNZB.Flag("file", "Filename to write to.  If not given use the name of the release +'.nzb'").StringVar(&r.FilePath)
 	rgrpExportNZB.Flag("dir", "Directory to write to.").Default(".").StringVar(&r.DirPath)
+
+	rgrpRecat := rgrp.Command("recategorize", "Run existing releases through the categorizer again").Action(r.recategorize)
+	rgrpRecat.Flag("group", "Only recategorize releases from this group").StringVar(&r.Group)
+	rgrpRecat.Flag("categories", "Only recategorize releases in this category").Short('c').Int64ListVar(&r.Categories)
+	rgrpRecat.Flag("from", "Only recategorize releases posted on or after this date (YYYY-MM-DD)").StringVar(&r.From)
+	rgrpRecat.Flag("to", "Only recategorize releases posted before this date (YYYY-MM-DD)").StringVar(&r.To)
+	rgrpRecat.Flag("dry-run", "Show what would change without updating releases").BoolVar(&r.DryRun)
+	rgrpRecat.Flag("batch", "Number of releases to update per transaction").Default("1000").IntVar(&r.BatchSize)
+
+	rgrpLock := rgrp.Command("lock-category", "Keep recategorize from changing the category of a release").Action(r.lockCategory)
+	rgrpLock.Flag("id", "ID of release to lock").Required().Int64Var(&r.ReleaseID)
+	rgrpLock.Flag("unlock", "Unlock the category instead").BoolVar(&r.Unlock)
+
+	rgrpEdit := rgrp.Command("edit", "Change a release by hand.  Edited fields aren't changed by recategorize, renaming or post processing").Action(r.edit)
+	rgrpEdit.Flag("id", "ID of release to edit").Required().Int64Var(&r.ReleaseID)
+	rgrpEdit.Flag("name", "New name of the release, also sets the search name").StringVar(&r.Name)
+	rgrpEdit.Flag("search-name", "New search name of the release").StringVar(&r.SearchName)
+	rgrpEdit.Flag("category", "New category ID of the release").StringVar(&r.EditCategory)
+	rgrpEdit.Flag("hide", "Hide the release from searches").BoolVar(&r.Hide)
+	rgrpEdit.Flag("unhide", "Show a hidden or failed release in searches again").BoolVar(&r.Unhide)
+
+	rgrpConvert := rgrp.Command("convertnzbs", "Compress the NZBs still stored in the release table, and move them to the NZB directory if one is configured").Action(r.convertNZBs)
+	rgrpConvert.Flag("batch", "Number of NZBs to convert per transaction").Default("100").IntVar(&r.BatchSize)
+
+	rgrpDedupe := rgrp.Command("dedupe", "Link releases made before duplicate detection to the release they duplicate").Action(r.dedupe)
+	rgrpDedupe.Flag("batch", "Number of releases to check at a time").Default("1000").IntVar(&r.BatchSize)
+
+	rgrpTVInfo := rgrp.Command("tvinfo", "Parse the show, season and episode of TV releases made before TV info was kept").Action(r.tvInfo)
+	rgrpTVInfo.Flag("batch", "Number of releases to parse per transaction").Default("1000").IntVar(&r.BatchSize)
+
+	rgrpMovieInfo := rgrp.Command("movieinfo", "Parse the title, year and quality of movie releases made before movie info was kept").Action(r.movieInfo)
+	rgrpMovieInfo.Flag("batch", "Number of releases to parse per transaction").Default("1000").IntVar(&r.BatchSize)
+
+	rgrpMusicInfo := rgrp.Command("musicinfo", "Parse the artist, album and format of music releases made before music info was kept").Action(r.musicInfo)
+	rgrpMusicInfo.Flag("batch", "Number of releases to parse per transaction").Default("1000").IntVar(&r.BatchSize)
+
+	rgrpBookInfo := rgrp.Command("bookinfo", "Parse the author, title and format of book and audiobook releases made before book info was kept").Action(r.bookInfo)
+	rgrpBookInfo.Flag("batch", "Number of releases to parse per transaction").Default("1000").IntVar(&r.BatchSize)
+
+	rgrpDupes := rgrp.Command("duplicates", "List the duplicates of a release").Action(r.duplicates)
+	rgrpDupes.Flag("id", "ID of the release").Required().Int64Var(&r.ReleaseID)
+
+	rgrpDelete := rgrp.Command("delete", "Delete a release and keep it from being made again").Action(r.delete)
+	rgrpDelete.Flag("id", "ID of release to delete").Required().Int64Var(&r.ReleaseID)
 }
 
 func (r *ReleasesCommand) run(c *kingpin.ParseContext) error {
@@ -46,8 +111,33 @@ func (r *ReleasesCommand) run(c *kingpin.ParseContext) error {
 	cfg := loadConfig(*configfile)
 
 	dbh := db.NewDBHandle(cfg.DB.Name, cfg.DB.Username, cfg.DB.Password, cfg.DB.Verbose)
-	err := dbh.MakeReleases()
-	return err
+	dbh.NZBDir = cfg.NZB.Dir
+	err := dbh.CheckCategories()
+	if err != nil {
+		return fmt.Errorf("%v.  Fix them with gonab categories sync.", err)
+	}
+	opts := db.ReleaseOptions{
+		MinCompletion:        cfg.Releases.MinCompletion,
+		PartialAfter:         time.Duration(cfg.Releases.PartialAfterHours) * time.Hour,
+		PartialMinCompletion: cfg.Releases.PartialMinCompletion,
+	}
+	stats, err := dbh.MakeReleasesWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created %d releases, skipped %d duplicates\n", stats.Created, stats.Duplicates)
+	for _, reason := range db.RejectReasons {
+		if count := stats.Rejected[reason]; count > 0 {
+			fmt.Printf("  Rejected %d binaries: %s\n", count, reason)
+		}
+	}
+	if stats.RequestIDsResolved > 0 {
+		fmt.Printf("Renamed %d earlier releases from their request ID\n", stats.RequestIDsResolved)
+	}
+	if stats.Reposts > 0 {
+		fmt.Printf("Linked %d reposts to earlier releases, %d of them completed the earlier release\n", stats.Reposts, stats.Merged)
+	}
+	return nil
 }
 
 func (r *ReleasesCommand) list(c *kingpin.ParseContext) error {
@@ -63,7 +153,11 @@ func (r *ReleasesCommand) list(c *kingpin.ParseContext) error {
 		cats = append(cats, types.CategoryFromInt(c))
 	}
 
-	releases, err := dbh.SearchReleases(r.SearchTerm, 0, r.Limit, cats)
+	search := dbh.SearchReleases
+	if r.Duplicates {
+		search = dbh.SearchAllReleases
+	}
+	releases, err := search(r.SearchTerm, 0, r.Limit, cats)
 	if err != nil {
 		return err
 	}
@@ -85,6 +179,7 @@ func (r *ReleasesCommand) exportNZB(c *kingpin.ParseContext) error {
 	}
 
 	dbh := db.NewDBHandle(cfg.DB.Name, cfg.DB.Username, cfg.DB.Password, cfg.DB.Verbose)
+	dbh.NZBDir = cfg.NZB.Dir
 
 	var rel types.Release
 	err := dbh.DB.First(&rel, r.ReleaseID).Error
@@ -98,12 +193,253 @@ func (r *ReleasesCommand) exportNZB(c *kingpin.ParseContext) error {
 	}
 	fullpath := path.Join(r.DirPath, filename)
 
+	doc, err := dbh.GetNZB(&rel)
+	if err != nil {
+		return err
+	}
+
 	fmt.Printf("Writing NZB for %s to %s\n", rel.Name, fullpath)
-	err = ioutil.WriteFile(fullpath, []byte(rel.NZB), 0644)
+	err = ioutil.WriteFile(fullpath, []byte(doc), 0644)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func parseDateFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("Invalid --%s date %q, use YYYY-MM-DD", name, value)
+	}
+	return t, nil
+}
+
+func (r *ReleasesCommand) recategorize(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
 
+	opts := db.RecategorizeOptions{
+		Group:     r.Group,
+		DryRun:    r.DryRun,
+		BatchSize: r.BatchSize,
+	}
+	for _, c := range r.Categories {
+		opts.Categories = append(opts.Categories, types.CategoryFromInt(c))
+	}
+	var err error
+	opts.From, err = parseDateFlag("from", r.From)
 	if err != nil {
 		return err
 	}
+	opts.To, err = parseDateFlag("to", r.To)
+	if err != nil {
+		return err
+	}
+
+	stats, err := dbh.RecategorizeReleases(opts)
+	if err != nil {
+		return fmt.Errorf("Error recategorizing releases: %v", err)
+	}
+	verb := "Changed"
+	if r.DryRun {
+		verb = "Would change"
+	}
+	fmt.Printf("Checked %d releases, %s %d, skipped %d with a locked category\n", stats.Checked, strings.ToLower(verb), stats.Changed, stats.Locked)
+	if stats.Changed > 0 {
+		fmt.Printf("\n%s (rows are the old category, columns the new one):\n", verb)
+		printCategoryMatrix(os.Stdout, stats.Changes)
+	}
+	return nil
+}
 
+// printCategoryMatrix prints how many releases moved between each pair of
+// categories, only including the categories involved.
+func printCategoryMatrix(out io.Writer, changes map[db.CategoryChange]int) {
+	fromSet := map[types.Category]bool{}
+	toSet := map[types.Category]bool{}
+	for ch := range changes {
+		fromSet[ch.From] = true
+		toSet[ch.To] = true
+	}
+	sortedCats := func(set map[types.Category]bool) []types.Category {
+		cats := make([]types.Category, 0, len(set))
+		for c := range set {
+			cats = append(cats, c)
+		}
+		sort.Slice(cats, func(i, j int) bool { return cats[i] < cats[j] })
+		return cats
+	}
+	froms, tos := sortedCats(fromSet), sortedCats(toSet)
+
+	w := new(tabwriter.Writer)
+	w.Init(out, 5, 0, 1, ' ', tabwriter.AlignRight)
+	header := []string{""}
+	for _, to := range tos {
+		header = append(header, fmt.Sprintf("%d", to))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t")+"\t")
+	for _, from := range froms {
+		row := []string{fmt.Sprintf("%d", from)}
+		for _, to := range tos {
+			row = append(row, fmt.Sprintf("%d", changes[db.CategoryChange{From: from, To: to}]))
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t")+"\t")
+	}
+	w.Flush()
+}
+
+func (r *ReleasesCommand) lockCategory(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	err := dbh.SetCategoryLocked(r.ReleaseID, !r.Unlock)
+	if err != nil {
+		return fmt.Errorf("Error locking category of release %d: %v", r.ReleaseID, err)
+	}
+	state := "Locked"
+	if r.Unlock {
+		state = "Unlocked"
+	}
+	fmt.Printf("%s category of release %d\n", state, r.ReleaseID)
+	return nil
+}
+
+func (r *ReleasesCommand) edit(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	edit := db.ReleaseEdit{}
+	if r.Name != "" {
+		edit.Name = &r.Name
+	}
+	if r.SearchName != "" {
+		edit.SearchName = &r.SearchName
+	}
+	if r.EditCategory != "" {
+		i, err := strconv.ParseInt(r.EditCategory, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid category %q: %v", r.EditCategory, err)
+		}
+		cat := types.Category(i)
+		edit.Category = &cat
+	}
+	if r.Hide && r.Unhide {
+		return fmt.Errorf("--hide and --unhide can't be used together")
+	}
+	if r.Hide || r.Unhide {
+		edit.Hidden = &r.Hide
+	}
+	if edit == (db.ReleaseEdit{}) {
+		return fmt.Errorf("Nothing to change, give at least one of --name, --search-name, --category, --hide or --unhide")
+	}
+
+	rel, err := dbh.EditRelease(r.ReleaseID, edit)
+	if err != nil {
+		return fmt.Errorf("Error editing release %d: %v", r.ReleaseID, err)
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 5, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Name\t%s\n", rel.Name)
+	fmt.Fprintf(w, "Search Name\t%s\n", rel.SearchName)
+	fmt.Fprintf(w, "Category\t%d (%s)\n", rel.CategoryName(), rel.CategoryName())
+	fmt.Fprintf(w, "Status\t%s\n", types.ReleaseStatusNames[rel.Status])
+	w.Flush()
+	return nil
+}
+
+func (r *ReleasesCommand) delete(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	err := dbh.DeleteRelease(r.ReleaseID)
+	if err != nil {
+		return fmt.Errorf("Error deleting release %d: %v", r.ReleaseID, err)
+	}
+	fmt.Printf("Deleted release %d\n", r.ReleaseID)
+	return nil
+}
+
+func (r *ReleasesCommand) convertNZBs(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	converted, err := dbh.ConvertNZBs(r.BatchSize)
+	if err != nil {
+		return fmt.Errorf("Error converting NZBs: %v", err)
+	}
+	fmt.Printf("Converted %d NZBs\n", converted)
+	return nil
+}
+
+func (r *ReleasesCommand) dedupe(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	stats, err := dbh.DedupeReleases(r.BatchSize)
+	if err != nil {
+		return fmt.Errorf("Error deduping releases: %v", err)
+	}
+	fmt.Printf("Checked %d releases, found %d duplicates, %d of them completed the earlier release\n", stats.Checked, stats.Duplicates, stats.Merged)
+	return nil
+}
+
+func (r *ReleasesCommand) tvInfo(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	checked, parsed, err := dbh.BackfillTVInfo(r.BatchSize)
+	if err != nil {
+		return fmt.Errorf("Error parsing TV info: %v", err)
+	}
+	fmt.Printf("Parsed TV info of %d of %d releases\n", parsed, checked)
+	return nil
+}
+
+func (r *ReleasesCommand) movieInfo(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	checked, parsed, err := dbh.BackfillMovieInfo(r.BatchSize)
+	if err != nil {
+		return fmt.Errorf("Error parsing movie info: %v", err)
+	}
+	fmt.Printf("Parsed movie info of %d of %d releases\n", parsed, checked)
+	return nil
+}
+
+func (r *ReleasesCommand) musicInfo(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	checked, parsed, err := dbh.BackfillMusicInfo(r.BatchSize)
+	if err != nil {
+		return fmt.Errorf("Error parsing music info: %v", err)
+	}
+	fmt.Printf("Parsed music info of %d of %d releases\n", parsed, checked)
+	return nil
+}
+
+func (r *ReleasesCommand) bookInfo(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	checked, parsed, err := dbh.BackfillBookInfo(r.BatchSize)
+	if err != nil {
+		return fmt.Errorf("Error parsing book info: %v", err)
+	}
+	fmt.Printf("Parsed book info of %d of %d releases\n", parsed, checked)
+	return nil
+}
+
+func (r *ReleasesCommand) duplicates(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	releases, err := dbh.GetDuplicateReleases(r.ReleaseID)
+	if err != nil {
+		return fmt.Errorf("Error finding duplicates of release %d: %v", r.ReleaseID, err)
+	}
+	fmt.Printf("Found %d duplicates of release %d\n", len(releases), r.ReleaseID)
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 5, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tDate\tGroup\tCompletion")
+	for _, rel := range releases {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%.2f%%\n", rel.ID, rel.Name, rel.Posted, rel.Group.Name, rel.Completion)
+	}
+	w.Flush()
 	return nil
 }
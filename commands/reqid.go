@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// RequestIDCommand manages the local table of request IDs.
+type RequestIDCommand struct {
+	File      string
+	Format    string
+	BatchSize int
+}
+
+func (r *RequestIDCommand) configure(app *kingpin.Application) {
+	rgrp := app.Command("reqid", "Manage the request IDs of request groups")
+	imp := rgrp.Command("import", "Import request IDs from a CSV or JSON dump and rename the releases they resolve").Action(r.importRequestIDs)
+	imp.Flag("format", "Format of the dump, auto guesses from the file extension").Default("auto").EnumVar(&r.Format, "auto", "csv", "json")
+	imp.Flag("batch", "Number of request IDs to save per transaction").Default("1000").IntVar(&r.BatchSize)
+	imp.Arg("file", "Dump to import").Required().ExistingFileVar(&r.File)
+}
+
+func (r *RequestIDCommand) importRequestIDs(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	records, err := readDumpFile(r.File, r.Format)
+	if err != nil {
+		return err
+	}
+	reqs := requestIDsFromRecords(records)
+	logrus.Infof("Parsed %d request IDs from %s", len(reqs), r.File)
+
+	batch := r.BatchSize
+	if batch < 1 {
+		batch = 1000
+	}
+	added, updated := 0, 0
+	for i := 0; i < len(reqs); i += batch {
+		end := i + batch
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+		a, u, err := dbh.SaveRequestIDs(reqs[i:end])
+		if err != nil {
+			return err
+		}
+		added += a
+		updated += u
+	}
+	total, err := dbh.CountRequestIDs()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %d request IDs and updated %d, %d request IDs in total\n", added, updated, total)
+
+	resolved, err := dbh.ResolveRequestIDs(batch)
+	if err != nil {
+		return fmt.Errorf("Error resolving request IDs: %v", err)
+	}
+	fmt.Printf("Renamed %d releases from their request ID\n", resolved)
+	return nil
+}
+
+// requestIDsFromRecords converts the records of a dump with reqid, group and
+// title fields into requests, skipping incomplete ones.
+func requestIDsFromRecords(records []dumpRecord) []*types.RequestID {
+	reqs := []*types.RequestID{}
+	for i, rec := range records {
+		reqid, err := strconv.ParseInt(rec.get("reqid", "requestid", "request_id"), 10, 64)
+		if err != nil || reqid <= 0 {
+			logrus.Errorf("Skipping record %d: invalid request ID", i+1)
+			continue
+		}
+		req := &types.RequestID{
+			RequestID: reqid,
+			GroupName: expandGroupName(rec.get("group", "groupname", "group_name")),
+			Title:     rec.get("title", "name", "release"),
+		}
+		if req.GroupName == "" || req.Title == "" {
+			logrus.Errorf("Skipping record %d: no group or title", i+1)
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+// expandGroupName turns the short group names used by request bots like
+// a.b.teevee into full names.
+func expandGroupName(name string) string {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if strings.HasPrefix(name, "a.b.") {
+		return "alt.binaries." + strings.TrimPrefix(name, "a.b.")
+	}
+	return name
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestRequestIDsFromRecords(t *testing.T) {
+	RegisterTestingT(t)
+	f, err := os.Open("testdata/reqid.csv")
+	if err != nil {
+		t.Fatalf("Error opening testdata: %v", err)
+	}
+	defer f.Close()
+	records, err := readCSVDump(f)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	// Records with a broken ID or without a title are skipped.
+	reqs := requestIDsFromRecords(records)
+	Expect(reqs).To(HaveLen(2))
+	Expect(reqs[0]).To(Equal(&types.RequestID{
+		RequestID: 169018,
+		GroupName: "alt.binaries.teevee",
+		Title:     "House.of.Lies.S01E01.720p.WEB-DL.DD5.1.H.264-BS",
+	}))
+	Expect(reqs[1].GroupName).To(Equal("alt.binaries.teevee"))
+}
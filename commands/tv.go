@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// TVCommand manages the local TV show database.
+type TVCommand struct {
+	File      string
+	Format    string
+	BatchSize int
+}
+
+func (t *TVCommand) configure(app *kingpin.Application) {
+	tgrp := app.Command("tv", "Manage the local TV show database")
+	imp := tgrp.Command("import", "Import shows from a CSV or JSON dump and match TV releases to them").Action(t.importShows)
+	imp.Flag("format", "Format of the dump, auto guesses from the file extension").Default("auto").EnumVar(&t.Format, "auto", "csv", "json")
+	imp.Flag("batch", "Number of shows to save per transaction").Default("1000").IntVar(&t.BatchSize)
+	imp.Arg("file", "Dump to import").Required().ExistingFileVar(&t.File)
+}
+
+func (t *TVCommand) importShows(c *kingpin.ParseContext) error {
+	_, dbh := commonInit()
+
+	records, err := readDumpFile(t.File, t.Format)
+	if err != nil {
+		return err
+	}
+	shows := showsFromRecords(records)
+	logrus.Infof("Parsed %d shows from %s", len(shows), t.File)
+
+	imp := dumpImport{
+		Item:     "show",
+		Releases: "TV",
+		Save: func(from, to int) (int, int, error) {
+			return dbh.SaveTVShows(shows[from:to])
+		},
+		Count: dbh.CountTVShows,
+		Match: dbh.MatchTVShows,
+	}
+	return imp.run(len(shows), t.BatchSize)
+}
+
+// showsFromRecords converts the records of a dump into shows, skipping the
+// ones that can't be parsed.  See tvShowFieldNames for the field names
+// understood.
+func showsFromRecords(records []dumpRecord) []*types.TVShow {
+	shows := []*types.TVShow{}
+	for i, rec := range records {
+		show, err := tvShowFromRecord(rec)
+		if err != nil {
+			logrus.Errorf("Skipping record %d: %v", i+1, err)
+			continue
+		}
+		shows = append(shows, show)
+	}
+	return shows
+}
+
+// Alternative names used by TVMaze, TVDB and TVRage dumps.  A bare id is
+// only a TVMaze ID in records with TVMaze style externals, see tvMazeID.
+var tvShowFieldNames = map[string][]string{
+	"tvmaze":  {"tvmaze_id", "tvmazeid", "tvmaze"},
+	"tvdb":    {"tvdb_id", "tvdbid", "thetvdb", "externals.thetvdb", "seriesid"},
+	"tvrage":  {"tvrage_id", "tvrageid", "tvrage", "rid", "externals.tvrage", "showid"},
+	"title":   {"title", "name", "seriesname", "showname"},
+	"country": {"country", "network.country.code", "webchannel.country.code", "origin_country"},
+	"year":    {"year", "premiered", "firstaired", "started"},
+	"aliases": {"aliases", "akas", "aka"},
+}
+
+func getTVShowField(rec dumpRecord, name string) string {
+	if name == "tvmaze" {
+		return tvMazeID(rec)
+	}
+	return rec.get(tvShowFieldNames[name]...)
+}
+
+// tvMazeID returns the TVMaze ID of a record.  Other dumps use id for their
+// own IDs, so it is only used if the record has the externals.* fields of
+// the TVMaze API.
+func tvMazeID(rec dumpRecord) string {
+	if v := rec.get(tvShowFieldNames["tvmaze"]...); v != "" {
+		return v
+	}
+	for k := range rec {
+		if strings.HasPrefix(k, "externals.") {
+			return rec.get("id")
+		}
+	}
+	return ""
+}
+
+// tvShowFromRecord converts a single record into a TVShow.  The title and one
+// of the IDs are required.
+func tvShowFromRecord(rec dumpRecord) (*types.TVShow, error) {
+	show := &types.TVShow{
+		Title:   getTVShowField(rec, "title"),
+		Country: strings.ToUpper(getTVShowField(rec, "country")),
+	}
+	if show.Title == "" {
+		return nil, fmt.Errorf("no title")
+	}
+	ids := []struct {
+		field string
+		id    *int64
+	}{
+		{"tvmaze", &show.TVMazeID},
+		{"tvdb", &show.TVDBID},
+		{"tvrage", &show.TVRageID},
+	}
+	for _, i := range ids {
+		v := getTVShowField(rec, i.field)
+		if v == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s id %q", i.field, v)
+		}
+		*i.id = id
+	}
+	if show.TVMazeID == 0 && show.TVDBID == 0 && show.TVRageID == 0 {
+		return nil, fmt.Errorf("no TVMaze, TVDB or TVRage id for %s", show.Title)
+	}
+	// Dates like 2013-09-16 give the year.
+	if v := getTVShowField(rec, "year"); len(v) >= 4 {
+		year, err := strconv.Atoi(v[:4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid year %q", v)
+		}
+		show.Year = year
+	}
+	for _, a := range strings.Split(getTVShowField(rec, "aliases"), "|") {
+		if a = strings.TrimSpace(a); a != "" {
+			show.Aliases = append(show.Aliases, a)
+		}
+	}
+	return show, nil
+}
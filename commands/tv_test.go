@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseTVShowJSON(t *testing.T) {
+	RegisterTestingT(t)
+	f, err := os.Open("testdata/tvshows.json")
+	if err != nil {
+		t.Fatalf("Error opening testdata: %v", err)
+	}
+	defer f.Close()
+	records, err := readJSONDump(f)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	shows := showsFromRecords(records)
+	// Shows without any ID are skipped.
+	Expect(shows).To(HaveLen(2))
+	Expect(shows[0]).To(Equal(&types.TVShow{
+		TVMazeID: 1,
+		TVDBID:   264492,
+		TVRageID: 25988,
+		Title:    "Under the Dome",
+		Country:  "US",
+		Year:     2013,
+		Aliases:  []string{"Под куполом"},
+	}))
+	Expect(shows[1].Title).To(Equal("The Flash"))
+	Expect(shows[1].Aliases).To(BeEmpty())
+}
+
+func TestParseTVShowCSV(t *testing.T) {
+	RegisterTestingT(t)
+	f, err := os.Open("testdata/tvshows.csv")
+	if err != nil {
+		t.Fatalf("Error opening testdata: %v", err)
+	}
+	defer f.Close()
+	records, err := readCSVDump(f)
+	if err != nil {
+		t.Fatalf("Error parsing: %v", err)
+	}
+	shows := showsFromRecords(records)
+	// Lines without a title or with a broken ID are skipped.
+	Expect(shows).To(HaveLen(1))
+	Expect(shows[0]).To(Equal(&types.TVShow{
+		TVDBID:   263365,
+		TVRageID: 32656,
+		Title:    "Marvel's Agents of S.H.I.E.L.D.",
+		Country:  "US",
+		Year:     2013,
+		Aliases:  []string{"Agents of SHIELD", "Marvels Agents of SHIELD"},
+	}))
+}
+
+func TestTVShowBareID(t *testing.T) {
+	RegisterTestingT(t)
+	show, err := tvShowFromRecord(dumpRecord{"id": "81189", "seriesname": "Breaking Bad", "seriesid": "81189"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(show.TVMazeID).To(BeZero())
+	Expect(show.TVDBID).To(Equal(int64(81189)))
+
+	show, err = tvShowFromRecord(dumpRecord{"id": "169", "name": "Breaking Bad", "externals.thetvdb": "81189"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(show.TVMazeID).To(Equal(int64(169)))
+
+	_, err = tvShowFromRecord(dumpRecord{"id": "169", "name": "Breaking Bad"})
+	Expect(err).To(HaveOccurred())
+}
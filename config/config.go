@@ -18,6 +18,10 @@ type Config struct {
 	NewsServer newsServer
 	DB         dbConfig
 	Regex      regexSource
+	Purge      purgeConfig
+	Releases   releasesConfig
+	API        apiConfig
+	NZB        nzbConfig
 }
 
 type newsServer struct {
@@ -27,6 +31,9 @@ type newsServer struct {
 	Password string
 	UseTLS   bool
 	MaxConns int
+	// Days of articles the provider keeps.  Releases older than this can't be
+	// downloaded anymore.  0 disables purging releases.
+	RetentionDays int
 }
 type dbConfig struct {
 	Name     string
@@ -35,6 +42,29 @@ type dbConfig struct {
 	Verbose  bool // turn on verbose db logging
 }
 
+type purgeConfig struct {
+	PartAgeHours int // Purge parts and binaries that are still incomplete after this long.
+	BatchSize    int // Rows to delete per transaction.
+}
+
+type releasesConfig struct {
+	MinCompletion float64 // Percentage of segments needed to make a release.
+	// Binaries older than PartialAfterHours are released once they are
+	// PartialMinCompletion percent complete.  0 disables this.
+	PartialAfterHours    int
+	PartialMinCompletion float64
+}
+
+type apiConfig struct {
+	// Key needed to use the admin endpoints.  Empty disables them.
+	AdminKey string
+}
+
+type nzbConfig struct {
+	// Store compressed NZBs in this directory instead of the database.
+	Dir string
+}
+
 type regexSource struct {
 	Type          string // nnplus or nzedb
 	URL           string
@@ -53,6 +83,14 @@ func NewConfig() *Config {
 			Name:    "gonab",
 			Verbose: false,
 		},
+		Purge: purgeConfig{
+			PartAgeHours: 72,
+			BatchSize:    1000,
+		},
+		Releases: releasesConfig{
+			MinCompletion:        100,
+			PartialMinCompletion: 95,
+		},
 	}
 }
 
@@ -2,6 +2,7 @@ package db
 
 import (
 	"crypto/sha1"
+	"database/sql"
 	"fmt"
 	"regexp"
 	"sort"
@@ -52,8 +53,21 @@ func NewNameCleaner(regexes []*types.Regex) *NameCleaner {
 	return &NameCleaner{Regexes: regexes}
 }
 
+// SubjectCleanerRegexID is recorded as the regex id of binaries whose names
+// came from the generic subject cleaner rather than a collection regex.
+const SubjectCleanerRegexID = 0
+
 // Clean will return a rewritten name.
 func (n *NameCleaner) Clean(name, groupname string) string {
+	cleaned, _ := n.Match(name, "", groupname)
+	return cleaned
+}
+
+// Match will return a rewritten name and the ID of the regex that produced
+// it.  If no regex matched the name the generic subject cleaner is used and
+// SubjectCleanerRegexID is returned.  The poster is only used when the
+// subject itself doesn't contain anything to name the binary after.
+func (n *NameCleaner) Match(name, poster, groupname string) (string, int) {
 	for _, r := range n.Regexes {
 		if !r.CompiledGroupRegex.Regex.MatchString(groupname) {
 			continue
@@ -62,41 +76,154 @@ func (n *NameCleaner) Clean(name, groupname string) string {
 		if len(m) == 0 {
 			continue
 		}
-		// Sort keynames and then concatinate values in that order
-		var keys = make([]string, len(m))
-		i := 0
-		for k := range m {
-			keys[i] = k
-			i++
-		}
-		sort.Strings(keys)
-		var parts = make([]string, len(m))
-		for i, k := range keys {
-			parts[i] = m[k]
-		}
-		return strings.Join(parts, "")
+		return joinNamedMatches(m), r.ID
+	}
+	return n.subjectCleaner(name, poster, groupname), SubjectCleanerRegexID
+}
+
+// Explain returns a trace of every collection regex that applies to
+// groupname.  If none of them matched the generic subject cleaner would be
+// used.
+func (n *NameCleaner) Explain(name, groupname string) []RegexTrace {
+	return explainRegexes(n.Regexes, name, groupname)
+}
+
+// joinNamedMatches sorts the capture names and then concatinates their values
+// in that order.
+func joinNamedMatches(m map[string]string) string {
+	var keys = make([]string, len(m))
+	i := 0
+	for k := range m {
+		keys[i] = k
+		i++
+	}
+	sort.Strings(keys)
+	var parts = make([]string, len(m))
+	for i, k := range keys {
+		parts[i] = m[k]
 	}
-	return n.subjectCleaner(name, groupname)
+	return strings.Join(parts, "")
 }
 
-func (n *NameCleaner) subjectCleaner(subject, groupname string) string {
+func (n *NameCleaner) subjectCleaner(subject, poster, groupname string) string {
 	// try collections regex
 	if !musicGroupRegex.MatchString(groupname) {
-		subject = genericSubjectCleaner1.ReplaceAllString(subject, " ")
-		subject = genericSubjectCleaner2.ReplaceAllString(subject, " ")
-		subject = genericSubjectCleaner3.ReplaceAllString(subject, " ")
-		subject = genericSubjectCleaner4.ReplaceAllString(subject, " ")
-		subject = genericSubjectCleaner5.ReplaceAllString(subject, " ")
-		subject = genericSubjectCleaner6.ReplaceAllString(subject, " ")
-		return strings.TrimSpace(subject)
+		return genericSubjectCleaner(subject)
+	}
+	return musicSubjectCleaner(subject, poster)
+}
+
+func genericSubjectCleaner(subject string) string {
+	subject = genericSubjectCleaner1.ReplaceAllString(subject, " ")
+	subject = genericSubjectCleaner2.ReplaceAllString(subject, " ")
+	subject = genericSubjectCleaner3.ReplaceAllString(subject, " ")
+	subject = genericSubjectCleaner4.ReplaceAllString(subject, " ")
+	subject = genericSubjectCleaner5.ReplaceAllString(subject, " ")
+	subject = genericSubjectCleaner6.ReplaceAllString(subject, " ")
+	return strings.TrimSpace(subject)
+}
+
+var (
+	// [01/15], (01/15), (1 of 15) and 1 of 15
+	musicPartCountRegex = regexp.MustCompile(`(?i)[\[(]\d{1,5}\s?(\/|of)\s?\d{1,5}[\])]|\d{1,5} of \d{1,5}`)
+	// The quoted file name.  Every file in an album post has a different one.
+	musicFileNameRegex = regexp.MustCompile(`"([^"]*)"`)
+	// Audio files and the usual files posted alongside them.
+	musicFileExtRegex = regexp.MustCompile(`(?i)(\.part\d+|\.vol\d+\+\d+)?\.(7z|aac|alac|ape|cue|flac|jpe?g|log|m3u8?|m4a|mp3|nfo|nzb|ogg|par2|png|r\d\d|rar|sfv|txt|wav|wma|zip)$`)
+	// Artist - Album - 05 - Title
+	musicSpacedTrackRegex = regexp.MustCompile(`^(?P<album>.+?) - \(?\d{1,3}\)? - `)
+	// artist-album-05-title
+	musicCompactTrackRegex = regexp.MustCompile(`^(?P<album>.+?)[-_.]\d{2,3}[-_.]`)
+	// 05 - Artist - Title, 05. Title and 05-artist-title
+	musicLeadingTrackRegex = regexp.MustCompile(`^\(?\d{1,3}\)?(\s?-\s?|\.\s?|_|\s)(?P<rest>.+)$`)
+	musicYencRegex         = regexp.MustCompile(`(?i)\byEnc\b`)
+	musicLettersRegex      = regexp.MustCompile(`[[:alpha:]]`)
+)
+
+// musicSubjectCleaner reduces a subject from a music group to the name of the
+// album it belongs to, so every track of the album posted by the same poster
+// ends up in the same binary.  Part counts, track numbers, track titles and
+// file extensions are removed.
+//
+// If neither the subject nor the file name contain the album the tracks are
+// named after the poster and the number of files in the post, or failing
+// that the generic subject cleaner is used.
+func musicSubjectCleaner(subject, poster string) string {
+	_, totalFiles := GetPartsFromSubject(subject)
+	stripped := musicPartCountRegex.ReplaceAllString(subject, " ")
+
+	prefix := ""
+	filename := stripped
+	if loc := musicFileNameRegex.FindStringSubmatchIndex(stripped); loc != nil {
+		prefix = stripped[:loc[0]]
+		filename = stripped[loc[2]:loc[3]]
+	} else {
+		filename = cleanMusicFragment(filename)
+	}
+
+	// Most posters put the album name in front of the file name.
+	prefix = cleanMusicFragment(prefix)
+	if musicLettersRegex.MatchString(prefix) {
+		return prefix
+	}
+	if album, ok := albumFromTrackFile(filename); ok {
+		return album
+	}
+	if totalFiles > 0 {
+		return strings.TrimSpace(fmt.Sprintf("%s (%d files)", poster, totalFiles))
+	}
+	return genericSubjectCleaner(subject)
+}
+
+// albumFromTrackFile guesses the album name from a track's file name.  It
+// returns false if the file name doesn't contain one, as in "01 - Title.mp3"
+// or "01-artist-title.mp3".
+func albumFromTrackFile(filename string) (string, bool) {
+	for {
+		stripped := musicFileExtRegex.ReplaceAllString(filename, "")
+		if stripped == filename {
+			break
+		}
+		filename = stripped
 	}
-	//TODO: handle music subject cleaning
-	return subject
+	filename = strings.TrimSpace(filename)
+
+	album := ""
+	if m := musicSpacedTrackRegex.FindStringSubmatch(filename); m != nil {
+		album = m[1]
+	} else if m := musicLeadingTrackRegex.FindStringSubmatch(filename); m != nil {
+		// Only "Artist - Album - Title" or "artist-album-title" name the
+		// album, two fields are just the artist and the track title.
+		rest := m[2]
+		if fields := strings.Split(rest, " - "); len(fields) > 2 {
+			album = strings.Join(fields[:len(fields)-1], " - ")
+		} else if fields := strings.Split(rest, "-"); len(fields) > 2 {
+			album = strings.Join(fields[:len(fields)-1], "-")
+		}
+	} else if m := musicCompactTrackRegex.FindStringSubmatch(filename); m != nil {
+		album = m[1]
+	} else {
+		// Things like "Artist - Album.nfo" or "Artist - Album.par2".
+		album = filename
+	}
+	album = cleanMusicFragment(album)
+	return album, musicLettersRegex.MatchString(album)
+}
+
+// cleanMusicFragment strips yEnc markers, file sizes and separators left over
+// from removing other parts of a subject.
+func cleanMusicFragment(s string) string {
+	s = musicYencRegex.ReplaceAllString(s, " ")
+	s = genericSubjectCleaner4.ReplaceAllString(s, " ")
+	s = genericSubjectCleaner6.ReplaceAllString(s, " ")
+	return strings.Trim(s, " -_.")
 }
 
 var partPartsRegex = regexp.MustCompile(`(?i)[[(\s](\d{1,5})(\/|[\s_]of[\s_]|-)(\d{1,5})[])\s$:]`)
 
-func getPartsFromSubject(subject string) (int, int) {
+// GetPartsFromSubject returns the part number and total number of parts
+// found in a subject, or zeros if it doesn't contain them.
+func GetPartsFromSubject(subject string) (int, int) {
 	part, totalparts := 0, 0
 	partmatches := partPartsRegex.FindStringSubmatch(subject)
 	if len(partmatches) > 0 {
@@ -106,71 +233,152 @@ func getPartsFromSubject(subject string) (int, int) {
 	return part, totalparts
 }
 
-// MakeBinaries makes binaries using the nzedb approach
+const (
+	// DefaultPartBatchSize is the number of parts MakeBinaries reads and
+	// commits at a time.
+	DefaultPartBatchSize = 10000
+	// DefaultBinaryCacheSize is the maximum number of binary hashes kept in
+	// memory between batches before the cache is flushed.
+	DefaultBinaryCacheSize = 100000
+)
+
+// MakeBinaries makes binaries using the nzedb approach with the default batch
+// and cache sizes.
 func (d *Handle) MakeBinaries() error {
-	var regex []*types.Regex
-	err := d.DB.Table("collection_regex").Find(&regex).Error
+	return d.MakeBinariesBatched(DefaultPartBatchSize, DefaultBinaryCacheSize)
+}
+
+// MakeBinariesBatched makes binaries from all parts without one.  Parts are
+// read batchSize at a time ordered by group and each batch is committed on
+// it's own so memory use stays bounded no matter how many parts are waiting.
+// cacheSize limits how many binary hashes are remembered between batches,
+// anything not in the cache is looked up in the database.
+func (d *Handle) MakeBinariesBatched(batchSize, cacheSize int) error {
+	if batchSize < 1 {
+		batchSize = DefaultPartBatchSize
+	}
+	if cacheSize < 1 {
+		cacheSize = DefaultBinaryCacheSize
+	}
+	regex, err := d.GetCollectionRegexes()
 	if err != nil {
 		return err
 	}
 	cleaner := NewNameCleaner(regex)
 
-	var parts []types.Part
 	var partCount int64
-	err = d.DB.Where("binary_id is NULL").Find(&parts).Count(&partCount).Error
+	err = d.DB.Model(&types.Part{}).Where("binary_id is NULL").Count(&partCount).Error
 	if err != nil {
 		return err
 	}
 	logrus.Infof("Found %d parts to process.", partCount)
 
-	binaries := map[string]*types.Binary{}
+	// binary hash -> binary id for binaries saved by earlier batches.  Hashes
+	// include the group name so the cache is reset whenever the group changes.
+	cache := map[string]int64{}
+	lastGroup := ""
+	var lastID int64
+	processed, newBinaries := 0, 0
 	t := time.Now()
-	for _, p := range parts {
-		cleanedSubject := cleaner.Clean(p.Subject, p.GroupName)
-		_, totalparts := getPartsFromSubject(p.Subject)
+	for {
+		var parts []types.Part
+		err = d.DB.Select("id, subject, posted, `from`, group_name").
+			Where("binary_id is NULL AND (group_name > ? OR (group_name = ? AND id > ?))", lastGroup, lastGroup, lastID).
+			Order("group_name, id").Limit(batchSize).Find(&parts).Error
+		if err != nil {
+			return err
+		}
+		if len(parts) == 0 {
+			break
+		}
+		last := parts[len(parts)-1]
+		if last.GroupName != lastGroup || len(cache) > cacheSize {
+			cache = map[string]int64{}
+		}
+		lastGroup, lastID = last.GroupName, last.ID
 
-		binhash := makeHash(cleanedSubject, p.GroupName, p.From, strconv.Itoa(totalparts))
-		if bin, ok := binaries[binhash]; ok {
-			bin.Parts = append(bin.Parts, p)
-		} else {
-			b, err := d.FindBinaryByHash(binhash)
-			if err != nil {
-				logrus.Debugf("New binary found: %s", cleanedSubject)
-				binaries[binhash] = &types.Binary{
-					Hash:       binhash,
-					Name:       p.Subject,
-					Posted:     p.Posted,
-					From:       p.From,
-					Parts:      []types.Part{p},
-					GroupName:  p.GroupName,
-					TotalParts: totalparts,
-				}
-			} else {
-				b.Parts = append(b.Parts, p)
-				binaries[binhash] = b
+		binaries, hits, err := d.binariesFromParts(parts, cleaner, cache)
+		if err != nil {
+			return err
+		}
+
+		tx := d.DB.Begin()
+		for hash, b := range binaries {
+			if tx.NewRecord(b) {
+				newBinaries++
+			}
+			txerr := saveBinary(tx, b)
+			if txerr != nil {
+				tx.Rollback()
+				return txerr
 			}
+			cache[hash] = b.ID
 		}
-	}
-	logrus.Infof("Found %d new binaries", len(binaries))
-	dbt := time.Now()
-	tx := d.DB.Begin()
-	for _, b := range binaries {
-		txerr := saveBinary(tx, b)
+		txerr := saveRegexHits(tx, CollectionRegexKind, hits, time.Now())
 		if txerr != nil {
 			tx.Rollback()
 			return txerr
 		}
+		err = tx.Commit().Error
+		if err != nil {
+			return err
+		}
+
+		processed += len(parts)
+		pct := 100.0
+		if partCount > 0 {
+			pct = float64(processed) / float64(partCount) * 100
+		}
+		logrus.Infof("Processed %d/%d parts (%.1f%%) in group %s, %d new binaries so far, %s elapsed",
+			processed, partCount, pct, lastGroup, newBinaries, time.Since(t))
 	}
-	tx.Commit()
-	logrus.Infof("Saved %d binaries to db in %s", len(binaries), time.Since(dbt))
-	logrus.Infof("Processed %d binaries from %d parts in %s", len(binaries), len(parts), time.Since(t))
+	logrus.Infof("Processed %d new binaries from %d parts in %s", newBinaries, processed, time.Since(t))
 	return nil
+}
 
-	// Get name cleaner and load regexes
-	// Find all parts with no binary
-	// Gen hash of that part and see if a Binary already exists
-	// If so, add part
-	// If not create Binary and add part
+// binariesFromParts groups a batch of parts into binaries keyed by hash.
+// Binaries already in the cache or the database are returned with their ID
+// set so only the new parts get attached to them.  It also returns how many
+// parts each collection regex matched.
+func (d *Handle) binariesFromParts(parts []types.Part, cleaner *NameCleaner, cache map[string]int64) (map[string]*types.Binary, map[int]int64, error) {
+	binaries := map[string]*types.Binary{}
+	hits := map[int]int64{}
+	for _, p := range parts {
+		cleanedSubject, regexID := cleaner.Match(p.Subject, p.From, p.GroupName)
+		hits[regexID]++
+		_, totalparts := GetPartsFromSubject(p.Subject)
+
+		binhash := makeHash(cleanedSubject, p.GroupName, p.From, strconv.Itoa(totalparts))
+		if bin, ok := binaries[binhash]; ok {
+			bin.Parts = append(bin.Parts, p)
+			continue
+		}
+		if id, ok := cache[binhash]; ok {
+			binaries[binhash] = &types.Binary{ID: id, Hash: binhash, Parts: []types.Part{p}}
+			continue
+		}
+		b, err := d.FindBinaryByHash(binhash)
+		if err != nil && err != gorm.RecordNotFound {
+			return nil, nil, err
+		}
+		if err == nil {
+			b.Parts = []types.Part{p}
+			binaries[binhash] = b
+			continue
+		}
+		logrus.Debugf("New binary found: %s", cleanedSubject)
+		binaries[binhash] = &types.Binary{
+			Hash:       binhash,
+			Name:       p.Subject,
+			Posted:     p.Posted,
+			From:       p.From,
+			Parts:      []types.Part{p},
+			GroupName:  p.GroupName,
+			TotalParts: totalparts,
+			RegexID:    sql.NullInt64{Int64: int64(regexID), Valid: true},
+		}
+	}
+	return binaries, hits, nil
 }
 
 // SaveBinary will efficently save a binary and it's associated parts.
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// parseBookInfo returns the book info in the name of a release, or nil if
+// the name can't be parsed.
+func parseBookInfo(releaseID int64, name string) *types.BookInfo {
+	res, err := processing.ParseBookName(name)
+	if err != nil {
+		return nil
+	}
+	return &types.BookInfo{
+		ReleaseID: releaseID,
+		Author:    res.Author,
+		AuthorKey: processing.NameKey(res.Author),
+		Title:     res.Title,
+		TitleKey:  processing.NameKey(res.Title),
+		Year:      res.Year,
+		Format:    res.Format,
+	}
+}
+
+// saveBookInfo replaces the book info of a release with what can be parsed
+// from its name.  Only book releases and audiobooks get any.  Returns
+// whether book info was saved.
+func saveBookInfo(tx *gorm.DB, releaseID int64, name string, cat types.Category) (bool, error) {
+	err := tx.Where("release_id = ?", releaseID).Delete(types.BookInfo{}).Error
+	if err != nil || (cat.Parent() != types.Books && cat != types.Audio_Audiobook) {
+		return false, err
+	}
+	info := parseBookInfo(releaseID, name)
+	if info == nil {
+		return false, nil
+	}
+	return true, tx.Save(info).Error
+}
+
+// BackfillBookInfo parses the names of book and audiobook releases made
+// without book info, batch releases at a time.  Returns the number of
+// releases checked and the number that got book info.
+func (d *Handle) BackfillBookInfo(batch int) (int, int, error) {
+	checked, parsed := 0, 0
+	for _, cat := range []types.Category{types.Books, types.Audio_Audiobook} {
+		c, p, err := d.backfillReleaseInfo(cat, "book_info", batch, saveBookInfo)
+		checked += c
+		parsed += p
+		if err != nil {
+			return checked, parsed, err
+		}
+	}
+	return checked, parsed, nil
+}
+
+// GetBookInfo returns the book info of a release.
+func (d *Handle) GetBookInfo(releaseID int64) (*types.BookInfo, error) {
+	info := &types.BookInfo{}
+	err := d.DB.Where("release_id = ?", releaseID).First(info).Error
+	return info, err
+}
+
+// BookSearch selects the releases SearchBookReleases returns.  Empty fields
+// don't filter.
+type BookSearch struct {
+	Query      string // Matched against the search name like SearchReleases.
+	Author     string
+	Title      string
+	Categories []types.Category
+	Offset     int
+	Limit      int
+}
+
+// SearchBookReleases returns the releases with book info matching s, newest
+// first.  Hidden releases, duplicates and inactive categories are left out.
+func (d *Handle) SearchBookReleases(s BookSearch) ([]types.Release, error) {
+	bookParts := []string{}
+	bookVals := []interface{}{}
+	if s.Author != "" {
+		bookParts = append(bookParts, "author_key = ?")
+		bookVals = append(bookVals, processing.NameKey(s.Author))
+	}
+	if s.Title != "" {
+		bookParts = append(bookParts, "title_key = ?")
+		bookVals = append(bookVals, processing.NameKey(s.Title))
+	}
+	return d.searchInfoReleases("book_info", bookParts, bookVals, s.Query, s.Categories, s.Offset, s.Limit)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestBackfillAndSearchBookInfo(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	releases := []*types.Release{
+		{Name: "Andy Weir - The Martian (2014) (retail) (epub)", SearchName: "Andy Weir - The Martian (2014) (retail) (epub)", Hash: "h1", CategoryID: sql.NullInt64{Int64: int64(types.Book_Ebook), Valid: true}},
+		{Name: "Andy Weir - The Martian (2014) [M4B]", SearchName: "Andy Weir - The Martian (2014) [M4B]", Hash: "h2", CategoryID: sql.NullInt64{Int64: int64(types.Audio_Audiobook), Valid: true}},
+		{Name: "Stephen.King-The.Stand.Unabridged-AUDIOBOOK", SearchName: "Stephen.King-The.Stand.Unabridged-AUDIOBOOK", Hash: "h3", CategoryID: sql.NullInt64{Int64: int64(types.Audio_Audiobook), Valid: true}},
+		{Name: "Adele - 25 (2015) [FLAC]", SearchName: "Adele - 25 (2015) [FLAC]", Hash: "h4", CategoryID: sql.NullInt64{Int64: int64(types.Audio_Lossless), Valid: true}},
+	}
+	for _, rel := range releases {
+		err := dbh.DB.Save(rel).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	// Music releases aren't checked.
+	checked, parsed, err := dbh.BackfillBookInfo(2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(checked).To(Equal(3))
+	Expect(parsed).To(Equal(3))
+
+	info, err := dbh.GetBookInfo(releases[2].ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.Author).To(Equal("Stephen King"))
+	Expect(info.Title).To(Equal("The Stand"))
+
+	info, err = dbh.GetBookInfo(releases[1].ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.Year).To(Equal(2014))
+	Expect(info.Format).To(Equal("M4B"))
+
+	_, err = dbh.GetBookInfo(releases[3].ID)
+	Expect(err).To(HaveOccurred())
+
+	found, err := dbh.SearchBookReleases(BookSearch{Author: "andy weir", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(2))
+
+	found, err = dbh.SearchBookReleases(BookSearch{Author: "Andy Weir", Title: "The Martian", Categories: []types.Category{types.Audio_Audiobook}, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[1].ID))
+
+	found, err = dbh.SearchBookReleases(BookSearch{Query: "stand", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+
+	// Moving a release out of books drops its book info.
+	music := types.Audio_MP3
+	_, err = dbh.EditRelease(releases[2].ID, ReleaseEdit{Category: &music})
+	Expect(err).ToNot(HaveOccurred())
+	_, err = dbh.GetBookInfo(releases[2].ID)
+	Expect(err).To(HaveOccurred())
+}
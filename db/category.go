@@ -1,16 +1,21 @@
 package db
 
 import (
+	"database/sql"
+	"fmt"
+	"regexp"
 	"sort"
+	"strings"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/hobeone/gonab/types"
 )
 
-//GetCategories returns a sorted list of parent categories with their cubcategories
-//populated.
+//GetCategories returns a sorted list of active parent categories with their
+//active subcategories populated.
 func (d *Handle) GetCategories() ([]*types.DBCategory, error) {
 	var cats []*types.DBCategory
-	err := d.DB.Preload("Parent").Find(&cats).Error
+	err := d.DB.Where("active = ?", true).Preload("Parent").Find(&cats).Error
 	if err != nil {
 		return cats, err
 	}
@@ -44,3 +49,275 @@ func (d *Handle) GetCategories() ([]*types.DBCategory, error) {
 	}
 	return sortedcats, nil
 }
+
+// GetCategoryMinSizes returns the minimum release size of each category.
+// Subcategories without a minimum of their own use their parent's.
+func (d *Handle) GetCategoryMinSizes() (map[types.Category]int64, error) {
+	var cats []types.DBCategory
+	err := d.DB.Find(&cats).Error
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[types.Category]int64, len(cats))
+	for _, c := range cats {
+		sizes[types.Category(c.ID)] = int64(c.MinSize)
+	}
+	for _, c := range cats {
+		if c.MinSize == 0 && c.ParentID.Valid {
+			sizes[types.Category(c.ID)] = sizes[types.Category(c.ParentID.Int64)]
+		}
+	}
+	return sizes, nil
+}
+
+// inactiveCategoryQuery selects releases outside inactive categories and
+// their subcategories.  It takes false twice.
+const inactiveCategoryQuery = "(category_id IS NULL OR category_id NOT IN (SELECT id FROM category WHERE active = ? OR parent_id IN (SELECT id FROM category WHERE active = ?)))"
+
+// GetAllCategories returns every category ordered by ID, including inactive
+// ones.
+func (d *Handle) GetAllCategories() ([]types.DBCategory, error) {
+	var cats []types.DBCategory
+	err := d.DB.Order("id").Find(&cats).Error
+	return cats, err
+}
+
+// SetCategoryActive enables or disables a category.  Releases in inactive
+// categories and their subcategories aren't shown in searches.
+func (d *Handle) SetCategoryActive(id int64, active bool) error {
+	var cat types.DBCategory
+	err := d.DB.First(&cat, id).Error
+	if err != nil {
+		return err
+	}
+	return d.DB.Model(types.DBCategory{}).Where("id = ?", id).UpdateColumn("active", active).Error
+}
+
+// CategoryEdit holds the settings EditCategory changes.  Nil fields are left
+// alone.
+type CategoryEdit struct {
+	Name           *string // Only custom categories can be renamed.
+	Description    *string
+	MinSize        *int
+	DisablePreview *bool
+}
+
+// EditCategory changes the settings of a category.
+func (d *Handle) EditCategory(id int64, edit CategoryEdit) (*types.DBCategory, error) {
+	cat := &types.DBCategory{}
+	err := d.DB.First(cat, id).Error
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if edit.Name != nil {
+		c := types.Category(id)
+		if !c.IsCustom() {
+			return nil, fmt.Errorf("only custom categories can be renamed")
+		}
+		if *edit.Name == "" {
+			return nil, fmt.Errorf("category name can't be empty")
+		}
+		updates["name"] = *edit.Name
+	}
+	if edit.Description != nil {
+		updates["description"] = *edit.Description
+	}
+	if edit.MinSize != nil {
+		if *edit.MinSize < 0 {
+			return nil, fmt.Errorf("minimum size can't be negative")
+		}
+		updates["min_size"] = *edit.MinSize
+	}
+	if edit.DisablePreview != nil {
+		updates["disable_preview"] = *edit.DisablePreview
+	}
+	if len(updates) == 0 {
+		return cat, nil
+	}
+	err = d.DB.Model(types.DBCategory{}).Where("id = ?", id).UpdateColumns(updates).Error
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := updates["name"]; ok {
+		err = registerCustomCategory(types.Category(id), name.(string))
+		if err != nil {
+			return nil, err
+		}
+	}
+	cat = &types.DBCategory{}
+	err = d.DB.First(cat, id).Error
+	return cat, err
+}
+
+// AddCustomCategory adds a site specific subcategory of parent with the
+// lowest free ID in its range.
+func (d *Handle) AddCustomCategory(parent types.Category, name string) (*types.DBCategory, error) {
+	if name == "" {
+		return nil, fmt.Errorf("category name can't be empty")
+	}
+	min, max, err := types.CustomCategoryRange(parent)
+	if err != nil {
+		return nil, err
+	}
+	var used []types.DBCategory
+	err = d.DB.Where("id BETWEEN ? AND ?", int64(min), int64(max)).Order("id").Find(&used).Error
+	if err != nil {
+		return nil, err
+	}
+	id := min
+	for _, c := range used {
+		if types.Category(c.ID) != id {
+			break
+		}
+		id++
+	}
+	if id > max {
+		return nil, fmt.Errorf("no custom categories left for %s", parent)
+	}
+	cat := &types.DBCategory{}
+	err = d.DB.Exec("INSERT INTO category (id, name, active, description, disable_preview, min_size, parent_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		int64(id), name, true, "", false, 0, int64(parent)).Error
+	if err != nil {
+		return nil, err
+	}
+	err = registerCustomCategory(id, name)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("Added custom category %d %s", id, id)
+	err = d.DB.First(cat, int64(id)).Error
+	return cat, err
+}
+
+func registerCustomCategory(c types.Category, name string) error {
+	return types.RegisterCustomCategory(c, fmt.Sprintf("%s_%s", c.Parent(), categoryNameRegex.ReplaceAllString(name, "")))
+}
+
+var categoryNameRegex = regexp.MustCompile(`(?i)[^a-z0-9]+`)
+
+// sameCategoryName returns true if the name of a category in the database is
+// the name of the Category constant c without its parent.
+func sameCategoryName(c types.Category, dbName string) bool {
+	constName := categoryConstName(c)
+	norm := func(s string) string {
+		return categoryNameRegex.ReplaceAllString(strings.ToLower(s), "")
+	}
+	return norm(constName) == norm(dbName)
+}
+
+// CheckCategories checks that the categories in the database match the
+// Category constants and registers the custom categories in it.  Releases are
+// categorized with the constants but shown with the names in the database so
+// they have to agree.
+func (d *Handle) CheckCategories() error {
+	cats, err := d.GetAllCategories()
+	if err != nil {
+		return err
+	}
+	problems := []string{}
+	seen := map[types.Category]bool{}
+	for _, dbc := range cats {
+		c := types.Category(dbc.ID)
+		seen[c] = true
+		var parent types.Category = types.Unknown
+		if dbc.ParentID.Valid {
+			parent = types.Category(dbc.ParentID.Int64)
+		}
+		wantParent := c.Parent()
+		if wantParent == c {
+			wantParent = types.Unknown
+		}
+		switch {
+		case c.IsKnown():
+			if !sameCategoryName(c, dbc.Name) {
+				problems = append(problems, fmt.Sprintf("category %d is %s in the database but %s in the code", dbc.ID, dbc.Name, c))
+			}
+		case c.IsCustom():
+			err = registerCustomCategory(c, dbc.Name)
+			if err != nil {
+				return err
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("category %d (%s) is neither known nor in a custom category range", dbc.ID, dbc.Name))
+			continue
+		}
+		if parent != wantParent {
+			problems = append(problems, fmt.Sprintf("category %d (%s) has parent %d instead of %d", dbc.ID, dbc.Name, parent, wantParent))
+		}
+	}
+	for _, c := range types.AllCategories() {
+		if !seen[c] {
+			problems = append(problems, fmt.Sprintf("category %d (%s) is missing from the database", c, c))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("categories in the database don't match the code: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// SyncCategories rewrites the names and parents of the known categories in
+// the database from the Category constants and adds the missing ones.
+// Names that only differ in case and punctuation are kept.  Returns how many
+// categories were changed.  Categories that are neither known nor custom are
+// left for CheckCategories to report.
+func (d *Handle) SyncCategories() (int, error) {
+	cats, err := d.GetAllCategories()
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[types.Category]types.DBCategory, len(cats))
+	for _, dbc := range cats {
+		byID[types.Category(dbc.ID)] = dbc
+	}
+
+	changed := 0
+	tx := d.DB.Begin()
+	for _, c := range types.AllCategories() {
+		var parent sql.NullInt64
+		if c.Parent() != c {
+			parent = sql.NullInt64{Int64: int64(c.Parent()), Valid: true}
+		}
+		dbc, ok := byID[c]
+		if !ok {
+			err = tx.Exec("INSERT INTO category (id, name, active, description, disable_preview, min_size, parent_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+				int64(c), categoryConstName(c), true, "", false, 0, parent).Error
+			if err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+			logrus.Infof("Added category %d (%s)", c, categoryConstName(c))
+			changed++
+			continue
+		}
+		updates := map[string]interface{}{}
+		if !sameCategoryName(c, dbc.Name) {
+			updates["name"] = categoryConstName(c)
+		}
+		if dbc.ParentID != parent {
+			updates["parent_id"] = parent
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		err = tx.Model(types.DBCategory{}).Where("id = ?", dbc.ID).UpdateColumns(updates).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		logrus.Infof("Changed category %d (%s): %v", dbc.ID, dbc.Name, updates)
+		changed++
+	}
+	return changed, tx.Commit().Error
+}
+
+// categoryConstName returns the name of the Category constant c without its
+// parent.
+func categoryConstName(c types.Category) string {
+	name := c.String()
+	if i := strings.Index(name, "_"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
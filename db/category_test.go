@@ -0,0 +1,136 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestCheckCategories(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	err := dbh.CheckCategories()
+	Expect(err).ToNot(HaveOccurred())
+
+	err = dbh.DB.Exec("UPDATE category SET name = ? WHERE id = ?", "Comics", int64(types.Book_Ebook)).Error
+	Expect(err).ToNot(HaveOccurred())
+	err = dbh.DB.Exec("DELETE FROM category WHERE id = ?", int64(types.TV_Anime)).Error
+	Expect(err).ToNot(HaveOccurred())
+	err = dbh.DB.Exec("INSERT INTO category (id, name, active, parent_id) VALUES (?, ?, ?, ?)", 5555, "Bogus", true, 5000).Error
+	Expect(err).ToNot(HaveOccurred())
+	err = dbh.CheckCategories()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("category 7020 is Comics"))
+	Expect(err.Error()).To(ContainSubstring("category 5070 (TV_Anime) is missing"))
+	Expect(err.Error()).To(ContainSubstring("category 5555 (Bogus)"))
+
+	err = dbh.DB.Exec("UPDATE category SET parent_id = ? WHERE id = ?", 2000, int64(types.TV_HD)).Error
+	Expect(err).ToNot(HaveOccurred())
+	changed, err := dbh.SyncCategories()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(changed).To(Equal(3))
+	err = dbh.CheckCategories()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).ToNot(ContainSubstring("category 7020"))
+	Expect(err.Error()).ToNot(ContainSubstring("category 5070"))
+	Expect(err.Error()).ToNot(ContainSubstring("category 5040"))
+	Expect(err.Error()).To(ContainSubstring("category 5555 (Bogus)"))
+
+	var anime types.DBCategory
+	err = dbh.DB.First(&anime, int64(types.TV_Anime)).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(anime.Name).To(Equal("Anime"))
+	Expect(anime.ParentID.Int64).To(Equal(int64(types.TV)))
+
+	// Names differing only in punctuation are kept.
+	changed, err = dbh.SyncCategories()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(changed).To(Equal(0))
+	var android types.DBCategory
+	err = dbh.DB.First(&android, int64(types.PC_PhoneAndroid)).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(android.Name).To(Equal("Phone-Android"))
+}
+
+func TestCustomCategories(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	_, err := dbh.AddCustomCategory(types.TV_HD, "UHD")
+	Expect(err).To(HaveOccurred())
+
+	cat, err := dbh.AddCustomCategory(types.TV, "UHD")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cat.ID).To(Equal(int64(500000)))
+	Expect(cat.ParentID.Int64).To(Equal(int64(types.TV)))
+	Expect(types.CategoryFromInt(cat.ID).String()).To(Equal("TV_UHD"))
+	cat, err = dbh.AddCustomCategory(types.TV, "Kids")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cat.ID).To(Equal(int64(500001)))
+
+	err = dbh.CheckCategories()
+	Expect(err).ToNot(HaveOccurred())
+
+	name := "Children"
+	cat, err = dbh.EditCategory(500001, CategoryEdit{Name: &name})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cat.Name).To(Equal("Children"))
+	_, err = dbh.EditCategory(int64(types.TV_HD), CategoryEdit{Name: &name})
+	Expect(err).To(HaveOccurred())
+
+	minSize := 1000
+	desc := "High definition TV"
+	cat, err = dbh.EditCategory(int64(types.TV_HD), CategoryEdit{MinSize: &minSize, Description: &desc})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cat.MinSize).To(Equal(1000))
+	Expect(cat.Description).To(Equal(desc))
+}
+
+func TestInactiveCategories(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	for i, cat := range []types.Category{types.TV_HD, types.XXX_x264, types.Movie_HD} {
+		rel := types.Release{
+			Name:       "Some.Name",
+			SearchName: "Some Name",
+			Hash:       cat.String(),
+			CategoryID: sql.NullInt64{Int64: int64(cat), Valid: true},
+		}
+		if i == 2 {
+			rel.CategoryID = sql.NullInt64{}
+		}
+		err := dbh.DB.Save(&rel).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	err := dbh.SetCategoryActive(int64(types.XXX), false)
+	Expect(err).ToNot(HaveOccurred())
+	err = dbh.SetCategoryActive(int64(types.TV_SD), false)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dbh.SetCategoryActive(1234, false)).To(HaveOccurred())
+
+	cats, err := dbh.GetCategories()
+	Expect(err).ToNot(HaveOccurred())
+	for _, c := range cats {
+		Expect(c.ID).ToNot(Equal(int64(types.XXX)))
+		if c.ID == int64(types.TV) {
+			for _, sub := range c.SubCategories {
+				Expect(sub.ID).ToNot(Equal(int64(types.TV_SD)))
+			}
+		}
+	}
+
+	releases, err := dbh.SearchReleases("Some", 0, 10, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(releases).To(HaveLen(2))
+	releases, err = dbh.SearchReleasesByName("Some")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(releases).To(HaveLen(2))
+	for _, rel := range releases {
+		Expect(rel.CategoryName()).ToNot(Equal(types.XXX_x264))
+	}
+}
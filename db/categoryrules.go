@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+)
+
+// GetCategoryRules returns the category rules in the order they are tried,
+// compiled.  Disabled rules are left out unless all is set.
+func (d *Handle) GetCategoryRules(all bool) ([]*types.CategoryRule, error) {
+	var rules []*types.CategoryRule
+	q := d.DB.Order("ordinal, id")
+	if !all {
+		q = q.Where("enabled = ?", true)
+	}
+	err := q.Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		err = r.Compile()
+		if err != nil {
+			return nil, fmt.Errorf("error compiling category rule %d: %v", r.ID, err)
+		}
+	}
+	return rules, nil
+}
+
+// AddCategoryRule checks and saves a new category rule.
+func (d *Handle) AddCategoryRule(r *types.CategoryRule) error {
+	err := r.Compile()
+	if err != nil {
+		return err
+	}
+	cat := types.Category(r.CategoryID)
+	if types.CategoryFromInt(r.CategoryID) != cat {
+		return fmt.Errorf("unknown category %d", r.CategoryID)
+	}
+	if r.MaxSize > 0 && r.MaxSize < r.MinSize {
+		return fmt.Errorf("maximum size %d is smaller than minimum size %d", r.MaxSize, r.MinSize)
+	}
+	err = d.DB.Save(r).Error
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Added category rule %d: %s", r.ID, cat)
+	return nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestCategoryRules(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	err := dbh.AddCategoryRule(&types.CategoryRule{NameRegex: `(`, CategoryID: int64(types.TV_Foreign)})
+	Expect(err).To(HaveOccurred())
+	err = dbh.AddCategoryRule(&types.CategoryRule{NameRegex: `foo`, CategoryID: 1234})
+	Expect(err).To(HaveOccurred())
+	err = dbh.AddCategoryRule(&types.CategoryRule{NameRegex: `foo`, MinSize: 10, MaxSize: 5, CategoryID: int64(types.TV_Foreign)})
+	Expect(err).To(HaveOccurred())
+
+	rules := []*types.CategoryRule{
+		{Ordinal: 2, NameRegex: `(?i)\.S\d\dE\d\d\.`, CategoryID: int64(types.TV_Other), Enabled: true},
+		{Ordinal: 1, NameRegex: `(?i)\.NORDiC\.`, GroupRegex: `^alt\.binaries\.teevee$`, CategoryID: int64(types.TV_Foreign), Enabled: true},
+		{Ordinal: 0, NameRegex: `.`, CategoryID: int64(types.Other_Misc)},
+	}
+	for _, r := range rules {
+		err = dbh.AddCategoryRule(r)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	enabled, err := dbh.GetCategoryRules(false)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(enabled).To(HaveLen(2))
+	Expect(enabled[0].ID).To(Equal(rules[1].ID))
+	Expect(enabled[0].CompiledName).ToNot(BeNil())
+	all, err := dbh.GetCategoryRules(true)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(all).To(HaveLen(3))
+
+	Expect(categorizeRelease(enabled, "Some.Show.S01E01.NORDiC.720p.HDTV.x264-GRP", "alt.binaries.teevee", 0, nil)).To(Equal(types.TV_Foreign))
+	Expect(categorizeRelease(enabled, "Some.Show.S01E01.NORDiC.720p.HDTV.x264-GRP", "alt.binaries.hdtv", 0, nil)).To(Equal(types.TV_Other))
+	Expect(categorizeRelease(enabled, "Movie.Name.2001-bluray-1080p.x264", "alt.binaries.teevee", 0, nil)).To(Equal(types.Movie_BluRay))
+
+	// Recategorizing uses the rules too.
+	grp := types.Group{Name: "alt.binaries.teevee", Active: true}
+	err = dbh.DB.Save(&grp).Error
+	Expect(err).ToNot(HaveOccurred())
+	rel := types.Release{
+		Name:       "Some.Show.S01E01.NORDiC.720p.HDTV.x264-GRP",
+		Hash:       "nordic",
+		GroupID:    sql.NullInt64{Int64: grp.ID, Valid: true},
+		CategoryID: sql.NullInt64{Int64: int64(types.TV_HD), Valid: true},
+	}
+	err = dbh.DB.Save(&rel).Error
+	Expect(err).ToNot(HaveOccurred())
+	stats, err := dbh.RecategorizeReleases(RecategorizeOptions{})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(stats.Changes).To(Equal(map[CategoryChange]int{
+		{From: types.TV_HD, To: types.TV_Foreign}: 1,
+	}))
+}
@@ -24,6 +24,8 @@ type Handle struct {
 	DB           gorm.DB
 	writeUpdates bool
 	syncMutex    sync.Mutex
+	// Store compressed NZBs in this directory instead of the database.
+	NZBDir string
 }
 
 // debugLogger satisfies Gorm's logger interface
@@ -126,7 +128,7 @@ func (d *Handle) CreatePart(p *types.Part) error {
 
 func (d *Handle) SearchReleasesByName(name string) ([]types.Release, error) {
 	var releases []types.Release
-	err := d.DB.Where("search_name LIKE ?", fmt.Sprintf("%%%s%%", name)).Preload("Category").Preload("Group").Find(&releases).Error
+	err := d.DB.Where("search_name LIKE ? AND status NOT IN (?) AND canonical_id IS NULL AND "+inactiveCategoryQuery, fmt.Sprintf("%%%s%%", name), []int{types.ReleaseHidden, types.ReleaseFailed}, false, false).Preload("Category").Preload("Group").Find(&releases).Error
 	return releases, err
 }
 
@@ -163,6 +165,11 @@ func (d *Handle) DisableGroup(groupname string) error {
 	return d.DB.Save(g).Error
 }
 
+// SaveGroup saves changes to a group.
+func (d *Handle) SaveGroup(g *types.Group) error {
+	return d.DB.Save(g).Error
+}
+
 // GetAllGroups returns all groups
 func (d *Handle) GetAllGroups() ([]types.Group, error) {
 	var g []types.Group
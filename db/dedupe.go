@@ -0,0 +1,232 @@
+package db
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/nzb"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// How far the size and number of files of a release may be off from an
+// earlier one to still be a duplicate of it.  Incomplete posts are missing
+// some of their size.
+const (
+	dupeSizeTolerance  = 0.1
+	dupeFilesTolerance = 0.1
+)
+
+var nonAlnumRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeReleaseName returns name without case and punctuation so the
+// names reposts and copies get in other groups match.
+func normalizeReleaseName(name string) string {
+	return nonAlnumRegex.ReplaceAllString(strings.ToLower(name), "")
+}
+
+// findCanonicalRelease returns the first visible release rel is a duplicate
+// of, or nil if there isn't one.  Reposts of hidden or failed releases stay
+// visible.
+func (d *Handle) findCanonicalRelease(rel *types.Release) (*types.Release, error) {
+	if rel.NormalizedName == "" {
+		return nil, nil
+	}
+	minSize := int64(float64(rel.Size) * (1 - dupeSizeTolerance))
+	maxSize := int64(float64(rel.Size) * (1 + dupeSizeTolerance))
+	fileSlack := int(float64(rel.Files) * dupeFilesTolerance)
+	if fileSlack < 1 {
+		fileSlack = 1
+	}
+	canonical := &types.Release{}
+	err := d.DB.Where("normalized_name = ? AND canonical_id IS NULL AND id < ? AND status NOT IN (?) AND size BETWEEN ? AND ? AND files BETWEEN ? AND ?",
+		rel.NormalizedName, rel.ID, []int{types.ReleaseHidden, types.ReleaseFailed}, minSize, maxSize, rel.Files-fileSlack, rel.Files+fileSlack).Order("id").First(canonical).Error
+	if err == gorm.RecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return canonical, nil
+}
+
+// dedupeRelease links rel to the release it duplicates, if any.  If that
+// release is incomplete it gets the segments it is missing from the NZB of
+// rel.  Returns whether rel is a duplicate and whether segments were merged.
+func (d *Handle) dedupeRelease(rel *types.Release) (bool, bool, error) {
+	canonical, err := d.findCanonicalRelease(rel)
+	if err != nil || canonical == nil {
+		return false, false, err
+	}
+	err = d.DB.Model(types.Release{}).Where("id = ?", rel.ID).UpdateColumn("canonical_id", canonical.ID).Error
+	if err != nil {
+		return false, false, err
+	}
+	rel.CanonicalID = sql.NullInt64{Int64: canonical.ID, Valid: true}
+	logrus.Infof("Release %d (%s) is a duplicate of release %d", rel.ID, rel.Name, canonical.ID)
+
+	if canonical.Completion >= 100 {
+		return true, false, nil
+	}
+	merged, err := d.mergeRepost(canonical, rel)
+	return true, merged, err
+}
+
+// promoteDuplicate makes the oldest visible duplicate of the release with the
+// given id canonical in its place and links the other duplicates to it.  It
+// has to be called before a canonical release is deleted, hidden or failed,
+// otherwise its duplicates disappear from searches with it.
+func promoteDuplicate(tx *gorm.DB, canonicalID int64) error {
+	var dupes []types.Release
+	err := tx.Select("id, status").Where("canonical_id = ?", canonicalID).Order("id").Find(&dupes).Error
+	if err != nil || len(dupes) == 0 {
+		return err
+	}
+	promoted := dupes[0].ID
+	for _, dupe := range dupes {
+		if dupe.Status != types.ReleaseHidden && dupe.Status != types.ReleaseFailed {
+			promoted = dupe.ID
+			break
+		}
+	}
+	err = tx.Model(types.Release{}).Where("id = ?", promoted).UpdateColumn("canonical_id", sql.NullInt64{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Model(types.Release{}).Where("canonical_id = ?", canonicalID).UpdateColumn("canonical_id", promoted).Error
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Release %d is now canonical in place of release %d", promoted, canonicalID)
+	return nil
+}
+
+// mergeRepost adds the segments of repost that canonical is missing to the
+// NZB of canonical.
+func (d *Handle) mergeRepost(canonical, repost *types.Release) (bool, error) {
+	canonicalDoc, err := d.GetNZB(canonical)
+	if err != nil {
+		return false, err
+	}
+	repostDoc, err := d.GetNZB(repost)
+	if err != nil {
+		return false, err
+	}
+	canonicalNZB, err := nzb.ParseNZB(canonicalDoc)
+	if err != nil {
+		return false, err
+	}
+	repostNZB, err := nzb.ParseNZB(repostDoc)
+	if err != nil {
+		return false, err
+	}
+	added := canonicalNZB.Merge(repostNZB)
+	if added == 0 {
+		return false, nil
+	}
+	doc, err := nzb.EncodeNZB(canonicalNZB)
+	if err != nil {
+		return false, err
+	}
+
+	completion := canonical.Completion
+	if repost.Completion > completion {
+		completion = repost.Completion
+	}
+	tx := d.DB.Begin()
+	if canonical.NZB != "" {
+		err = tx.Model(types.Release{}).Where("id = ?", canonical.ID).UpdateColumn("nzb", "").Error
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+	file, err := d.saveNZB(tx, canonical.ID, doc)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	err = tx.Model(types.Release{}).Where("id = ?", canonical.ID).UpdateColumns(map[string]interface{}{
+		"size":       canonicalNZB.Size(),
+		"files":      len(canonicalNZB.Files),
+		"completion": completion,
+	}).Error
+	if err != nil {
+		d.rollbackNZBs(tx, file)
+		return false, err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		d.rollbackNZBs(tx, file)
+		return false, err
+	}
+	logrus.Infof("Added %d segments from repost %d to release %d (%s)", added, repost.ID, canonical.ID, canonical.Name)
+	return true, nil
+}
+
+// DedupeStats counts what DedupeReleases did.
+type DedupeStats struct {
+	Checked    int
+	Duplicates int
+	Merged     int
+}
+
+// DedupeReleases links the releases made before duplicate detection to the
+// release they duplicate, batch releases at a time.
+func (d *Handle) DedupeReleases(batch int) (*DedupeStats, error) {
+	if batch < 1 {
+		batch = 1000
+	}
+	stats := &DedupeStats{}
+	lastID := int64(0)
+	for {
+		var releases []types.Release
+		err := d.DB.Select("id, name, size, nzb, completion").Where("(normalized_name = ? OR normalized_name IS NULL) AND id > ?", "", lastID).Order("id").Limit(batch).Find(&releases).Error
+		if err != nil {
+			return stats, err
+		}
+		if len(releases) == 0 {
+			break
+		}
+		lastID = releases[len(releases)-1].ID
+		for i := range releases {
+			rel := &releases[i]
+			stats.Checked++
+			rel.NormalizedName = normalizeReleaseName(rel.Name)
+			doc, err := d.GetNZB(rel)
+			if err != nil && err != gorm.RecordNotFound {
+				return stats, err
+			}
+			if nz, perr := nzb.ParseNZB(doc); err == nil && perr == nil {
+				rel.Files = len(nz.Files)
+			}
+			err = d.DB.Model(types.Release{}).Where("id = ?", rel.ID).UpdateColumns(map[string]interface{}{
+				"normalized_name": rel.NormalizedName,
+				"files":           rel.Files,
+			}).Error
+			if err != nil {
+				return stats, err
+			}
+			dupe, merged, err := d.dedupeRelease(rel)
+			if err != nil {
+				return stats, err
+			}
+			if dupe {
+				stats.Duplicates++
+			}
+			if merged {
+				stats.Merged++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// GetDuplicateReleases returns the releases linked to a canonical release.
+func (d *Handle) GetDuplicateReleases(canonicalID int64) ([]types.Release, error) {
+	var releases []types.Release
+	err := d.DB.Where("canonical_id = ?", canonicalID).Preload("Group").Order("id").Find(&releases).Error
+	return releases, err
+}
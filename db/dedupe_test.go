@@ -0,0 +1,196 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hobeone/gonab/nzb"
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func dedupeTestNZB(segments ...int) string {
+	part := types.Part{
+		Subject:   `Some.Movie [1/1] - "some.movie.mkv" yEnc (1/10)`,
+		From:      "test@foo.bar",
+		Posted:    time.Unix(0, 0),
+		GroupName: "misc.test",
+	}
+	for _, n := range segments {
+		part.Segments = append(part.Segments, types.Segment{
+			MessageID: string(rune('a'+n)) + "@foo.bar",
+			Size:      100,
+			Segment:   n,
+		})
+	}
+	doc, err := nzb.WriteNZB(&types.Binary{Name: "Some.Movie", Parts: []types.Part{part}})
+	Expect(err).ToNot(HaveOccurred())
+	return doc
+}
+
+func TestDedupeRelease(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	releases := []*types.Release{
+		{Name: "Some.Movie.2015.720p.BluRay.x264-GRP", Hash: "h1", Size: 900, Completion: 90},
+		{Name: "Some Movie 2015 720p BluRay x264 GRP", Hash: "h2", Size: 1000, Completion: 100},
+		{Name: "Other.Movie.2015.720p.BluRay.x264-GRP", Hash: "h3", Size: 1000, Completion: 100},
+	}
+	all := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	docs := []string{dedupeTestNZB(1, 2, 3, 4, 6, 7, 8, 9, 10), dedupeTestNZB(all...), dedupeTestNZB(all...)}
+	for i, rel := range releases {
+		rel.SearchName = cleanReleaseName(rel.Name)
+		rel.NormalizedName = normalizeReleaseName(rel.Name)
+		rel.Files = 1
+		err := dbh.DB.Save(rel).Error
+		Expect(err).ToNot(HaveOccurred())
+		_, err = dbh.saveNZB(&dbh.DB, rel.ID, docs[i])
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	dupe, merged, err := dbh.dedupeRelease(releases[0])
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dupe).To(BeFalse())
+	Expect(merged).To(BeFalse())
+
+	dupe, merged, err = dbh.dedupeRelease(releases[1])
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dupe).To(BeTrue())
+	Expect(merged).To(BeTrue())
+
+	dupe, _, err = dbh.dedupeRelease(releases[2])
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dupe).To(BeFalse())
+
+	var canonical types.Release
+	err = dbh.DB.First(&canonical, releases[0].ID).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(canonical.Completion).To(Equal(100.0))
+	Expect(canonical.Size).To(Equal(int64(1000)))
+	doc, err := dbh.GetNZB(&canonical)
+	Expect(err).ToNot(HaveOccurred())
+	nz, err := nzb.ParseNZB(doc)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(nz.Files[0].Segments).To(HaveLen(10))
+
+	dupes, err := dbh.GetDuplicateReleases(canonical.ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dupes).To(HaveLen(1))
+	Expect(dupes[0].ID).To(Equal(releases[1].ID))
+
+	found, err := dbh.SearchReleases("Movie", 0, 10, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(2))
+	found, err = dbh.SearchAllReleases("Movie", 0, 10, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(3))
+}
+
+func TestDedupeReleaseOfHidden(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	releases := []*types.Release{
+		{Name: "Some.Movie.2015.720p.BluRay.x264-GRP", Hash: "h1", Status: types.ReleaseHidden},
+		{Name: "Some.Movie.2015.720p.BluRay.x264-GRP", Hash: "h2"},
+	}
+	for _, rel := range releases {
+		rel.SearchName = cleanReleaseName(rel.Name)
+		rel.NormalizedName = normalizeReleaseName(rel.Name)
+		rel.Size = 1000
+		rel.Completion = 100
+		rel.Files = 1
+		err := dbh.DB.Save(rel).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	dupe, _, err := dbh.dedupeRelease(releases[1])
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dupe).To(BeFalse())
+
+	found, err := dbh.SearchReleases("Movie", 0, 10, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[1].ID))
+}
+
+func TestDedupeReleases(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	// Releases made before duplicate detection, with the NZB in the release.
+	for _, h := range []string{"h1", "h2"} {
+		rel := types.Release{Name: "Some.Movie-GRP", SearchName: "Some Movie GRP", Hash: h, Size: 300, Completion: 100, NZB: dedupeTestNZB(1, 2, 3)}
+		err := dbh.DB.Save(&rel).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	stats, err := dbh.DedupeReleases(1)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(*stats).To(Equal(DedupeStats{Checked: 2, Duplicates: 1}))
+
+	var rels []types.Release
+	err = dbh.DB.Order("id").Find(&rels).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rels[0].NormalizedName).To(Equal("somemoviegrp"))
+	Expect(rels[0].Files).To(Equal(1))
+	Expect(rels[0].CanonicalID.Valid).To(BeFalse())
+	Expect(rels[1].CanonicalID.Int64).To(Equal(rels[0].ID))
+}
+
+func TestPromoteDuplicate(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	rels := make([]types.Release, 4)
+	for i := range rels {
+		rels[i] = types.Release{Name: "Some.Movie-GRP", SearchName: "Some Movie GRP", Hash: string(rune('a' + i))}
+		if i > 0 {
+			rels[i].CanonicalID = sql.NullInt64{Int64: rels[0].ID, Valid: true}
+		}
+		err := dbh.DB.Save(&rels[i]).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+	canonicalOf := func() map[int64]int64 {
+		var found []types.Release
+		err := dbh.DB.Order("id").Find(&found).Error
+		Expect(err).ToNot(HaveOccurred())
+		m := map[int64]int64{}
+		for _, r := range found {
+			m[r.ID] = r.CanonicalID.Int64
+		}
+		return m
+	}
+	visible := func() []int64 {
+		found, err := dbh.SearchReleases("Movie", 0, 10, nil)
+		Expect(err).ToNot(HaveOccurred())
+		ids := []int64{}
+		for _, r := range found {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	// Deleting the canonical release promotes the oldest duplicate.
+	err := dbh.DeleteRelease(rels[0].ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(canonicalOf()).To(Equal(map[int64]int64{rels[1].ID: 0, rels[2].ID: rels[1].ID, rels[3].ID: rels[1].ID}))
+	Expect(visible()).To(Equal([]int64{rels[1].ID}))
+
+	// So does hiding it.
+	hidden := true
+	_, err = dbh.EditRelease(rels[1].ID, ReleaseEdit{Hidden: &hidden})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(canonicalOf()).To(Equal(map[int64]int64{rels[1].ID: 0, rels[2].ID: 0, rels[3].ID: rels[2].ID}))
+	Expect(visible()).To(Equal([]int64{rels[2].ID}))
+
+	// Hidden duplicates are only promoted if there are no others.
+	err = dbh.DB.Model(types.Release{}).Where("id = ?", rels[3].ID).UpdateColumn("status", types.ReleaseFailed).Error
+	Expect(err).ToNot(HaveOccurred())
+	err = dbh.DeleteRelease(rels[2].ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(canonicalOf()).To(Equal(map[int64]int64{rels[1].ID: 0, rels[3].ID: 0}))
+	Expect(visible()).To(BeEmpty())
+}
@@ -0,0 +1,3 @@
+DROP TABLE `regex_hit`;
+ALTER TABLE `release` DROP COLUMN regex_id;
+ALTER TABLE `binary` DROP COLUMN regex_id;
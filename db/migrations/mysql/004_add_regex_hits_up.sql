@@ -0,0 +1,11 @@
+ALTER TABLE `binary` ADD regex_id INT(11);
+ALTER TABLE `release` ADD regex_id INT(11);
+CREATE TABLE `regex_hit` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `kind` varchar(255) DEFAULT NULL,
+  `regex_id` int(11) DEFAULT NULL,
+  `hits` bigint(20) DEFAULT NULL,
+  `last_matched` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_regex_hit_kind_regex_id` (`kind`,`regex_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
@@ -0,0 +1,3 @@
+ALTER TABLE `release` DROP KEY `idx_release_posted`;
+ALTER TABLE `binary` DROP KEY `idx_binary_posted`;
+ALTER TABLE `missed_message` DROP COLUMN created_at;
@@ -0,0 +1,4 @@
+ALTER TABLE `missed_message` ADD created_at TIMESTAMP NULL DEFAULT NULL;
+UPDATE `missed_message` SET created_at = CURRENT_TIMESTAMP;
+ALTER TABLE `binary` ADD KEY `idx_binary_posted` (`posted`);
+ALTER TABLE `release` ADD KEY `idx_release_posted` (`posted`);
@@ -0,0 +1,2 @@
+ALTER TABLE `release` DROP COLUMN completion;
+ALTER TABLE `group` DROP COLUMN min_completion;
@@ -0,0 +1,2 @@
+ALTER TABLE `group` ADD min_completion DOUBLE;
+ALTER TABLE `release` ADD completion DOUBLE;
@@ -0,0 +1,2 @@
+ALTER TABLE `group` DROP COLUMN max_size;
+ALTER TABLE `group` DROP COLUMN max_files;
@@ -0,0 +1,4 @@
+ALTER TABLE `binary` DROP KEY `idx_binary_created_at`;
+ALTER TABLE `part` DROP KEY `idx_part_created_at`;
+ALTER TABLE `binary` DROP COLUMN created_at;
+ALTER TABLE `part` DROP COLUMN created_at;
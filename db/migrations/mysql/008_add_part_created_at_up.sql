@@ -0,0 +1,6 @@
+ALTER TABLE `part` ADD created_at TIMESTAMP NULL DEFAULT NULL;
+ALTER TABLE `binary` ADD created_at TIMESTAMP NULL DEFAULT NULL;
+UPDATE `part` SET created_at = CURRENT_TIMESTAMP;
+UPDATE `binary` SET created_at = CURRENT_TIMESTAMP;
+ALTER TABLE `part` ADD KEY `idx_part_created_at` (`created_at`);
+ALTER TABLE `binary` ADD KEY `idx_binary_created_at` (`created_at`);
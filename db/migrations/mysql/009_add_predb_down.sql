@@ -0,0 +1,4 @@
+ALTER TABLE `release` DROP KEY `idx_release_predb_id`;
+ALTER TABLE `release` DROP COLUMN nuked;
+ALTER TABLE `release` DROP COLUMN predb_id;
+DROP TABLE `predb`;
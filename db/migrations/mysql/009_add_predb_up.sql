@@ -0,0 +1,16 @@
+CREATE TABLE `predb` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `title` varchar(255) DEFAULT NULL,
+  `category` varchar(255) DEFAULT NULL,
+  `size` bigint(20) DEFAULT NULL,
+  `files` int(11) DEFAULT NULL,
+  `nuked` tinyint(1) DEFAULT NULL,
+  `nuke_reason` varchar(255) DEFAULT NULL,
+  `pre_time` timestamp NULL DEFAULT NULL,
+  `source` varchar(255) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_predb_title` (`title`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+ALTER TABLE `release` ADD predb_id bigint(20);
+ALTER TABLE `release` ADD nuked tinyint(1) DEFAULT 0;
+ALTER TABLE `release` ADD KEY `idx_release_predb_id` (`predb_id`);
@@ -0,0 +1,4 @@
+ALTER TABLE `release` DROP KEY `idx_release_request_status`;
+ALTER TABLE `release` DROP COLUMN request_status;
+ALTER TABLE `release` DROP COLUMN request_id;
+DROP TABLE `request_id`;
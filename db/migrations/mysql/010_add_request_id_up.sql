@@ -0,0 +1,11 @@
+CREATE TABLE `request_id` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `request_id` bigint(20) NOT NULL,
+  `group_name` varchar(255) NOT NULL,
+  `title` varchar(255) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_request_id_group_name` (`request_id`, `group_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+ALTER TABLE `release` ADD request_id bigint(20) DEFAULT 0;
+ALTER TABLE `release` ADD request_status int(11) DEFAULT 0;
+ALTER TABLE `release` ADD KEY `idx_release_request_status` (`request_status`);
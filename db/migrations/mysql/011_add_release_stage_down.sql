@@ -0,0 +1,2 @@
+ALTER TABLE `release` DROP KEY `idx_release_status`;
+DROP TABLE `release_stage`;
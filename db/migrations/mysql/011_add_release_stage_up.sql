@@ -0,0 +1,13 @@
+CREATE TABLE `release_stage` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `release_id` bigint(20) NOT NULL,
+  `stage` varchar(255) NOT NULL,
+  `attempts` int(11) DEFAULT 0,
+  `last_attempt_at` timestamp NULL DEFAULT NULL,
+  `completed_at` timestamp NULL DEFAULT NULL,
+  `result` varchar(1024) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_release_stage_release_id_stage` (`release_id`, `stage`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+UPDATE `release` SET status = 0 WHERE status IS NULL;
+ALTER TABLE `release` ADD KEY `idx_release_status` (`status`);
@@ -0,0 +1 @@
+ALTER TABLE `release` DROP COLUMN category_locked;
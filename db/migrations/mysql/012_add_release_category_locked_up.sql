@@ -0,0 +1 @@
+ALTER TABLE `release` ADD category_locked tinyint(1) DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP TABLE `deleted_release`;
+ALTER TABLE `release` DROP COLUMN status_locked;
+ALTER TABLE `release` DROP COLUMN name_locked;
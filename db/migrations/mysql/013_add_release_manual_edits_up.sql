@@ -0,0 +1,10 @@
+ALTER TABLE `release` ADD name_locked tinyint(1) DEFAULT 0;
+ALTER TABLE `release` ADD status_locked tinyint(1) DEFAULT 0;
+CREATE TABLE `deleted_release` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `hash` varchar(255) NOT NULL,
+  `name` varchar(255) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_deleted_release_hash` (`hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
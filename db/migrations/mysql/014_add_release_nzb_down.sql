@@ -0,0 +1 @@
+DROP TABLE `release_nzb`;
@@ -0,0 +1,10 @@
+CREATE TABLE `release_nzb` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `release_id` bigint(20) NOT NULL,
+  `hash` varchar(255) NOT NULL,
+  `size` bigint(20) DEFAULT 0,
+  `data` longblob,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_release_nzb_release_id` (`release_id`),
+  KEY `idx_release_nzb_hash` (`hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
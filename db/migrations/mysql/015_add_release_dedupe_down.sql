@@ -0,0 +1,5 @@
+ALTER TABLE `release` DROP KEY `idx_release_canonical_id`;
+ALTER TABLE `release` DROP KEY `idx_release_normalized_name`;
+ALTER TABLE `release` DROP COLUMN canonical_id;
+ALTER TABLE `release` DROP COLUMN files;
+ALTER TABLE `release` DROP COLUMN normalized_name;
@@ -0,0 +1,5 @@
+ALTER TABLE `release` ADD normalized_name varchar(255) DEFAULT '';
+ALTER TABLE `release` ADD files int(11) DEFAULT 0;
+ALTER TABLE `release` ADD canonical_id bigint(20) DEFAULT NULL;
+ALTER TABLE `release` ADD KEY `idx_release_normalized_name` (`normalized_name`);
+ALTER TABLE `release` ADD KEY `idx_release_canonical_id` (`canonical_id`);
@@ -0,0 +1,13 @@
+CREATE TABLE `category_rule` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `ordinal` int(11) DEFAULT 0,
+  `name_regex` varchar(2048) DEFAULT '',
+  `group_regex` varchar(255) DEFAULT '',
+  `min_size` bigint(20) DEFAULT 0,
+  `max_size` bigint(20) DEFAULT 0,
+  `category_id` bigint(20) NOT NULL,
+  `enabled` tinyint(1) DEFAULT 1,
+  `description` varchar(255) DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_category_rule_ordinal` (`ordinal`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
@@ -0,0 +1 @@
+DROP TABLE `tv_info`;
@@ -0,0 +1,12 @@
+CREATE TABLE `tv_info` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `release_id` bigint(20) NOT NULL,
+  `show_name` varchar(255) DEFAULT '',
+  `show_key` varchar(255) DEFAULT '',
+  `season` varchar(16) DEFAULT '',
+  `episode` varchar(16) DEFAULT '',
+  `airdate` varchar(16) DEFAULT '',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_tv_info_release_id` (`release_id`),
+  KEY `idx_tv_info_show_key` (`show_key`, `season`, `episode`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
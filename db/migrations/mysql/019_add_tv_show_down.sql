@@ -0,0 +1,3 @@
+ALTER TABLE `tv_info` DROP KEY `idx_tv_info_tv_show_id`, DROP COLUMN `tv_show_id`;
+DROP TABLE `tv_show_alias`;
+DROP TABLE `tv_show`;
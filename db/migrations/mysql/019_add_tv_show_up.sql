@@ -0,0 +1,25 @@
+CREATE TABLE `tv_show` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `tvmaze_id` bigint(20) DEFAULT 0,
+  `tvdb_id` bigint(20) DEFAULT 0,
+  `tvrage_id` bigint(20) DEFAULT 0,
+  `title` varchar(255) DEFAULT '',
+  `title_key` varchar(255) DEFAULT '',
+  `country` varchar(8) DEFAULT '',
+  `year` int(11) DEFAULT 0,
+  PRIMARY KEY (`id`),
+  KEY `idx_tv_show_tvmaze_id` (`tvmaze_id`),
+  KEY `idx_tv_show_tvdb_id` (`tvdb_id`),
+  KEY `idx_tv_show_tvrage_id` (`tvrage_id`),
+  KEY `idx_tv_show_title_key` (`title_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+CREATE TABLE `tv_show_alias` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `tv_show_id` bigint(20) NOT NULL,
+  `alias` varchar(255) DEFAULT '',
+  `alias_key` varchar(255) DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_tv_show_alias_tv_show_id` (`tv_show_id`),
+  KEY `idx_tv_show_alias_alias_key` (`alias_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+ALTER TABLE `tv_info` ADD `tv_show_id` bigint(20) DEFAULT NULL, ADD KEY `idx_tv_info_tv_show_id` (`tv_show_id`);
@@ -0,0 +1,2 @@
+DROP TABLE `movie_info`;
+DROP TABLE `movie`;
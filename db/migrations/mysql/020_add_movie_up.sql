@@ -0,0 +1,28 @@
+CREATE TABLE `movie` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `imdb_id` bigint(20) DEFAULT 0,
+  `tmdb_id` bigint(20) DEFAULT 0,
+  `title` varchar(255) DEFAULT '',
+  `title_key` varchar(255) DEFAULT '',
+  `year` int(11) DEFAULT 0,
+  PRIMARY KEY (`id`),
+  KEY `idx_movie_imdb_id` (`imdb_id`),
+  KEY `idx_movie_tmdb_id` (`tmdb_id`),
+  KEY `idx_movie_title_key` (`title_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+CREATE TABLE `movie_info` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `release_id` bigint(20) NOT NULL,
+  `title` varchar(255) DEFAULT '',
+  `title_key` varchar(255) DEFAULT '',
+  `year` int(11) DEFAULT 0,
+  `resolution` varchar(16) DEFAULT '',
+  `source` varchar(16) DEFAULT '',
+  `codec` varchar(16) DEFAULT '',
+  `release_group` varchar(255) DEFAULT '',
+  `movie_id` bigint(20) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_movie_info_release_id` (`release_id`),
+  KEY `idx_movie_info_title_key` (`title_key`),
+  KEY `idx_movie_info_movie_id` (`movie_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
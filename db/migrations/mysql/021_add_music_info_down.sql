@@ -0,0 +1 @@
+DROP TABLE `music_info`;
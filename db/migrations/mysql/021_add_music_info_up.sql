@@ -0,0 +1,16 @@
+CREATE TABLE `music_info` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `release_id` bigint(20) NOT NULL,
+  `artist` varchar(255) DEFAULT '',
+  `artist_key` varchar(255) DEFAULT '',
+  `album` varchar(255) DEFAULT '',
+  `album_key` varchar(255) DEFAULT '',
+  `year` int(11) DEFAULT 0,
+  `format` varchar(16) DEFAULT '',
+  `bitrate` varchar(16) DEFAULT '',
+  `release_group` varchar(255) DEFAULT '',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_music_info_release_id` (`release_id`),
+  KEY `idx_music_info_artist_key` (`artist_key`, `album_key`),
+  KEY `idx_music_info_album_key` (`album_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
@@ -0,0 +1,14 @@
+CREATE TABLE `book_info` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `release_id` bigint(20) NOT NULL,
+  `author` varchar(255) DEFAULT '',
+  `author_key` varchar(255) DEFAULT '',
+  `title` varchar(255) DEFAULT '',
+  `title_key` varchar(255) DEFAULT '',
+  `year` int(11) DEFAULT 0,
+  `format` varchar(16) DEFAULT '',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_book_info_release_id` (`release_id`),
+  KEY `idx_book_info_author_key` (`author_key`),
+  KEY `idx_book_info_title_key` (`title_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
@@ -0,0 +1 @@
+ALTER TABLE `tv_info` DROP COLUMN `last_episode`;
@@ -0,0 +1 @@
+ALTER TABLE `tv_info` ADD `last_episode` int(11) DEFAULT 0;
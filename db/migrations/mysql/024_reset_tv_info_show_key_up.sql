@@ -0,0 +1 @@
+DELETE FROM `tv_info`;
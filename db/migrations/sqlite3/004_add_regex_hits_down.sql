@@ -0,0 +1 @@
+DROP TABLE "regex_hit";
@@ -0,0 +1,10 @@
+ALTER TABLE "binary" ADD regex_id INT(11);
+ALTER TABLE "release" ADD regex_id INT(11);
+CREATE TABLE "regex_hit" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "kind" varchar(255) DEFAULT NULL,
+  "regex_id" INTEGER DEFAULT NULL,
+  "hits" INTEGER DEFAULT NULL,
+  "last_matched" timestamp NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX "regex_hit_idx_regex_hit_kind_regex_id" ON "regex_hit" ("kind","regex_id");
@@ -0,0 +1,2 @@
+DROP INDEX "release_idx_release_posted";
+DROP INDEX "binary_idx_binary_posted";
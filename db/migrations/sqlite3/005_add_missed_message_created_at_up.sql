@@ -0,0 +1,4 @@
+ALTER TABLE "missed_message" ADD created_at timestamp NULL DEFAULT NULL;
+UPDATE "missed_message" SET created_at = CURRENT_TIMESTAMP;
+CREATE INDEX "binary_idx_binary_posted" ON "binary" ("posted");
+CREATE INDEX "release_idx_release_posted" ON "release" ("posted");
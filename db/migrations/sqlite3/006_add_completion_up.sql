@@ -0,0 +1,2 @@
+ALTER TABLE "group" ADD min_completion REAL;
+ALTER TABLE "release" ADD completion REAL;
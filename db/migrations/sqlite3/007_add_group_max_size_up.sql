@@ -0,0 +1,2 @@
+ALTER TABLE "group" ADD max_files INT(11);
+ALTER TABLE "group" ADD max_size BIGINT(20);
@@ -0,0 +1,2 @@
+DROP INDEX "binary_idx_binary_created_at";
+DROP INDEX "part_idx_part_created_at";
@@ -0,0 +1,6 @@
+ALTER TABLE "part" ADD created_at timestamp NULL DEFAULT NULL;
+ALTER TABLE "binary" ADD created_at timestamp NULL DEFAULT NULL;
+UPDATE "part" SET created_at = CURRENT_TIMESTAMP;
+UPDATE "binary" SET created_at = CURRENT_TIMESTAMP;
+CREATE INDEX "part_idx_part_created_at" ON "part" ("created_at");
+CREATE INDEX "binary_idx_binary_created_at" ON "binary" ("created_at");
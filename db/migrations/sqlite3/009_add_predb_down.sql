@@ -0,0 +1,2 @@
+DROP INDEX "release_idx_release_predb_id";
+DROP TABLE "predb";
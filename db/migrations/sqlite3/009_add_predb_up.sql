@@ -0,0 +1,15 @@
+CREATE TABLE "predb" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "title" varchar(255) DEFAULT NULL,
+  "category" varchar(255) DEFAULT NULL,
+  "size" INTEGER DEFAULT NULL,
+  "files" INTEGER DEFAULT NULL,
+  "nuked" tinyint(1) DEFAULT NULL,
+  "nuke_reason" varchar(255) DEFAULT NULL,
+  "pre_time" timestamp NULL DEFAULT NULL,
+  "source" varchar(255) DEFAULT NULL
+);
+CREATE UNIQUE INDEX "predb_idx_predb_title" ON "predb" ("title");
+ALTER TABLE "release" ADD predb_id INTEGER;
+ALTER TABLE "release" ADD nuked tinyint(1) DEFAULT 0;
+CREATE INDEX "release_idx_release_predb_id" ON "release" ("predb_id");
@@ -0,0 +1,2 @@
+DROP INDEX "release_idx_release_request_status";
+DROP TABLE "request_id";
@@ -0,0 +1,10 @@
+CREATE TABLE "request_id" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "request_id" INTEGER NOT NULL,
+  "group_name" varchar(255) NOT NULL,
+  "title" varchar(255) DEFAULT NULL
+);
+CREATE UNIQUE INDEX "request_id_idx_request_id_group_name" ON "request_id" ("request_id", "group_name");
+ALTER TABLE "release" ADD request_id INTEGER DEFAULT 0;
+ALTER TABLE "release" ADD request_status INTEGER DEFAULT 0;
+CREATE INDEX "release_idx_release_request_status" ON "release" ("request_status");
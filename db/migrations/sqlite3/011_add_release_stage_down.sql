@@ -0,0 +1,2 @@
+DROP INDEX "release_idx_release_status";
+DROP TABLE "release_stage";
@@ -0,0 +1,12 @@
+CREATE TABLE "release_stage" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "release_id" INTEGER NOT NULL,
+  "stage" varchar(255) NOT NULL,
+  "attempts" INTEGER DEFAULT 0,
+  "last_attempt_at" timestamp NULL DEFAULT NULL,
+  "completed_at" timestamp NULL DEFAULT NULL,
+  "result" varchar(1024) DEFAULT NULL
+);
+CREATE UNIQUE INDEX "release_stage_idx_release_stage_release_id_stage" ON "release_stage" ("release_id", "stage");
+UPDATE "release" SET status = 0 WHERE status IS NULL;
+CREATE INDEX "release_idx_release_status" ON "release" ("status");
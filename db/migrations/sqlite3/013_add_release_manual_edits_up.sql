@@ -0,0 +1,9 @@
+ALTER TABLE "release" ADD name_locked tinyint(1) DEFAULT 0;
+ALTER TABLE "release" ADD status_locked tinyint(1) DEFAULT 0;
+CREATE TABLE "deleted_release" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "hash" varchar(255) NOT NULL,
+  "name" varchar(255) DEFAULT NULL,
+  "created_at" timestamp NULL DEFAULT NULL
+);
+CREATE UNIQUE INDEX "deleted_release_idx_deleted_release_hash" ON "deleted_release" ("hash");
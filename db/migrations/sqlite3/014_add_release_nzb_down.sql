@@ -0,0 +1,3 @@
+DROP INDEX "release_nzb_idx_release_nzb_hash";
+DROP INDEX "release_nzb_idx_release_nzb_release_id";
+DROP TABLE "release_nzb";
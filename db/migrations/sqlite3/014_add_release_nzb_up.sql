@@ -0,0 +1,9 @@
+CREATE TABLE "release_nzb" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "release_id" INTEGER NOT NULL,
+  "hash" varchar(255) NOT NULL,
+  "size" INTEGER DEFAULT 0,
+  "data" blob
+);
+CREATE UNIQUE INDEX "release_nzb_idx_release_nzb_release_id" ON "release_nzb" ("release_id");
+CREATE INDEX "release_nzb_idx_release_nzb_hash" ON "release_nzb" ("hash");
@@ -0,0 +1,2 @@
+DROP INDEX "release_idx_release_canonical_id";
+DROP INDEX "release_idx_release_normalized_name";
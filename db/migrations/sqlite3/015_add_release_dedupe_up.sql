@@ -0,0 +1,5 @@
+ALTER TABLE "release" ADD normalized_name varchar(255) DEFAULT '';
+ALTER TABLE "release" ADD files INTEGER DEFAULT 0;
+ALTER TABLE "release" ADD canonical_id INTEGER DEFAULT NULL;
+CREATE INDEX "release_idx_release_normalized_name" ON "release" ("normalized_name");
+CREATE INDEX "release_idx_release_canonical_id" ON "release" ("canonical_id");
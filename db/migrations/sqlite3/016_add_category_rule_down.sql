@@ -0,0 +1,2 @@
+DROP INDEX "category_rule_idx_category_rule_ordinal";
+DROP TABLE "category_rule";
@@ -0,0 +1,12 @@
+CREATE TABLE "category_rule" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "ordinal" INTEGER DEFAULT 0,
+  "name_regex" varchar(2048) DEFAULT '',
+  "group_regex" varchar(255) DEFAULT '',
+  "min_size" INTEGER DEFAULT 0,
+  "max_size" INTEGER DEFAULT 0,
+  "category_id" INTEGER NOT NULL,
+  "enabled" tinyint(1) DEFAULT 1,
+  "description" varchar(255) DEFAULT ''
+);
+CREATE INDEX "category_rule_idx_category_rule_ordinal" ON "category_rule" ("ordinal");
@@ -0,0 +1,3 @@
+UPDATE "release" SET category_id = 7999 WHERE category_id = 7050;
+UPDATE "category" SET id = 7999 WHERE id = 7050;
+UPDATE "release" SET category_id = CASE category_id WHEN 7020 THEN 7010 WHEN 7030 THEN 7020 ELSE 7030 END WHERE category_id IN (7010, 7020, 7030);
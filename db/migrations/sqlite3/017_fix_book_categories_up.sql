@@ -0,0 +1,3 @@
+UPDATE "release" SET category_id = CASE category_id WHEN 7010 THEN 7020 WHEN 7020 THEN 7030 ELSE 7010 END WHERE category_id IN (7010, 7020, 7030);
+UPDATE "category" SET id = 7050 WHERE id = 7999;
+UPDATE "release" SET category_id = 7050 WHERE category_id = 7999;
@@ -0,0 +1,3 @@
+DROP INDEX "tv_info_idx_tv_info_show_key";
+DROP INDEX "tv_info_idx_tv_info_release_id";
+DROP TABLE "tv_info";
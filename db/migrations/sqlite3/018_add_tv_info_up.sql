@@ -0,0 +1,11 @@
+CREATE TABLE "tv_info" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "release_id" INTEGER NOT NULL,
+  "show_name" varchar(255) DEFAULT '',
+  "show_key" varchar(255) DEFAULT '',
+  "season" varchar(16) DEFAULT '',
+  "episode" varchar(16) DEFAULT '',
+  "airdate" varchar(16) DEFAULT ''
+);
+CREATE UNIQUE INDEX "tv_info_idx_tv_info_release_id" ON "tv_info" ("release_id");
+CREATE INDEX "tv_info_idx_tv_info_show_key" ON "tv_info" ("show_key", "season", "episode");
@@ -0,0 +1,9 @@
+DROP INDEX "tv_info_idx_tv_info_tv_show_id";
+DROP INDEX "tv_show_alias_idx_tv_show_alias_alias_key";
+DROP INDEX "tv_show_alias_idx_tv_show_alias_tv_show_id";
+DROP TABLE "tv_show_alias";
+DROP INDEX "tv_show_idx_tv_show_title_key";
+DROP INDEX "tv_show_idx_tv_show_tvrage_id";
+DROP INDEX "tv_show_idx_tv_show_tvdb_id";
+DROP INDEX "tv_show_idx_tv_show_tvmaze_id";
+DROP TABLE "tv_show";
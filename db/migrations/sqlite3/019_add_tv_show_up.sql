@@ -0,0 +1,24 @@
+CREATE TABLE "tv_show" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "tvmaze_id" INTEGER DEFAULT 0,
+  "tvdb_id" INTEGER DEFAULT 0,
+  "tvrage_id" INTEGER DEFAULT 0,
+  "title" varchar(255) DEFAULT '',
+  "title_key" varchar(255) DEFAULT '',
+  "country" varchar(8) DEFAULT '',
+  "year" INTEGER DEFAULT 0
+);
+CREATE INDEX "tv_show_idx_tv_show_tvmaze_id" ON "tv_show" ("tvmaze_id");
+CREATE INDEX "tv_show_idx_tv_show_tvdb_id" ON "tv_show" ("tvdb_id");
+CREATE INDEX "tv_show_idx_tv_show_tvrage_id" ON "tv_show" ("tvrage_id");
+CREATE INDEX "tv_show_idx_tv_show_title_key" ON "tv_show" ("title_key");
+CREATE TABLE "tv_show_alias" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "tv_show_id" INTEGER NOT NULL,
+  "alias" varchar(255) DEFAULT '',
+  "alias_key" varchar(255) DEFAULT ''
+);
+CREATE INDEX "tv_show_alias_idx_tv_show_alias_tv_show_id" ON "tv_show_alias" ("tv_show_id");
+CREATE INDEX "tv_show_alias_idx_tv_show_alias_alias_key" ON "tv_show_alias" ("alias_key");
+ALTER TABLE "tv_info" ADD tv_show_id INTEGER;
+CREATE INDEX "tv_info_idx_tv_info_tv_show_id" ON "tv_info" ("tv_show_id");
@@ -0,0 +1,8 @@
+DROP INDEX "movie_info_idx_movie_info_movie_id";
+DROP INDEX "movie_info_idx_movie_info_title_key";
+DROP INDEX "movie_info_idx_movie_info_release_id";
+DROP TABLE "movie_info";
+DROP INDEX "movie_idx_movie_title_key";
+DROP INDEX "movie_idx_movie_tmdb_id";
+DROP INDEX "movie_idx_movie_imdb_id";
+DROP TABLE "movie";
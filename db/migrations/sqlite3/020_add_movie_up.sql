@@ -0,0 +1,26 @@
+CREATE TABLE "movie" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "imdb_id" INTEGER DEFAULT 0,
+  "tmdb_id" INTEGER DEFAULT 0,
+  "title" varchar(255) DEFAULT '',
+  "title_key" varchar(255) DEFAULT '',
+  "year" INTEGER DEFAULT 0
+);
+CREATE INDEX "movie_idx_movie_imdb_id" ON "movie" ("imdb_id");
+CREATE INDEX "movie_idx_movie_tmdb_id" ON "movie" ("tmdb_id");
+CREATE INDEX "movie_idx_movie_title_key" ON "movie" ("title_key");
+CREATE TABLE "movie_info" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "release_id" INTEGER NOT NULL,
+  "title" varchar(255) DEFAULT '',
+  "title_key" varchar(255) DEFAULT '',
+  "year" INTEGER DEFAULT 0,
+  "resolution" varchar(16) DEFAULT '',
+  "source" varchar(16) DEFAULT '',
+  "codec" varchar(16) DEFAULT '',
+  "release_group" varchar(255) DEFAULT '',
+  "movie_id" INTEGER
+);
+CREATE UNIQUE INDEX "movie_info_idx_movie_info_release_id" ON "movie_info" ("release_id");
+CREATE INDEX "movie_info_idx_movie_info_title_key" ON "movie_info" ("title_key");
+CREATE INDEX "movie_info_idx_movie_info_movie_id" ON "movie_info" ("movie_id");
@@ -0,0 +1,4 @@
+DROP INDEX "music_info_idx_music_info_album_key";
+DROP INDEX "music_info_idx_music_info_artist_key";
+DROP INDEX "music_info_idx_music_info_release_id";
+DROP TABLE "music_info";
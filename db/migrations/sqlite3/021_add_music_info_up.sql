@@ -0,0 +1,15 @@
+CREATE TABLE "music_info" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "release_id" INTEGER NOT NULL,
+  "artist" varchar(255) DEFAULT '',
+  "artist_key" varchar(255) DEFAULT '',
+  "album" varchar(255) DEFAULT '',
+  "album_key" varchar(255) DEFAULT '',
+  "year" INTEGER DEFAULT 0,
+  "format" varchar(16) DEFAULT '',
+  "bitrate" varchar(16) DEFAULT '',
+  "release_group" varchar(255) DEFAULT ''
+);
+CREATE UNIQUE INDEX "music_info_idx_music_info_release_id" ON "music_info" ("release_id");
+CREATE INDEX "music_info_idx_music_info_artist_key" ON "music_info" ("artist_key", "album_key");
+CREATE INDEX "music_info_idx_music_info_album_key" ON "music_info" ("album_key");
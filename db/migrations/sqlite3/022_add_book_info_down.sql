@@ -0,0 +1,4 @@
+DROP INDEX "book_info_idx_book_info_title_key";
+DROP INDEX "book_info_idx_book_info_author_key";
+DROP INDEX "book_info_idx_book_info_release_id";
+DROP TABLE "book_info";
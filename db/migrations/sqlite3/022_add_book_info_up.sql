@@ -0,0 +1,13 @@
+CREATE TABLE "book_info" (
+  "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  "release_id" INTEGER NOT NULL,
+  "author" varchar(255) DEFAULT '',
+  "author_key" varchar(255) DEFAULT '',
+  "title" varchar(255) DEFAULT '',
+  "title_key" varchar(255) DEFAULT '',
+  "year" INTEGER DEFAULT 0,
+  "format" varchar(16) DEFAULT ''
+);
+CREATE UNIQUE INDEX "book_info_idx_book_info_release_id" ON "book_info" ("release_id");
+CREATE INDEX "book_info_idx_book_info_author_key" ON "book_info" ("author_key");
+CREATE INDEX "book_info_idx_book_info_title_key" ON "book_info" ("title_key");
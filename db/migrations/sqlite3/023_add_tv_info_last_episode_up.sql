@@ -0,0 +1 @@
+ALTER TABLE "tv_info" ADD last_episode INTEGER DEFAULT 0;
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// findMovieByIDs returns the movie with the IMDB or TMDB ID of m, or nil if
+// there's none.
+func findMovieByIDs(tx *gorm.DB, m *types.Movie) (*types.Movie, error) {
+	ids := []struct {
+		column string
+		id     int64
+	}{
+		{"imdb_id", m.IMDBID},
+		{"tmdb_id", m.TMDBID},
+	}
+	for _, i := range ids {
+		if i.id == 0 {
+			continue
+		}
+		var movie types.Movie
+		err := tx.Where(i.column+" = ?", i.id).First(&movie).Error
+		if err == nil {
+			return &movie, nil
+		}
+		if err != gorm.RecordNotFound {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// SaveMovies adds the given movies.  Movies with an IMDB or TMDB ID that is
+// already known are updated, zero IDs keep what's in the database.  Returns
+// the number of movies added and updated.
+func (d *Handle) SaveMovies(movies []*types.Movie) (int, int, error) {
+	added, updated := 0, 0
+	tx := d.DB.Begin()
+	for _, m := range movies {
+		dbmovie, err := findMovieByIDs(tx, m)
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		if dbmovie != nil {
+			m.ID = dbmovie.ID
+			if m.IMDBID == 0 {
+				m.IMDBID = dbmovie.IMDBID
+			}
+			if m.TMDBID == 0 {
+				m.TMDBID = dbmovie.TMDBID
+			}
+			updated++
+		} else {
+			added++
+		}
+		m.TitleKey = processing.NameKey(m.Title)
+		err = tx.Save(m).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+	}
+	err := tx.Commit().Error
+	return added, updated, err
+}
+
+// CountMovies returns the number of movies in the database.
+func (d *Handle) CountMovies() (int64, error) {
+	var count int64
+	err := d.DB.Model(&types.Movie{}).Count(&count).Error
+	return count, err
+}
+
+// findMovie returns the movie whose title matches the title parsed from a
+// release, or nil if there's none.  When the release has a year the movie
+// must have come out within a year of it, the closest one is returned.
+func findMovie(tx *gorm.DB, title string, year int) (*types.Movie, error) {
+	key := processing.NameKey(title)
+	if key == "" {
+		return nil, nil
+	}
+	var movies []types.Movie
+	err := tx.Where("title_key = ?", key).Order("id").Find(&movies).Error
+	if err != nil || len(movies) == 0 {
+		return nil, err
+	}
+	if year == 0 {
+		return &movies[0], nil
+	}
+	var best *types.Movie
+	for i := range movies {
+		diff := movies[i].Year - year
+		if diff < -1 || diff > 1 {
+			continue
+		}
+		if best == nil || diff == 0 {
+			best = &movies[i]
+		}
+	}
+	return best, nil
+}
+
+// MatchMovies matches the movie info of releases to movies imported after
+// the releases were made, batch releases at a time.  Returns the number of
+// releases checked and matched.
+func (d *Handle) MatchMovies(batch int) (int, int, error) {
+	if batch < 1 {
+		batch = 1000
+	}
+	checked, matched := 0, 0
+	lastID := int64(0)
+	for {
+		var infos []types.MovieInfo
+		err := d.DB.Where("movie_id IS NULL AND id > ?", lastID).Order("id").Limit(batch).Find(&infos).Error
+		if err != nil {
+			return checked, matched, err
+		}
+		if len(infos) == 0 {
+			break
+		}
+		lastID = infos[len(infos)-1].ID
+		for _, info := range infos {
+			checked++
+			movie, err := findMovie(&d.DB, info.Title, info.Year)
+			if err != nil {
+				return checked, matched, err
+			}
+			if movie == nil {
+				continue
+			}
+			err = d.DB.Model(types.MovieInfo{}).Where("id = ?", info.ID).UpdateColumn("movie_id", movie.ID).Error
+			if err != nil {
+				return checked, matched, err
+			}
+			matched++
+		}
+		logrus.Infof("Matched %d of %d releases to movies", matched, checked)
+	}
+	return checked, matched, nil
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestSaveAndMatchMovies(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	movieHD := sql.NullInt64{Int64: int64(types.Movie_HD), Valid: true}
+	early := &types.Release{Name: "The.Martian.2015.1080p.BluRay.x264-SPARKS", Hash: "h1", CategoryID: movieHD}
+	err := dbh.DB.Save(early).Error
+	Expect(err).ToNot(HaveOccurred())
+	checked, parsed, err := dbh.BackfillMovieInfo(10)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(checked).To(Equal(1))
+	Expect(parsed).To(Equal(1))
+	info, err := dbh.GetMovieInfo(early.ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.Title).To(Equal("The Martian"))
+	Expect(info.Year).To(Equal(2015))
+	Expect(info.Resolution).To(Equal("1080p"))
+	Expect(info.ReleaseGroup).To(Equal("SPARKS"))
+	Expect(info.MovieID.Valid).To(BeFalse())
+
+	movies := []*types.Movie{
+		{IMDBID: 3659388, Title: "The Martian", Year: 2015},
+		{IMDBID: 1392190, TMDBID: 76341, Title: "Mad Max: Fury Road", Year: 2015},
+		{IMDBID: 79501, Title: "Mad Max", Year: 1979},
+		{IMDBID: 1234, Title: "Mad Max", Year: 2030},
+	}
+	added, updated, err := dbh.SaveMovies(movies)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(added).To(Equal(4))
+	Expect(updated).To(Equal(0))
+
+	added, updated, err = dbh.SaveMovies([]*types.Movie{{IMDBID: 3659388, TMDBID: 286217, Title: "The Martian", Year: 2015}})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(added).To(Equal(0))
+	Expect(updated).To(Equal(1))
+	count, err := dbh.CountMovies()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(count).To(Equal(int64(4)))
+
+	// Imports without an IMDB ID keep the known one.
+	_, updated, err = dbh.SaveMovies([]*types.Movie{{TMDBID: 76341, Title: "Mad Max: Fury Road", Year: 2015}})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(updated).To(Equal(1))
+	var furyRoad types.Movie
+	err = dbh.DB.First(&furyRoad, movies[1].ID).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(furyRoad.IMDBID).To(Equal(int64(1392190)))
+
+	// Releases made before the import are matched afterwards.
+	checked, matched, err := dbh.MatchMovies(10)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(checked).To(Equal(1))
+	Expect(matched).To(Equal(1))
+	info, err = dbh.GetMovieInfo(early.ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.MovieID.Int64).To(Equal(movies[0].ID))
+
+	// New releases are matched when they're made, the year picks the movie.
+	releases := []*types.Release{
+		{Name: "Mad.Max.Fury.Road.2015.720p.WEB-DL.DD5.1.H264-FGT", Hash: "h2", CategoryID: movieHD},
+		{Name: "Mad.Max.1980.720p.BluRay.x264-GRP", Hash: "h3", CategoryID: movieHD},
+		{Name: "Mad.Max.1999.720p.BluRay.x264-GRP", Hash: "h4", CategoryID: movieHD},
+	}
+	expected := []sql.NullInt64{
+		{Int64: movies[1].ID, Valid: true},
+		{Int64: movies[2].ID, Valid: true},
+		{},
+	}
+	for i, rel := range releases {
+		err = dbh.DB.Save(rel).Error
+		Expect(err).ToNot(HaveOccurred())
+		err = saveReleaseInfo(&dbh.DB, rel.ID, rel.Name, types.Movie_HD)
+		Expect(err).ToNot(HaveOccurred())
+		info, err = dbh.GetMovieInfo(rel.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.MovieID).To(Equal(expected[i]), rel.Name)
+	}
+
+	found, err := dbh.SearchMovieReleases(MovieSearch{IMDBID: 3659388, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(early.ID))
+
+	found, err = dbh.SearchMovieReleases(MovieSearch{TMDBID: 76341, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[0].ID))
+
+	// Movies only known by their IMDB ID match searches with both IDs.
+	found, err = dbh.SearchMovieReleases(MovieSearch{IMDBID: 79501, TMDBID: 9659, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[1].ID))
+
+	found, err = dbh.SearchMovieReleases(MovieSearch{Title: "mad max", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(2))
+
+	found, err = dbh.SearchMovieReleases(MovieSearch{Title: "Mad Max 1980", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[1].ID))
+
+	// Moving a release out of movies drops its movie info.
+	tv := types.TV_HD
+	_, err = dbh.EditRelease(releases[0].ID, ReleaseEdit{Category: &tv})
+	Expect(err).ToNot(HaveOccurred())
+	_, err = dbh.GetMovieInfo(releases[0].ID)
+	Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// parseMovieInfo returns the movie info in the name of a release, or nil if
+// the name can't be parsed.
+func parseMovieInfo(releaseID int64, name string) *types.MovieInfo {
+	res, err := processing.ParseMovieName(name)
+	if err != nil {
+		return nil
+	}
+	return &types.MovieInfo{
+		ReleaseID:    releaseID,
+		Title:        res.Title,
+		TitleKey:     processing.NameKey(res.Title),
+		Year:         res.Year,
+		Resolution:   res.Resolution,
+		Source:       res.Source,
+		Codec:        res.Codec,
+		ReleaseGroup: res.Group,
+	}
+}
+
+// saveMovieInfo replaces the movie info of a release with what can be parsed
+// from its name and links it to the matching movie.  Releases not in a movie
+// category don't get any.  Returns whether movie info was saved.
+func saveMovieInfo(tx *gorm.DB, releaseID int64, name string, cat types.Category) (bool, error) {
+	err := tx.Where("release_id = ?", releaseID).Delete(types.MovieInfo{}).Error
+	if err != nil || cat.Parent() != types.Movies {
+		return false, err
+	}
+	info := parseMovieInfo(releaseID, name)
+	if info == nil {
+		return false, nil
+	}
+	movie, err := findMovie(tx, info.Title, info.Year)
+	if err != nil {
+		return false, err
+	}
+	if movie != nil {
+		info.MovieID = sql.NullInt64{Int64: movie.ID, Valid: true}
+	}
+	return true, tx.Save(info).Error
+}
+
+// BackfillMovieInfo parses the names of movie releases made without movie
+// info, batch releases at a time.  Returns the number of releases checked and
+// the number that got movie info.
+func (d *Handle) BackfillMovieInfo(batch int) (int, int, error) {
+	return d.backfillReleaseInfo(types.Movies, "movie_info", batch, saveMovieInfo)
+}
+
+// GetMovieInfo returns the movie info of a release.
+func (d *Handle) GetMovieInfo(releaseID int64) (*types.MovieInfo, error) {
+	info := &types.MovieInfo{}
+	err := d.DB.Where("release_id = ?", releaseID).First(info).Error
+	return info, err
+}
+
+// MovieSearch selects the releases SearchMovieReleases returns.  Empty fields
+// and zero IDs don't filter.
+type MovieSearch struct {
+	Title      string
+	IMDBID     int64
+	TMDBID     int64
+	Categories []types.Category
+	Offset     int
+	Limit      int
+}
+
+// SearchMovieReleases returns the releases with movie info matching s, newest
+// first.  Hidden releases, duplicates and inactive categories are left out.
+func (d *Handle) SearchMovieReleases(s MovieSearch) ([]types.Release, error) {
+	movieParts := []string{}
+	movieVals := []interface{}{}
+	// Movies imported from one dump only have its ID, so either of them
+	// matches.
+	idParts := []string{}
+	if s.IMDBID != 0 {
+		idParts = append(idParts, "imdb_id = ?")
+		movieVals = append(movieVals, s.IMDBID)
+	}
+	if s.TMDBID != 0 {
+		idParts = append(idParts, "tmdb_id = ?")
+		movieVals = append(movieVals, s.TMDBID)
+	}
+	if len(idParts) > 0 {
+		movieParts = append(movieParts, "movie_id IN (SELECT id FROM movie WHERE "+strings.Join(idParts, " OR ")+")")
+	}
+	// Titles like The Martian 2015 also filter on the year.
+	if s.Title != "" && len(movieParts) == 0 {
+		title, year := s.Title, 0
+		if res, err := processing.ParseMovieName(s.Title); err == nil {
+			title, year = res.Title, res.Year
+		}
+		movieParts = append(movieParts, "title_key = ?")
+		movieVals = append(movieVals, processing.NameKey(title))
+		if year != 0 {
+			movieParts = append(movieParts, "year = ?")
+			movieVals = append(movieVals, year)
+		}
+	}
+	return d.searchInfoReleases("movie_info", movieParts, movieVals, "", s.Categories, s.Offset, s.Limit)
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// parseMusicInfo returns the music info in the name of a release, or nil if
+// the name can't be parsed.
+func parseMusicInfo(releaseID int64, name string) *types.MusicInfo {
+	res, err := processing.ParseMusicName(name)
+	if err != nil {
+		return nil
+	}
+	return &types.MusicInfo{
+		ReleaseID:    releaseID,
+		Artist:       res.Artist,
+		ArtistKey:    processing.NameKey(res.Artist),
+		Album:        res.Album,
+		AlbumKey:     processing.NameKey(res.Album),
+		Year:         res.Year,
+		Format:       res.Format,
+		Bitrate:      res.Bitrate,
+		ReleaseGroup: res.Group,
+	}
+}
+
+// saveMusicInfo replaces the music info of a release with what can be parsed
+// from its name.  Releases not in an audio category and audiobooks don't get
+// any.  Returns whether music info was saved.
+func saveMusicInfo(tx *gorm.DB, releaseID int64, name string, cat types.Category) (bool, error) {
+	err := tx.Where("release_id = ?", releaseID).Delete(types.MusicInfo{}).Error
+	if err != nil || cat.Parent() != types.Audio || cat == types.Audio_Audiobook {
+		return false, err
+	}
+	info := parseMusicInfo(releaseID, name)
+	if info == nil {
+		return false, nil
+	}
+	return true, tx.Save(info).Error
+}
+
+// BackfillMusicInfo parses the names of audio releases made without music
+// info, batch releases at a time.  Returns the number of releases checked and
+// the number that got music info.
+func (d *Handle) BackfillMusicInfo(batch int) (int, int, error) {
+	return d.backfillReleaseInfo(types.Audio, "music_info", batch, saveMusicInfo)
+}
+
+// GetMusicInfo returns the music info of a release.
+func (d *Handle) GetMusicInfo(releaseID int64) (*types.MusicInfo, error) {
+	info := &types.MusicInfo{}
+	err := d.DB.Where("release_id = ?", releaseID).First(info).Error
+	return info, err
+}
+
+// MusicSearch selects the releases SearchMusicReleases returns.  Empty fields
+// don't filter.
+type MusicSearch struct {
+	Query      string // Matched against the search name like SearchReleases.
+	Artist     string
+	Album      string
+	Year       int
+	Categories []types.Category
+	Offset     int
+	Limit      int
+}
+
+// SearchMusicReleases returns the releases with music info matching s,
+// newest first.  Hidden releases, duplicates and inactive categories are
+// left out.
+func (d *Handle) SearchMusicReleases(s MusicSearch) ([]types.Release, error) {
+	musicParts := []string{}
+	musicVals := []interface{}{}
+	if s.Artist != "" {
+		musicParts = append(musicParts, "artist_key = ?")
+		musicVals = append(musicVals, processing.NameKey(s.Artist))
+	}
+	if s.Album != "" {
+		musicParts = append(musicParts, "album_key = ?")
+		musicVals = append(musicVals, processing.NameKey(s.Album))
+	}
+	if s.Year != 0 {
+		musicParts = append(musicParts, "year = ?")
+		musicVals = append(musicVals, s.Year)
+	}
+	return d.searchInfoReleases("music_info", musicParts, musicVals, s.Query, s.Categories, s.Offset, s.Limit)
+}
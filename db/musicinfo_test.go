@@ -0,0 +1,60 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestBackfillAndSearchMusicInfo(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	releases := []*types.Release{
+		{Name: "Adele - 25 (2015) [FLAC]", SearchName: "Adele - 25 (2015) [FLAC]", Hash: "h1", CategoryID: sql.NullInt64{Int64: int64(types.Audio_Lossless), Valid: true}},
+		{Name: "Adele-25-WEB-2015-GRP", SearchName: "Adele-25-WEB-2015-GRP", Hash: "h2", CategoryID: sql.NullInt64{Int64: int64(types.Audio_MP3), Valid: true}},
+		{Name: "Taylor_Swift-1989-2014-GRP", SearchName: "Taylor_Swift-1989-2014-GRP", Hash: "h3", CategoryID: sql.NullInt64{Int64: int64(types.Audio_MP3), Valid: true}},
+		{Name: "Stephen King - It (2016) [M4B]", SearchName: "Stephen King - It (2016) [M4B]", Hash: "h4", CategoryID: sql.NullInt64{Int64: int64(types.Audio_Audiobook), Valid: true}},
+	}
+	for _, rel := range releases {
+		err := dbh.DB.Save(rel).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	// Audiobooks are checked with the rest of the audio releases.
+	checked, parsed, err := dbh.BackfillMusicInfo(2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(checked).To(Equal(4))
+	Expect(parsed).To(Equal(3))
+
+	info, err := dbh.GetMusicInfo(releases[2].ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.Artist).To(Equal("Taylor Swift"))
+	Expect(info.Album).To(Equal("1989"))
+	Expect(info.Year).To(Equal(2014))
+	Expect(info.Format).To(Equal("MP3"))
+
+	// Audiobooks don't get music info.
+	_, err = dbh.GetMusicInfo(releases[3].ID)
+	Expect(err).To(HaveOccurred())
+
+	found, err := dbh.SearchMusicReleases(MusicSearch{Artist: "adele", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(2))
+
+	found, err = dbh.SearchMusicReleases(MusicSearch{Artist: "Adele", Album: "25", Categories: []types.Category{types.Audio_Lossless}, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[0].ID))
+
+	found, err = dbh.SearchMusicReleases(MusicSearch{Year: 2014, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[2].ID))
+
+	found, err = dbh.SearchMusicReleases(MusicSearch{Query: "swift", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+}
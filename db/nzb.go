@@ -0,0 +1,246 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+func compressNZB(doc string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(doc))
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	return buf.Bytes(), err
+}
+
+func decompressNZB(data []byte) (string, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	doc, err := ioutil.ReadAll(r)
+	return string(doc), err
+}
+
+// nzbPath returns where the NZB with the given hash is stored in dir.  NZBs
+// are spread over subdirectories named after the start of their hash.
+func nzbPath(dir, hash string) string {
+	return filepath.Join(dir, hash[:2], hash[2:4], hash+".nzb.gz")
+}
+
+// writeNZBFile writes a compressed NZB to dir unless it's already there.
+func writeNZBFile(dir, hash string, data []byte) error {
+	p := nzbPath(dir, hash)
+	if _, err := os.Stat(p); err == nil {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so readers never see half an NZB.
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".nzb")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	err = os.Chmod(tmp.Name(), 0644)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// saveNZB compresses the NZB of a release and stores it in NZBDir, or the
+// release_nzb table if that isn't set.  Returns the hash of the file written
+// to NZBDir, which has to be passed to rollbackNZBs if tx is rolled back.
+func (d *Handle) saveNZB(tx *gorm.DB, releaseID int64, doc string) (string, error) {
+	data, err := compressNZB(doc)
+	if err != nil {
+		return "", err
+	}
+	rec := types.ReleaseNZB{}
+	err = tx.Where("release_id = ?", releaseID).First(&rec).Error
+	if err != nil && err != gorm.RecordNotFound {
+		return "", err
+	}
+	rec.ReleaseID = releaseID
+	rec.Hash = makeShaHash(doc)
+	rec.Size = int64(len(doc))
+	rec.Data = data
+	file := ""
+	if d.NZBDir != "" {
+		err = writeNZBFile(d.NZBDir, rec.Hash, data)
+		if err != nil {
+			return "", err
+		}
+		file = rec.Hash
+		rec.Data = nil
+	}
+	return file, tx.Save(&rec).Error
+}
+
+// rollbackNZBs rolls back tx and removes the NZB files written by saveNZB in
+// it that no release uses.
+func (d *Handle) rollbackNZBs(tx *gorm.DB, hashes ...string) {
+	tx.Rollback()
+	err := d.removeNZBFiles(hashes...)
+	if err != nil {
+		logrus.Errorf("Error removing NZB files of a rolled back transaction: %v", err)
+	}
+}
+
+// GetNZB returns the uncompressed NZB of a release.
+func (d *Handle) GetNZB(rel *types.Release) (string, error) {
+	if rel.NZB != "" {
+		return rel.NZB, nil
+	}
+	rec := types.ReleaseNZB{}
+	err := d.DB.Where("release_id = ?", rel.ID).First(&rec).Error
+	if err != nil {
+		return "", err
+	}
+	data := rec.Data
+	if len(data) == 0 {
+		if d.NZBDir == "" {
+			return "", fmt.Errorf("NZB of release %d is stored on disk but no NZB directory is configured", rel.ID)
+		}
+		data, err = ioutil.ReadFile(nzbPath(d.NZBDir, rec.Hash))
+		if err != nil {
+			return "", err
+		}
+	}
+	return decompressNZB(data)
+}
+
+// deleteNZB deletes the NZB of a release.  If it was stored in NZBDir its
+// hash is returned so the file can be removed with removeNZBFiles after tx
+// commits.
+func (d *Handle) deleteNZB(tx *gorm.DB, releaseID int64) (string, error) {
+	rec := types.ReleaseNZB{}
+	err := tx.Where("release_id = ?", releaseID).First(&rec).Error
+	if err == gorm.RecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	err = tx.Delete(&rec).Error
+	if err != nil || len(rec.Data) > 0 || d.NZBDir == "" {
+		return "", err
+	}
+	return rec.Hash, nil
+}
+
+// removeNZBFiles removes the NZB files with the given hashes from NZBDir.
+// Files a release still uses are left alone.
+func (d *Handle) removeNZBFiles(hashes ...string) error {
+	for _, h := range hashes {
+		if h == "" {
+			continue
+		}
+		var users int
+		err := d.DB.Model(types.ReleaseNZB{}).Where("hash = ?", h).Count(&users).Error
+		if err != nil {
+			return err
+		}
+		if users > 0 {
+			continue
+		}
+		err = os.Remove(nzbPath(d.NZBDir, h))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertNZBs compresses up to batch NZBs still stored in the release table
+// at a time until none are left.  If NZBDir is set NZBs compressed in the
+// release_nzb table are moved there as well.  Returns the number of NZBs
+// converted.
+func (d *Handle) ConvertNZBs(batch int) (int, error) {
+	if batch < 1 {
+		batch = 100
+	}
+	converted := 0
+	for {
+		var releases []types.Release
+		err := d.DB.Select("id, nzb").Where("nzb != ?", "").Order("id").Limit(batch).Find(&releases).Error
+		if err != nil {
+			return converted, err
+		}
+		if len(releases) == 0 {
+			break
+		}
+		tx := d.DB.Begin()
+		files := make([]string, 0, len(releases))
+		for _, rel := range releases {
+			file, err := d.saveNZB(tx, rel.ID, rel.NZB)
+			if err != nil {
+				d.rollbackNZBs(tx, files...)
+				return converted, err
+			}
+			files = append(files, file)
+			err = tx.Model(types.Release{}).Where("id = ?", rel.ID).UpdateColumn("nzb", "").Error
+			if err != nil {
+				d.rollbackNZBs(tx, files...)
+				return converted, err
+			}
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			d.rollbackNZBs(tx, files...)
+			return converted, err
+		}
+		converted += len(releases)
+		logrus.Infof("Compressed %d NZBs", converted)
+	}
+
+	if d.NZBDir == "" {
+		return converted, nil
+	}
+	for {
+		var recs []types.ReleaseNZB
+		err := d.DB.Where("length(data) > 0").Order("id").Limit(batch).Find(&recs).Error
+		if err != nil {
+			return converted, err
+		}
+		if len(recs) == 0 {
+			break
+		}
+		for _, rec := range recs {
+			err = writeNZBFile(d.NZBDir, rec.Hash, rec.Data)
+			if err != nil {
+				return converted, err
+			}
+			err = d.DB.Model(types.ReleaseNZB{}).Where("id = ?", rec.ID).UpdateColumn("data", []byte{}).Error
+			if err != nil {
+				return converted, err
+			}
+		}
+		converted += len(recs)
+		logrus.Infof("Moved %d NZBs to %s", len(recs), d.NZBDir)
+	}
+	return converted, nil
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+const testNZB = `<?xml version="1.0" encoding="UTF-8"?><nzb><file subject="test"></file></nzb>`
+
+func TestNZBStorage(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	rel := types.Release{Name: "r1", Hash: "h1"}
+	err := dbh.DB.Save(&rel).Error
+	Expect(err).ToNot(HaveOccurred())
+	_, err = dbh.saveNZB(&dbh.DB, rel.ID, testNZB)
+	Expect(err).ToNot(HaveOccurred())
+
+	var rec types.ReleaseNZB
+	err = dbh.DB.Where("release_id = ?", rel.ID).First(&rec).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rec.Data).ToNot(BeEmpty())
+	Expect(rec.Size).To(Equal(int64(len(testNZB))))
+
+	doc, err := dbh.GetNZB(&rel)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(doc).To(Equal(testNZB))
+
+	// Stored on disk when a directory is configured.
+	dir, err := ioutil.TempDir("", "gonab-nzb")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	dbh.NZBDir = dir
+
+	rel2 := types.Release{Name: "r2", Hash: "h2"}
+	err = dbh.DB.Save(&rel2).Error
+	Expect(err).ToNot(HaveOccurred())
+	_, err = dbh.saveNZB(&dbh.DB, rel2.ID, testNZB)
+	Expect(err).ToNot(HaveOccurred())
+	_, err = os.Stat(nzbPath(dir, makeShaHash(testNZB)))
+	Expect(err).ToNot(HaveOccurred())
+	doc, err = dbh.GetNZB(&rel2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(doc).To(Equal(testNZB))
+
+	// Both releases still find their NZB after deleting one.
+	err = dbh.DeleteRelease(rel2.ID)
+	Expect(err).ToNot(HaveOccurred())
+	doc, err = dbh.GetNZB(&rel)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(doc).To(Equal(testNZB))
+}
+
+func TestConvertNZBs(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	for _, h := range []string{"h1", "h2", "h3"} {
+		err := dbh.DB.Save(&types.Release{Name: h, Hash: h, NZB: testNZB + h}).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	converted, err := dbh.ConvertNZBs(2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(converted).To(Equal(3))
+
+	var rels []types.Release
+	err = dbh.DB.Order("id").Find(&rels).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rels).To(HaveLen(3))
+	for _, rel := range rels {
+		Expect(rel.NZB).To(BeEmpty())
+		doc, err := dbh.GetNZB(&rel)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(doc).To(Equal(testNZB + rel.Hash))
+	}
+
+	dir, err := ioutil.TempDir("", "gonab-nzb")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	dbh.NZBDir = dir
+
+	converted, err = dbh.ConvertNZBs(2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(converted).To(Equal(3))
+	var onDisk int
+	err = dbh.DB.Model(types.ReleaseNZB{}).Where("length(data) > 0").Count(&onDisk).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(onDisk).To(Equal(0))
+	doc, err := dbh.GetNZB(&rels[0])
+	Expect(err).ToNot(HaveOccurred())
+	Expect(doc).To(Equal(testNZB + "h1"))
+}
+
+func TestNZBFileRollback(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+	dir, err := ioutil.TempDir("", "gonab-nzb")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	dbh.NZBDir = dir
+
+	rel := types.Release{Name: "r1", Hash: "h1"}
+	err = dbh.DB.Save(&rel).Error
+	Expect(err).ToNot(HaveOccurred())
+	tx := dbh.DB.Begin()
+	file, err := dbh.saveNZB(tx, rel.ID, testNZB)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(file).To(Equal(makeShaHash(testNZB)))
+	dbh.rollbackNZBs(tx, file)
+	_, err = os.Stat(nzbPath(dir, file))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// Files of NZBs another release already uses stay.
+	_, err = dbh.saveNZB(&dbh.DB, rel.ID, testNZB)
+	Expect(err).ToNot(HaveOccurred())
+	rel2 := types.Release{Name: "r2", Hash: "h2"}
+	err = dbh.DB.Save(&rel2).Error
+	Expect(err).ToNot(HaveOccurred())
+	tx = dbh.DB.Begin()
+	file, err = dbh.saveNZB(tx, rel2.ID, testNZB)
+	Expect(err).ToNot(HaveOccurred())
+	dbh.rollbackNZBs(tx, file)
+	doc, err := dbh.GetNZB(&rel)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(doc).To(Equal(testNZB))
+}
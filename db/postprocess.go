@@ -0,0 +1,129 @@
+package db
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// StageStats counts what running a post processing stage did.
+type StageStats struct {
+	Stage    string
+	Advanced int
+	Retried  int
+	Failed   int
+	Hidden   int
+}
+
+// GetReleasesInState returns up to limit releases in the given state with an
+// id above afterID, oldest first.  Releases whose status was set by hand are
+// skipped.
+func (d *Handle) GetReleasesInState(status int, afterID int64, limit int) ([]types.Release, error) {
+	var releases []types.Release
+	err := d.DB.Where("status = ? AND status_locked = ? AND id > ?", status, false, afterID).Order("id").Limit(limit).Find(&releases).Error
+	return releases, err
+}
+
+// GetReleaseStages returns the stage records of a release.
+func (d *Handle) GetReleaseStages(releaseID int64) ([]types.ReleaseStage, error) {
+	var stages []types.ReleaseStage
+	err := d.DB.Where("release_id = ?", releaseID).Order("id").Find(&stages).Error
+	return stages, err
+}
+
+// RunStage runs stage once on every release waiting for it, batch releases at
+// a time.  Releases the stage fails on are retried on later runs until
+// maxAttempts is reached and then marked failed.
+func (d *Handle) RunStage(stage processing.Stage, batch, maxAttempts int) (*StageStats, error) {
+	if batch < 1 {
+		batch = 100
+	}
+	stats := &StageStats{Stage: stage.Name}
+	lastID := int64(0)
+	for {
+		releases, err := d.GetReleasesInState(stage.From, lastID, batch)
+		if err != nil {
+			return stats, err
+		}
+		if len(releases) == 0 {
+			break
+		}
+		// Releases left waiting to be retried are only tried again on the
+		// next run.
+		lastID = releases[len(releases)-1].ID
+		err = d.runStageBatch(stage, releases, maxAttempts, stats)
+		if err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+func (d *Handle) runStageBatch(stage processing.Stage, releases []types.Release, maxAttempts int, stats *StageStats) error {
+	for i := range releases {
+		rel := &releases[i]
+		rec := types.ReleaseStage{}
+		err := d.DB.Where("release_id = ? AND stage = ?", rel.ID, stage.Name).First(&rec).Error
+		if err != nil && err != gorm.RecordNotFound {
+			return err
+		}
+		rec.ReleaseID = rel.ID
+		rec.Stage = stage.Name
+		rec.Attempts++
+		rec.LastAttemptAt = time.Now()
+
+		status := stage.To
+		// Stages needing the NZB fail on releases without one.
+		result := ""
+		rel.NZB, err = d.GetNZB(rel)
+		runErr := err
+		if err == nil || err == gorm.RecordNotFound {
+			result, runErr = stage.Run(rel)
+		}
+		switch e := runErr.(type) {
+		case nil:
+			rec.CompletedAt = rec.LastAttemptAt
+			rec.Result = result
+			stats.Advanced++
+		case *processing.HideError:
+			rec.Result = e.Reason
+			status = types.ReleaseHidden
+			stats.Hidden++
+			logrus.Infof("Hiding release %d (%s) in stage %s: %s", rel.ID, rel.Name, stage.Name, e.Reason)
+		default:
+			rec.Result = runErr.Error()
+			status = stage.From
+			if rec.Attempts >= maxAttempts {
+				status = types.ReleaseFailed
+				stats.Failed++
+				logrus.Errorf("Release %d (%s) failed stage %s after %d attempts: %v", rel.ID, rel.Name, stage.Name, rec.Attempts, runErr)
+			} else {
+				stats.Retried++
+			}
+		}
+
+		tx := d.DB.Begin()
+		err = tx.Save(&rec).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if status == types.ReleaseHidden || status == types.ReleaseFailed {
+			err = promoteDuplicate(tx, rel.ID)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		err = tx.Model(types.Release{}).Where("id = ?", rel.ID).UpdateColumn("status", status).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		tx.Commit()
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunStage(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	names := []string{"good", "flaky", "bad"}
+	for _, n := range names {
+		err := dbh.DB.Save(&types.Release{Name: n, SearchName: n, Hash: n}).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+	stage := processing.Stage{
+		Name: "test",
+		From: types.ReleaseNew,
+		To:   types.ReleaseNFOChecked,
+		Run: func(rel *types.Release) (string, error) {
+			switch rel.Name {
+			case "flaky":
+				return "", fmt.Errorf("try again")
+			case "bad":
+				return "", &processing.HideError{Reason: "bad"}
+			}
+			return "fine", nil
+		},
+	}
+
+	stats, err := dbh.RunStage(stage, 10, 2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(*stats).To(Equal(StageStats{Stage: "test", Advanced: 1, Retried: 1, Hidden: 1}))
+
+	stats, err = dbh.RunStage(stage, 10, 2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(*stats).To(Equal(StageStats{Stage: "test", Failed: 1}))
+
+	statuses := map[string]int{}
+	var rels []types.Release
+	err = dbh.DB.Find(&rels).Error
+	Expect(err).ToNot(HaveOccurred())
+	for _, r := range rels {
+		statuses[r.Name] = r.Status
+	}
+	Expect(statuses).To(Equal(map[string]int{
+		"good":  types.ReleaseNFOChecked,
+		"flaky": types.ReleaseFailed,
+		"bad":   types.ReleaseHidden,
+	}))
+
+	recs, err := dbh.GetReleaseStages(rels[1].ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(recs).To(HaveLen(1))
+	Expect(recs[0].Attempts).To(Equal(2))
+	Expect(recs[0].Result).To(Equal("try again"))
+	Expect(recs[0].CompletedAt.IsZero()).To(BeTrue())
+
+	found, err := dbh.SearchReleases("", 0, 10, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].Name).To(Equal("good"))
+}
+
+func TestRunStageRetriesOncePerRun(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	names := []string{"flaky", "flaky", "good", "good"}
+	for i, n := range names {
+		err := dbh.DB.Save(&types.Release{Name: n, SearchName: n, Hash: fmt.Sprintf("%s%d", n, i)}).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+	stage := processing.Stage{
+		Name: "test",
+		From: types.ReleaseNew,
+		To:   types.ReleaseNFOChecked,
+		Run: func(rel *types.Release) (string, error) {
+			if rel.Name == "flaky" {
+				return "", fmt.Errorf("try again")
+			}
+			return "fine", nil
+		},
+	}
+
+	// With one release per batch the retried releases come first and would
+	// fill every batch if they were picked again.
+	stats, err := dbh.RunStage(stage, 1, 3)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(*stats).To(Equal(StageStats{Stage: "test", Advanced: 2, Retried: 2}))
+
+	var rels []types.Release
+	err = dbh.DB.Order("id").Find(&rels).Error
+	Expect(err).ToNot(HaveOccurred())
+	for _, r := range rels {
+		if r.Name == "good" {
+			Expect(r.Status).To(Equal(types.ReleaseNFOChecked))
+			continue
+		}
+		Expect(r.Status).To(Equal(types.ReleaseNew))
+		recs, err := dbh.GetReleaseStages(r.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recs).To(HaveLen(1))
+		Expect(recs[0].Attempts).To(Equal(1))
+	}
+}
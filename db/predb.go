@@ -0,0 +1,58 @@
+package db
+
+import (
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// FindPreDBByTitle returns the pre with exactly the given title.
+func (d *Handle) FindPreDBByTitle(title string) (*types.PreDB, error) {
+	var pre types.PreDB
+	err := d.DB.Where("title = ?", title).First(&pre).Error
+	return &pre, err
+}
+
+// SavePreDBs adds the given pres, updating the ones with a title that is
+// already known.  Releases named from an updated pre get its nuke status.
+// Returns the number of pres added and updated.
+func (d *Handle) SavePreDBs(pres []*types.PreDB) (int, int, error) {
+	added, updated := 0, 0
+	tx := d.DB.Begin()
+	for _, p := range pres {
+		var dbpre types.PreDB
+		err := tx.Where("title = ?", p.Title).First(&dbpre).Error
+		if err != nil && err != gorm.RecordNotFound {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		if err == nil {
+			p.ID = dbpre.ID
+		}
+		err = tx.Save(p).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		if dbpre.ID == 0 {
+			added++
+			continue
+		}
+		updated++
+		if dbpre.Nuked != p.Nuked {
+			err = tx.Model(types.Release{}).Where("predb_id = ?", p.ID).UpdateColumn("nuked", p.Nuked).Error
+			if err != nil {
+				tx.Rollback()
+				return 0, 0, err
+			}
+		}
+	}
+	err := tx.Commit().Error
+	return added, updated, err
+}
+
+// CountPreDBs returns the number of pres in the database.
+func (d *Handle) CountPreDBs() (int64, error) {
+	var count int64
+	err := d.DB.Model(&types.PreDB{}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestSavePreDBs(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	pres := []*types.PreDB{
+		{Title: "Some.Show.S01E01.720p.HDTV.x264-GRP", Category: "TV-X264"},
+		{Title: "Some.Movie.2015.1080p.BluRay.x264-GRP", Category: "X264"},
+	}
+	added, updated, err := dbh.SavePreDBs(pres)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(added).To(Equal(2))
+	Expect(updated).To(Equal(0))
+
+	rel := types.Release{
+		Name:    "Some.Movie.2015.1080p.BluRay.x264-GRP",
+		Hash:    "somehash",
+		PreDBID: sql.NullInt64{Int64: pres[1].ID, Valid: true},
+	}
+	err = dbh.DB.Save(&rel).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	nuked := []*types.PreDB{
+		{Title: "Some.Movie.2015.1080p.BluRay.x264-GRP", Category: "X264", Nuked: true, NukeReason: "mislabeled"},
+	}
+	added, updated, err = dbh.SavePreDBs(nuked)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(added).To(Equal(0))
+	Expect(updated).To(Equal(1))
+
+	count, err := dbh.CountPreDBs()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(count).To(Equal(int64(2)))
+
+	pre, err := dbh.FindPreDBByTitle("Some.Movie.2015.1080p.BluRay.x264-GRP")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(pre.Nuked).To(BeTrue())
+	Expect(pre.NukeReason).To(Equal("mislabeled"))
+
+	var dbrel types.Release
+	err = dbh.DB.First(&dbrel, rel.ID).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dbrel.Nuked).To(BeTrue())
+}
+
+func TestResolveFromPreDB(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	_, _, err := dbh.SavePreDBs([]*types.PreDB{
+		{Title: "Some.Show.S01E01.720p.HDTV.x264-GRP", Category: "TV-X264"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	cleaner, err := NewReleaseCleaner([]*types.Regex{})
+	Expect(err).ToNot(HaveOccurred())
+	cleaner.PreDB = dbh
+
+	subj := `[01/20] - "Some.Show.S01E01.720p.HDTV.x264-GRP.part01.rar" yEnc`
+	cleaned, err := cleaner.Resolve(subj, "", "alt.binaries.test", 0)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cleaned.PreDB).ToNot(BeNil())
+	Expect(cleaned.Name).To(Equal("Some.Show.S01E01.720p.HDTV.x264-GRP"))
+	Expect(categorizeRelease(nil, cleaned.Name, "alt.binaries.test", 0, cleaned.PreDB)).To(Equal(types.TV_HD))
+
+	cleaned, err = cleaner.Resolve(`"Unknown.Thing.2016-NOPE.rar" yEnc`, "", "alt.binaries.test", 0)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cleaned.PreDB).To(BeNil())
+}
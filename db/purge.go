@@ -0,0 +1,227 @@
+package db
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// PurgeOptions controls what Purge deletes.
+type PurgeOptions struct {
+	// Binaries, parts and missed messages added before this are deleted.
+	PartsBefore time.Time
+	// Releases posted before this are deleted.  Zero skips releases.
+	ReleasesBefore time.Time
+	// Number of rows to delete per transaction.
+	BatchSize int
+	// Only count what would be deleted.
+	DryRun bool
+}
+
+// PurgeStats counts the rows deleted (or that would be deleted) by Purge.
+type PurgeStats struct {
+	Binaries       int64
+	Parts          int64
+	Segments       int64
+	MissedMessages int64
+	Releases       int64
+}
+
+// Purge deletes binaries, parts and missed messages that were never turned
+// into releases and releases that are past the provider's retention.  Rows are
+// deleted in batches so no table is locked for long.
+func (d *Handle) Purge(opts PurgeOptions) (*PurgeStats, error) {
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 1000
+	}
+	stats := &PurgeStats{}
+	var err error
+	if opts.DryRun {
+		err = d.countPurgeable(opts, stats)
+		return stats, err
+	}
+
+	err = d.purgeBinaries(opts, stats)
+	if err != nil {
+		return stats, err
+	}
+	err = d.purgeParts(opts, stats)
+	if err != nil {
+		return stats, err
+	}
+	err = d.purgeMissedMessages(opts, stats)
+	if err != nil {
+		return stats, err
+	}
+	err = d.purgeReleases(opts, stats)
+	return stats, err
+}
+
+func (d *Handle) countPurgeable(opts PurgeOptions, stats *PurgeStats) error {
+	err := d.DB.Model(&types.Binary{}).Where("created_at < ?", opts.PartsBefore).Count(&stats.Binaries).Error
+	if err != nil {
+		return err
+	}
+	partWhere := "binary_id IN (SELECT id FROM `binary` WHERE created_at < ?) OR (binary_id IS NULL AND created_at < ?)"
+	err = d.DB.Model(&types.Part{}).Where(partWhere, opts.PartsBefore, opts.PartsBefore).Count(&stats.Parts).Error
+	if err != nil {
+		return err
+	}
+	err = d.DB.Model(&types.Segment{}).Where("part_id IN (SELECT id FROM part WHERE "+partWhere+")", opts.PartsBefore, opts.PartsBefore).Count(&stats.Segments).Error
+	if err != nil {
+		return err
+	}
+	err = d.DB.Model(&types.MissedMessage{}).Where("created_at < ?", opts.PartsBefore).Count(&stats.MissedMessages).Error
+	if err != nil {
+		return err
+	}
+	if !opts.ReleasesBefore.IsZero() {
+		err = d.DB.Model(&types.Release{}).Where("posted < ?", opts.ReleasesBefore).Count(&stats.Releases).Error
+	}
+	return err
+}
+
+// purgeBinaries deletes binaries that were added long ago but never became a
+// release, along with all their parts and segments.  The time the binary was
+// added is used rather than when it was posted so headers of old articles
+// fetched by a scan aren't deleted before they had a chance to be released.
+func (d *Handle) purgeBinaries(opts PurgeOptions, stats *PurgeStats) error {
+	for {
+		var binaryIDs []int64
+		err := d.DB.Model(&types.Binary{}).Where("created_at < ?", opts.PartsBefore).Limit(opts.BatchSize).Pluck("id", &binaryIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(binaryIDs) == 0 {
+			return nil
+		}
+		var partIDs []int64
+		err = d.DB.Model(&types.Part{}).Where("binary_id IN (?)", binaryIDs).Pluck("id", &partIDs).Error
+		if err != nil {
+			return err
+		}
+
+		tx := d.DB.Begin()
+		segments, err := deleteSegmentsAndParts(tx, partIDs)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Where("id IN (?)", binaryIDs).Delete(types.Binary{}).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			return err
+		}
+		stats.Binaries += int64(len(binaryIDs))
+		stats.Parts += int64(len(partIDs))
+		stats.Segments += segments
+		logrus.Infof("Purged %d binaries with %d parts", len(binaryIDs), len(partIDs))
+	}
+}
+
+// purgeParts deletes parts added long ago that never became part of a binary.
+func (d *Handle) purgeParts(opts PurgeOptions, stats *PurgeStats) error {
+	for {
+		var partIDs []int64
+		err := d.DB.Model(&types.Part{}).Where("binary_id IS NULL AND created_at < ?", opts.PartsBefore).Limit(opts.BatchSize).Pluck("id", &partIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(partIDs) == 0 {
+			return nil
+		}
+		tx := d.DB.Begin()
+		segments, err := deleteSegmentsAndParts(tx, partIDs)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			return err
+		}
+		stats.Parts += int64(len(partIDs))
+		stats.Segments += segments
+		logrus.Infof("Purged %d parts without binaries", len(partIDs))
+	}
+}
+
+func (d *Handle) purgeMissedMessages(opts PurgeOptions, stats *PurgeStats) error {
+	for {
+		var ids []int64
+		err := d.DB.Model(&types.MissedMessage{}).Where("created_at < ?", opts.PartsBefore).Limit(opts.BatchSize).Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		err = d.DB.Where("id IN (?)", ids).Delete(types.MissedMessage{}).Error
+		if err != nil {
+			return err
+		}
+		stats.MissedMessages += int64(len(ids))
+		logrus.Infof("Purged %d missed messages", len(ids))
+	}
+}
+
+// purgeReleases deletes releases that are older than the provider retention
+// and can't be downloaded anymore, along with their NZBs and the rest of what
+// DeleteRelease deletes.
+func (d *Handle) purgeReleases(opts PurgeOptions, stats *PurgeStats) error {
+	if opts.ReleasesBefore.IsZero() {
+		return nil
+	}
+	for {
+		var releases []types.Release
+		err := d.DB.Select("id, name").Where("posted < ?", opts.ReleasesBefore).Order("id").Limit(opts.BatchSize).Find(&releases).Error
+		if err != nil {
+			return err
+		}
+		if len(releases) == 0 {
+			return nil
+		}
+		// Unlike DeleteRelease purged releases aren't remembered, they are
+		// too old to be made again.
+		tx := d.DB.Begin()
+		files := make([]string, 0, len(releases))
+		for i := range releases {
+			file, err := d.deleteRelease(tx, &releases[i])
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			files = append(files, file)
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			return err
+		}
+		err = d.removeNZBFiles(files...)
+		if err != nil {
+			return err
+		}
+		stats.Releases += int64(len(releases))
+		logrus.Infof("Purged %d releases past retention", len(releases))
+	}
+}
+
+// deleteSegmentsAndParts deletes the given parts and their segments and
+// returns the number of segments deleted.
+func deleteSegmentsAndParts(tx *gorm.DB, partIDs []int64) (int64, error) {
+	if len(partIDs) == 0 {
+		return 0, nil
+	}
+	res := tx.Where("part_id IN (?)", partIDs).Delete(types.Segment{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	err := tx.Where("id IN (?)", partIDs).Delete(types.Part{}).Error
+	return res.RowsAffected, err
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestPurge(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+	err := loadFixtures(dbh)
+	if err != nil {
+		t.Fatalf("Error creating fixtures: %v", err)
+	}
+	old := time.Now().AddDate(0, 0, -10)
+	oldPart := types.Part{
+		Hash:          "oldpart",
+		Subject:       `Old Post [01/02] - "old.rar" yEnc`,
+		TotalSegments: 3,
+		Posted:        old,
+		GroupName:     "misc.test",
+		Segments: []types.Segment{
+			{Segment: 1, Size: 1024, MessageID: randString()},
+		},
+	}
+	// Posted long ago but only just fetched, it must be kept.
+	newPart := types.Part{
+		Hash:          "newpart",
+		Subject:       `New Post [01/02] - "new.rar" yEnc`,
+		TotalSegments: 3,
+		Posted:        old,
+		GroupName:     "misc.test",
+		Segments: []types.Segment{
+			{Segment: 1, Size: 1024, MessageID: randString()},
+		},
+	}
+	oldBinary := types.Binary{
+		Hash:      "oldbinary",
+		Name:      "Old Binary",
+		Posted:    old,
+		GroupName: "misc.test",
+		Parts: []types.Part{
+			{
+				Hash:          "oldbinarypart",
+				Subject:       `Old Binary [01/02] - "old.rar" yEnc`,
+				TotalSegments: 1,
+				Posted:        old,
+				GroupName:     "misc.test",
+				Segments: []types.Segment{
+					{Segment: 1, Size: 1024, MessageID: randString()},
+				},
+			},
+		},
+	}
+	for _, p := range []*types.Part{&oldPart, &newPart} {
+		err = dbh.DB.Save(p).Error
+		if err != nil {
+			t.Fatalf("Error saving part: %v", err)
+		}
+	}
+	err = dbh.DB.Save(&oldBinary).Error
+	if err != nil {
+		t.Fatalf("Error saving binary: %v", err)
+	}
+	missed := []types.MissedMessage{
+		{MessageNumber: 1, GroupName: "misc.test", Attempts: 1},
+		{MessageNumber: 2, GroupName: "misc.test", Attempts: 1},
+	}
+	for i := range missed {
+		err = dbh.DB.Save(&missed[i]).Error
+		if err != nil {
+			t.Fatalf("Error saving missed message: %v", err)
+		}
+	}
+	// Saving sets created_at to now, backdate the rows that should be purged.
+	err = dbh.DB.Model(&types.Part{}).Where("hash IN (?)", []string{"oldpart", "oldbinarypart"}).UpdateColumn("created_at", old).Error
+	if err != nil {
+		t.Fatalf("Error updating parts: %v", err)
+	}
+	err = dbh.DB.Model(&types.Binary{}).Where("hash = ?", "oldbinary").UpdateColumn("created_at", old).Error
+	if err != nil {
+		t.Fatalf("Error updating binary: %v", err)
+	}
+	err = dbh.DB.Model(&types.MissedMessage{}).Where("message_number = ?", 1).UpdateColumn("created_at", old).Error
+	if err != nil {
+		t.Fatalf("Error updating missed message: %v", err)
+	}
+	var partCount int64
+	dbh.DB.Model(&types.Part{}).Count(&partCount)
+	dir, err := ioutil.TempDir("", "gonab-nzb")
+	if err != nil {
+		t.Fatalf("Error making NZB dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	dbh.NZBDir = dir
+	oldRelease := types.Release{Name: "Old.Show.S01E01.720p.HDTV.x264-GRP", Posted: old}
+	newRelease := types.Release{Name: "new", Posted: time.Now()}
+	for _, r := range []*types.Release{&oldRelease, &newRelease} {
+		err = dbh.DB.Save(r).Error
+		if err != nil {
+			t.Fatalf("Error saving release: %v", err)
+		}
+	}
+	// Everything hanging off the old release goes with it.
+	_, err = dbh.saveNZB(&dbh.DB, oldRelease.ID, testNZB)
+	if err != nil {
+		t.Fatalf("Error saving NZB: %v", err)
+	}
+	err = saveReleaseInfo(&dbh.DB, oldRelease.ID, oldRelease.Name, types.TV_HD)
+	if err != nil {
+		t.Fatalf("Error saving release info: %v", err)
+	}
+	err = dbh.DB.Save(&types.ReleaseStage{ReleaseID: oldRelease.ID, Stage: "nfo", Attempts: 1}).Error
+	if err != nil {
+		t.Fatalf("Error saving release stage: %v", err)
+	}
+	var infoCount int
+	dbh.DB.Model(types.TVInfo{}).Count(&infoCount)
+	Expect(infoCount).To(Equal(1))
+
+	opts := PurgeOptions{
+		PartsBefore:    time.Now().AddDate(0, 0, -5),
+		ReleasesBefore: time.Now().AddDate(0, 0, -5),
+		BatchSize:      1,
+		DryRun:         true,
+	}
+	stats, err := dbh.Purge(opts)
+	if err != nil {
+		t.Fatalf("Error purging: %v", err)
+	}
+	Expect(stats.Binaries).To(Equal(int64(1)))
+	Expect(stats.Parts).To(Equal(int64(2)))
+	Expect(stats.Segments).To(Equal(int64(2)))
+	Expect(stats.MissedMessages).To(Equal(int64(1)))
+	Expect(stats.Releases).To(Equal(int64(1)))
+
+	var afterCount int64
+	dbh.DB.Model(&types.Part{}).Count(&afterCount)
+	Expect(afterCount).To(Equal(partCount))
+
+	opts.DryRun = false
+	stats, err = dbh.Purge(opts)
+	if err != nil {
+		t.Fatalf("Error purging: %v", err)
+	}
+	Expect(stats.Binaries).To(Equal(int64(1)))
+	Expect(stats.Parts).To(Equal(int64(2)))
+	Expect(stats.MissedMessages).To(Equal(int64(1)))
+	Expect(stats.Releases).To(Equal(int64(1)))
+
+	dbh.DB.Model(&types.Part{}).Count(&afterCount)
+	Expect(afterCount).To(Equal(partCount - 2))
+	var binaryCount int64
+	dbh.DB.Model(&types.Binary{}).Where("hash = ?", "oldbinary").Count(&binaryCount)
+	Expect(binaryCount).To(BeZero())
+	var remaining []types.MissedMessage
+	dbh.DB.Find(&remaining)
+	Expect(remaining).To(HaveLen(1))
+	Expect(remaining[0].MessageNumber).To(Equal(int64(2)))
+	var rels []types.Release
+	err = dbh.DB.Find(&rels).Error
+	if err != nil {
+		t.Fatalf("Error getting releases: %v", err)
+	}
+	Expect(rels).To(HaveLen(1))
+	Expect(rels[0].Name).To(Equal("new"))
+	for _, table := range []interface{}{types.ReleaseNZB{}, types.ReleaseStage{}, types.TVInfo{}} {
+		var count int
+		dbh.DB.Model(table).Count(&count)
+		Expect(count).To(BeZero(), "%T", table)
+	}
+	_, err = os.Stat(nzbPath(dir, makeShaHash(testNZB)))
+	Expect(os.IsNotExist(err)).To(BeTrue())
+	var deleted int
+	dbh.DB.Model(types.DeletedRelease{}).Count(&deleted)
+	Expect(deleted).To(BeZero())
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// RecategorizeOptions selects the releases RecategorizeReleases looks at.
+// Zero values don't filter.
+type RecategorizeOptions struct {
+	Group      string
+	Categories []types.Category
+	From       time.Time // Posted at or after
+	To         time.Time // Posted before
+	DryRun     bool
+	BatchSize  int
+}
+
+// CategoryChange is a move of releases from one category to another.
+type CategoryChange struct {
+	From types.Category
+	To   types.Category
+}
+
+// RecategorizeStats counts what RecategorizeReleases did.
+type RecategorizeStats struct {
+	Checked int
+	Changed int
+	Locked  int // Releases skipped because their category is locked
+	Changes map[CategoryChange]int
+}
+
+// RecategorizeReleases runs the releases selected by opts through the
+// category rules and categorizer again and updates the ones whose category
+// changed, unless opts.DryRun is set.  Releases with a locked category are left alone.
+func (d *Handle) RecategorizeReleases(opts RecategorizeOptions) (*RecategorizeStats, error) {
+	stats := &RecategorizeStats{Changes: map[CategoryChange]int{}}
+	qParts := []string{}
+	vals := []interface{}{}
+	if opts.Group != "" {
+		grp, err := d.FindGroupByName(opts.Group)
+		if err != nil {
+			return stats, err
+		}
+		qParts = append(qParts, "group_id = ?")
+		vals = append(vals, grp.ID)
+	}
+	if len(opts.Categories) > 0 {
+		qParts = append(qParts, "category_id IN (?)")
+		vals = append(vals, categoryIDs(opts.Categories))
+	}
+	if !opts.From.IsZero() {
+		qParts = append(qParts, "posted >= ?")
+		vals = append(vals, opts.From)
+	}
+	if !opts.To.IsZero() {
+		qParts = append(qParts, "posted < ?")
+		vals = append(vals, opts.To)
+	}
+
+	locked := d.DB.Model(types.Release{}).Where("category_locked = ?", true)
+	if len(qParts) > 0 {
+		locked = locked.Where(strings.Join(qParts, " AND "), vals...)
+	}
+	err := locked.Count(&stats.Locked).Error
+	if err != nil {
+		return stats, err
+	}
+
+	rules, err := d.GetCategoryRules(false)
+	if err != nil {
+		return stats, err
+	}
+
+	batch := opts.BatchSize
+	if batch < 1 {
+		batch = 1000
+	}
+	qParts = append(qParts, "category_locked = ?", "id > ?")
+	vals = append(vals, false)
+	q := strings.Join(qParts, " AND ")
+	groupNames := map[int64]string{}
+	lastID := int64(0)
+	for {
+		var releases []types.Release
+		err = d.DB.Select("id, name, size, group_id, category_id, predb_id").Where(q, append(vals, lastID)...).Order("id").Limit(batch).Find(&releases).Error
+		if err != nil {
+			return stats, err
+		}
+		if len(releases) == 0 {
+			break
+		}
+		lastID = releases[len(releases)-1].ID
+
+		moves := map[types.Category][]int64{}
+		moved := []types.Release{}
+		for _, rel := range releases {
+			stats.Checked++
+			groupName, err := d.groupName(rel.GroupID.Int64, groupNames)
+			if err != nil {
+				return stats, err
+			}
+			var pre *types.PreDB
+			if rel.PreDBID.Valid {
+				pre = &types.PreDB{}
+				err = d.DB.First(pre, rel.PreDBID.Int64).Error
+				if err == gorm.RecordNotFound {
+					pre = nil
+				} else if err != nil {
+					return stats, err
+				}
+			}
+			oldCat := rel.CategoryName()
+			newCat := categorizeRelease(rules, rel.Name, groupName, rel.Size, pre)
+			if newCat == oldCat {
+				continue
+			}
+			stats.Changed++
+			stats.Changes[CategoryChange{From: oldCat, To: newCat}]++
+			moves[newCat] = append(moves[newCat], rel.ID)
+			rel.CategoryID = sql.NullInt64{Int64: int64(newCat), Valid: true}
+			moved = append(moved, rel)
+		}
+		if opts.DryRun || len(moves) == 0 {
+			continue
+		}
+		tx := d.DB.Begin()
+		for cat, ids := range moves {
+			err = tx.Model(types.Release{}).Where("id IN (?)", ids).UpdateColumn("category_id", int64(cat)).Error
+			if err != nil {
+				tx.Rollback()
+				return stats, err
+			}
+		}
+		for _, rel := range moved {
+			err = saveReleaseInfo(tx, rel.ID, rel.Name, rel.CategoryName())
+			if err != nil {
+				tx.Rollback()
+				return stats, err
+			}
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// groupName returns the name of the group with the given ID, caching it in
+// names.
+func (d *Handle) groupName(id int64, names map[int64]string) (string, error) {
+	if name, ok := names[id]; ok {
+		return name, nil
+	}
+	var grp types.Group
+	err := d.DB.First(&grp, id).Error
+	if err != nil && err != gorm.RecordNotFound {
+		return "", err
+	}
+	names[id] = grp.Name
+	return grp.Name, nil
+}
+
+// SetCategoryLocked locks or unlocks the category of a release.
+func (d *Handle) SetCategoryLocked(releaseID int64, locked bool) error {
+	var rel types.Release
+	err := d.DB.Select("id").First(&rel, releaseID).Error
+	if err != nil {
+		return err
+	}
+	return d.DB.Model(types.Release{}).Where("id = ?", releaseID).UpdateColumn("category_locked", locked).Error
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestRecategorizeReleases(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	grp := types.Group{Name: "alt.binaries.teevee", Active: true}
+	err := dbh.DB.Save(&grp).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	posted := time.Date(2016, 3, 1, 0, 0, 0, 0, time.Local)
+	name := "Some.Show.S01E01.720p.WEB-DL.DD5.1.H.264-GRP"
+	for _, hash := range []string{"wrong", "locked", "old"} {
+		rel := types.Release{
+			Name:       name,
+			SearchName: name,
+			Hash:       hash,
+			Posted:     posted,
+			GroupID:    sql.NullInt64{Int64: grp.ID, Valid: true},
+			CategoryID: sql.NullInt64{Int64: int64(types.Other_Misc), Valid: true},
+		}
+		if hash == "old" {
+			rel.Posted = posted.AddDate(-1, 0, 0)
+		}
+		err = dbh.DB.Save(&rel).Error
+		Expect(err).ToNot(HaveOccurred())
+		if hash == "locked" {
+			err = dbh.SetCategoryLocked(rel.ID, true)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	}
+	categories := func() map[string]types.Category {
+		var rels []types.Release
+		err := dbh.DB.Find(&rels).Error
+		Expect(err).ToNot(HaveOccurred())
+		cats := map[string]types.Category{}
+		for _, r := range rels {
+			cats[r.Hash] = r.CategoryName()
+		}
+		return cats
+	}
+
+	opts := RecategorizeOptions{
+		Group:  grp.Name,
+		From:   posted.AddDate(0, -1, 0),
+		DryRun: true,
+	}
+	stats, err := dbh.RecategorizeReleases(opts)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(stats.Checked).To(Equal(1))
+	Expect(stats.Changed).To(Equal(1))
+	Expect(stats.Locked).To(Equal(1))
+	Expect(stats.Changes).To(Equal(map[CategoryChange]int{
+		{From: types.Other_Misc, To: types.TV_WEBDL}: 1,
+	}))
+	Expect(categories()).To(Equal(map[string]types.Category{
+		"wrong":  types.Other_Misc,
+		"locked": types.Other_Misc,
+		"old":    types.Other_Misc,
+	}))
+
+	opts.DryRun = false
+	stats, err = dbh.RecategorizeReleases(opts)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(stats.Changed).To(Equal(1))
+	Expect(categories()).To(Equal(map[string]types.Category{
+		"wrong":  types.TV_WEBDL,
+		"locked": types.Other_Misc,
+		"old":    types.Other_Misc,
+	}))
+
+	stats, err = dbh.RecategorizeReleases(opts)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(stats.Checked).To(Equal(1))
+	Expect(stats.Changed).To(Equal(0))
+
+	err = dbh.SetCategoryLocked(12345, true)
+	Expect(err).To(HaveOccurred())
+}
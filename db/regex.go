@@ -0,0 +1,180 @@
+package db
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// Kinds of regex hits that are tracked.
+const (
+	CollectionRegexKind = "collection"
+	ReleaseRegexKind    = "release"
+)
+
+// RegexStat is the hit count of a single regex.
+type RegexStat struct {
+	Kind        string
+	RegexID     int
+	Description string
+	GroupRegex  string
+	Hits        int64
+	LastMatched time.Time
+}
+
+// RegexTrace records how a single regex handled a name.
+type RegexTrace struct {
+	Regex    *types.Regex
+	Matched  bool
+	Captures map[string]string
+	Result   string // Joined captures if Matched
+}
+
+// explainRegexes runs name through every regex whose group regex matches
+// groupname and returns a trace for each of them in the order they would be
+// tried.  The first trace with Matched set is the one Clean would use.
+func explainRegexes(regexes []*types.Regex, name, groupname string) []RegexTrace {
+	traces := []RegexTrace{}
+	for _, r := range regexes {
+		if !r.CompiledGroupRegex.Regex.MatchString(groupname) {
+			continue
+		}
+		t := RegexTrace{Regex: r}
+		m := r.Compiled.FindStringSubmatchMap(name)
+		if len(m) > 0 {
+			t.Matched = true
+			t.Captures = m
+			t.Result = joinNamedMatches(m)
+		}
+		traces = append(traces, t)
+	}
+	return traces
+}
+
+// GetCollectionRegexes returns all regexes used to make binaries from parts.
+func (d *Handle) GetCollectionRegexes() ([]*types.Regex, error) {
+	var regex []*types.Regex
+	err := d.DB.Table("collection_regex").Find(&regex).Error
+	return regex, err
+}
+
+// GetReleaseRegexes returns the enabled regexes used to name releases in the
+// order they are tried.
+func (d *Handle) GetReleaseRegexes() ([]*types.Regex, error) {
+	var regex []*types.Regex
+	err := d.DB.Where("status = ?", true).Order("ordinal, id").Find(&regex).Error
+	return regex, err
+}
+
+// LocalRegexMinID is the lowest ID of regexes added locally.  Importing
+// regexes only replaces the ones below it.
+const LocalRegexMinID = 100000
+
+// AddLocalRegex saves r with the next free local ID.  The kind of r decides
+// the table it goes to.
+func (d *Handle) AddLocalRegex(r *types.Regex) error {
+	err := r.Compile()
+	if err != nil {
+		return err
+	}
+	var ids []int
+	err = d.DB.Table(r.TableName()).Where("id >= ?", LocalRegexMinID).Order("id desc").Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	r.ID = LocalRegexMinID
+	if len(ids) > 0 {
+		r.ID = ids[0] + 1
+	}
+	return d.DB.Create(r).Error
+}
+
+// saveRegexHits adds the given hit counts to the regex_hit table.
+func saveRegexHits(tx *gorm.DB, kind string, hits map[int]int64, matched time.Time) error {
+	for regexID, count := range hits {
+		res := tx.Exec("UPDATE regex_hit SET hits = hits + ?, last_matched = ? WHERE kind = ? AND regex_id = ?", count, matched, kind, regexID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			continue
+		}
+		hit := &types.RegexHit{
+			Kind:        kind,
+			RegexID:     regexID,
+			Hits:        count,
+			LastMatched: matched,
+		}
+		err := tx.Save(hit).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRegexStats returns the hit statistics of every regex of the given kind,
+// including the ones that never matched, sorted by most hits first.  The
+// generic fallback (SubjectCleanerRegexID or NoRegexID) is included if it
+// was ever used.
+func (d *Handle) GetRegexStats(kind string) ([]RegexStat, error) {
+	table := "regex"
+	fallback := "Hard coded cleaners"
+	if kind == CollectionRegexKind {
+		table = "collection_regex"
+		fallback = "Generic subject cleaner"
+	}
+	var regexes []types.Regex
+	err := d.DB.Table(table).Find(&regexes).Error
+	if err != nil {
+		return nil, err
+	}
+	var hits []types.RegexHit
+	err = d.DB.Where("kind = ?", kind).Find(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	hitmap := make(map[int]types.RegexHit, len(hits))
+	for _, h := range hits {
+		hitmap[h.RegexID] = h
+	}
+
+	stats := make([]RegexStat, 0, len(regexes)+1)
+	if h, ok := hitmap[SubjectCleanerRegexID]; ok {
+		stats = append(stats, RegexStat{
+			Kind:        kind,
+			RegexID:     SubjectCleanerRegexID,
+			Description: fallback,
+			Hits:        h.Hits,
+			LastMatched: h.LastMatched,
+		})
+	}
+	for _, r := range regexes {
+		stat := RegexStat{
+			Kind:        kind,
+			RegexID:     r.ID,
+			Description: r.Description,
+			GroupRegex:  r.GroupRegex,
+		}
+		if h, ok := hitmap[r.ID]; ok {
+			stat.Hits = h.Hits
+			stat.LastMatched = h.LastMatched
+		}
+		stats = append(stats, stat)
+	}
+	sort.Sort(regexStatsByHits(stats))
+	return stats, nil
+}
+
+type regexStatsByHits []RegexStat
+
+func (s regexStatsByHits) Len() int      { return len(s) }
+func (s regexStatsByHits) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s regexStatsByHits) Less(i, j int) bool {
+	if s[i].Hits == s[j].Hits {
+		return s[i].RegexID < s[j].RegexID
+	}
+	return s[i].Hits > s[j].Hits
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestRegexStats(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+	err := loadFixtures(dbh)
+	if err != nil {
+		t.Fatalf("Error creating fixtures: %v", err)
+	}
+	used := &types.Regex{
+		Regex:       `(?i)^(?P<name>.*?\]) \[(?P<parts>\d{1,3}\/\d{1,3})`,
+		GroupRegex:  `.*`,
+		Description: "used",
+		Kind:        "collection",
+	}
+	unused := &types.Regex{
+		Regex:       `^nevermatches$`,
+		GroupRegex:  `.*`,
+		Description: "unused",
+		Kind:        "collection",
+	}
+	for _, r := range []*types.Regex{unused, used} {
+		err = dbh.DB.Save(r).Error
+		if err != nil {
+			t.Fatalf("Error saving regex: %v", err)
+		}
+	}
+
+	err = dbh.MakeBinaries()
+	if err != nil {
+		t.Fatalf("Error creating binaries: %v", err)
+	}
+
+	var bin types.Binary
+	err = dbh.DB.First(&bin).Error
+	if err != nil {
+		t.Fatalf("Error getting binary: %v", err)
+	}
+	Expect(bin.RegexID.Int64).To(Equal(int64(used.ID)))
+
+	stats, err := dbh.GetRegexStats(CollectionRegexKind)
+	if err != nil {
+		t.Fatalf("Error getting regex stats: %v", err)
+	}
+	Expect(stats).To(HaveLen(2))
+	Expect(stats[0].RegexID).To(Equal(used.ID))
+	Expect(stats[0].Hits).To(Equal(int64(1)))
+	Expect(stats[1].RegexID).To(Equal(unused.ID))
+	Expect(stats[1].Hits).To(Equal(int64(0)))
+}
+
+func TestGetReleaseRegexes(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	regexes := []*types.Regex{
+		{ID: 1, Regex: `^(?P<name>late)$`, GroupRegex: `.*`, Status: true, Ordinal: 10, Kind: ReleaseRegexKind},
+		{ID: 2, Regex: `^(?P<name>early)$`, GroupRegex: `.*`, Status: true, Ordinal: 5, Kind: ReleaseRegexKind},
+		{ID: 3, Regex: `^(?P<name>off)$`, GroupRegex: `.*`, Status: false, Ordinal: 1, Kind: ReleaseRegexKind},
+	}
+	for _, r := range regexes {
+		err := dbh.DB.Create(r).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+	local := &types.Regex{Regex: `^(?P<name>local)$`, GroupRegex: `.*`, Status: true, Ordinal: 7, Kind: ReleaseRegexKind}
+	err := dbh.AddLocalRegex(local)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(local.ID).To(Equal(LocalRegexMinID))
+	next := &types.Regex{Regex: `^(?P<name>next)$`, GroupRegex: `.*`, Kind: ReleaseRegexKind}
+	err = dbh.AddLocalRegex(next)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(next.ID).To(Equal(LocalRegexMinID + 1))
+
+	got, err := dbh.GetReleaseRegexes()
+	Expect(err).ToNot(HaveOccurred())
+	ids := []int{}
+	for _, r := range got {
+		ids = append(ids, r.ID)
+	}
+	Expect(ids).To(Equal([]int{2, LocalRegexMinID, 1}))
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// ReleaseEdit holds the fields EditRelease changes.  Nil fields are left
+// alone.
+type ReleaseEdit struct {
+	Name       *string
+	SearchName *string
+	Category   *types.Category
+	Hidden     *bool
+}
+
+// EditRelease changes a release by hand and locks the edited fields so
+// recategorizing, renaming and post processing don't overwrite them.  Setting
+// only the name also sets the search name.  Unhiding a hidden or failed
+// release marks it as fully processed.  The status is only locked when the
+// edit changes it.
+func (d *Handle) EditRelease(releaseID int64, edit ReleaseEdit) (*types.Release, error) {
+	rel := &types.Release{}
+	err := d.DB.First(rel, releaseID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if edit.Name != nil {
+		if *edit.Name == "" {
+			return nil, fmt.Errorf("release name can't be empty")
+		}
+		updates["name"] = *edit.Name
+		updates["search_name"] = cleanReleaseName(*edit.Name)
+		updates["name_locked"] = true
+	}
+	if edit.SearchName != nil {
+		updates["search_name"] = *edit.SearchName
+		updates["name_locked"] = true
+	}
+	if edit.Category != nil {
+		cat := *edit.Category
+		if types.CategoryFromInt(int64(cat)) != cat {
+			return nil, fmt.Errorf("unknown category %d", cat)
+		}
+		updates["category_id"] = int64(cat)
+		updates["category_locked"] = true
+	}
+	hide := false
+	if edit.Hidden != nil {
+		switch {
+		case *edit.Hidden && rel.Status != types.ReleaseHidden:
+			hide = true
+			updates["status"] = types.ReleaseHidden
+			updates["status_locked"] = true
+		case !*edit.Hidden && (rel.Status == types.ReleaseHidden || rel.Status == types.ReleaseFailed):
+			updates["status"] = types.ReleaseMetadataMatched
+			updates["status_locked"] = true
+		}
+	}
+	if len(updates) == 0 {
+		return rel, nil
+	}
+
+	tx := d.DB.Begin()
+	if hide {
+		err = promoteDuplicate(tx, rel.ID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	err = tx.Model(types.Release{}).Where("id = ?", releaseID).UpdateColumns(updates).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("Edited release %d (%s): %v", rel.ID, rel.Name, updates)
+	rel = &types.Release{}
+	err = d.DB.First(rel, releaseID).Error
+	if err != nil {
+		return nil, err
+	}
+	if edit.Name != nil || edit.Category != nil {
+		err = saveReleaseInfo(&d.DB, rel.ID, rel.Name, rel.CategoryName())
+	}
+	return rel, err
+}
+
+// DeleteRelease deletes a release, its NZB, TV, movie, music and book info
+// and post processing records.  The hash of the release is remembered so
+// MakeReleases doesn't make it again.
+func (d *Handle) DeleteRelease(releaseID int64) error {
+	var rel types.Release
+	err := d.DB.Select("id, hash, name").First(&rel, releaseID).Error
+	if err != nil {
+		return err
+	}
+
+	tx := d.DB.Begin()
+	file, err := d.deleteRelease(tx, &rel)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	deleted := types.DeletedRelease{}
+	err = tx.Where("hash = ?", rel.Hash).First(&deleted).Error
+	if err != nil && err != gorm.RecordNotFound {
+		tx.Rollback()
+		return err
+	}
+	deleted.Hash = rel.Hash
+	deleted.Name = rel.Name
+	deleted.CreatedAt = time.Now()
+	err = tx.Save(&deleted).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Deleted release %d (%s)", rel.ID, rel.Name)
+	return d.removeNZBFiles(file)
+}
+
+// deleteRelease deletes rel with its NZB, TV, movie, music and book info and
+// post processing records.  Its oldest duplicate takes its place.  Returns
+// the hash of its NZB file to remove with removeNZBFiles once tx commits.
+func (d *Handle) deleteRelease(tx *gorm.DB, rel *types.Release) (string, error) {
+	err := promoteDuplicate(tx, rel.ID)
+	if err != nil {
+		return "", err
+	}
+	err = tx.Where("release_id = ?", rel.ID).Delete(types.ReleaseStage{}).Error
+	if err != nil {
+		return "", err
+	}
+	file, err := d.deleteNZB(tx, rel.ID)
+	if err != nil {
+		return "", err
+	}
+	// Releases without a category have no TV, movie, music or book info.
+	err = saveReleaseInfo(tx, rel.ID, rel.Name, types.Unknown)
+	if err != nil {
+		return "", err
+	}
+	return file, tx.Where("id = ?", rel.ID).Delete(types.Release{}).Error
+}
+
+// releaseDeleted returns true if a release with the given hash was deleted by
+// hand.
+func (d *Handle) releaseDeleted(hash string) (bool, error) {
+	var count int
+	err := d.DB.Model(types.DeletedRelease{}).Where("hash = ?", hash).Count(&count).Error
+	return count > 0, err
+}
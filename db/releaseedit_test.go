@@ -0,0 +1,92 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+	. "github.com/onsi/gomega"
+)
+
+func TestEditRelease(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	rel := types.Release{Name: "bad name", SearchName: "bad name", Hash: "h1", Status: types.ReleaseHidden}
+	err := dbh.DB.Save(&rel).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	name := "Good.Name.2016.720p.BluRay.x264-GRP"
+	cat := types.Movie_HD
+	hidden := false
+	edited, err := dbh.EditRelease(rel.ID, ReleaseEdit{Name: &name, Category: &cat, Hidden: &hidden})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(edited.Name).To(Equal(name))
+	Expect(edited.SearchName).To(Equal(cleanReleaseName(name)))
+	Expect(edited.CategoryName()).To(Equal(types.Movie_HD))
+	Expect(edited.Status).To(Equal(types.ReleaseMetadataMatched))
+	Expect(edited.NameLocked).To(BeTrue())
+	Expect(edited.CategoryLocked).To(BeTrue())
+	Expect(edited.StatusLocked).To(BeTrue())
+
+	// Post processing skips releases whose status was set by hand.
+	err = dbh.DB.Model(types.Release{}).Where("id = ?", rel.ID).UpdateColumn("status", types.ReleaseNew).Error
+	Expect(err).ToNot(HaveOccurred())
+	releases, err := dbh.GetReleasesInState(types.ReleaseNew, 0, 10)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(releases).To(BeEmpty())
+
+	// Edits that don't change the status don't lock it.
+	visible := types.Release{Name: "visible", SearchName: "visible", Hash: "h2", Status: types.ReleaseNFOChecked}
+	err = dbh.DB.Save(&visible).Error
+	Expect(err).ToNot(HaveOccurred())
+	edited, err = dbh.EditRelease(visible.ID, ReleaseEdit{Hidden: &hidden})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(edited.Status).To(Equal(types.ReleaseNFOChecked))
+	Expect(edited.StatusLocked).To(BeFalse())
+
+	hidden = true
+	edited, err = dbh.EditRelease(visible.ID, ReleaseEdit{Hidden: &hidden})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(edited.Status).To(Equal(types.ReleaseHidden))
+	Expect(edited.StatusLocked).To(BeTrue())
+
+	err = dbh.DB.Model(types.Release{}).Where("id = ?", visible.ID).UpdateColumn("status_locked", false).Error
+	Expect(err).ToNot(HaveOccurred())
+	edited, err = dbh.EditRelease(visible.ID, ReleaseEdit{Hidden: &hidden})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(edited.StatusLocked).To(BeFalse())
+
+	bad := types.Category(12345)
+	_, err = dbh.EditRelease(rel.ID, ReleaseEdit{Category: &bad})
+	Expect(err).To(HaveOccurred())
+
+	_, err = dbh.EditRelease(999, ReleaseEdit{Name: &name})
+	Expect(err).To(Equal(gorm.RecordNotFound))
+}
+
+func TestDeleteRelease(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	rel := types.Release{Name: "unwanted", SearchName: "unwanted", Hash: "h1"}
+	err := dbh.DB.Save(&rel).Error
+	Expect(err).ToNot(HaveOccurred())
+	err = dbh.DB.Save(&types.ReleaseStage{ReleaseID: rel.ID, Stage: "nfo"}).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	err = dbh.DeleteRelease(rel.ID)
+	Expect(err).ToNot(HaveOccurred())
+
+	_, err = dbh.FindReleaseByHash("h1")
+	Expect(err).To(Equal(gorm.RecordNotFound))
+	stages, err := dbh.GetReleaseStages(rel.ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(stages).To(BeEmpty())
+	deleted, err := dbh.releaseDeleted("h1")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(deleted).To(BeTrue())
+
+	err = dbh.DeleteRelease(rel.ID)
+	Expect(err).To(Equal(gorm.RecordNotFound))
+}
@@ -3,9 +3,10 @@ package db
 import (
 	"database/sql"
 	"fmt"
-	"sort"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/hobeone/gonab/categorize"
@@ -18,18 +19,30 @@ import (
 // query is matched against the name of the Releases
 // limit limits the number of returned releases to no more than that
 // categories restricts the searched releases to be in those categories
+// Duplicates of other releases are left out.
 func (d *Handle) SearchReleases(query string, offset, limit int, categories []types.Category) ([]types.Release, error) {
-	qParts := []string{}
-	var vals []interface{}
+	return d.searchReleases(query, offset, limit, categories, false)
+}
+
+// SearchAllReleases is SearchReleases including duplicates.
+func (d *Handle) SearchAllReleases(query string, offset, limit int, categories []types.Category) ([]types.Release, error) {
+	return d.searchReleases(query, offset, limit, categories, true)
+}
+
+func (d *Handle) searchReleases(query string, offset, limit int, categories []types.Category, duplicates bool) ([]types.Release, error) {
+	// Hidden and failed releases and inactive categories are never shown.
+	qParts := []string{"status NOT IN (?)", inactiveCategoryQuery}
+	vals := []interface{}{[]int{types.ReleaseHidden, types.ReleaseFailed}, false, false}
+	if !duplicates {
+		qParts = append(qParts, "canonical_id IS NULL")
+	}
 	if query != "" {
 		qParts = append(qParts, "search_name LIKE ?")
 		vals = append(vals, fmt.Sprintf("%%%s%%", query))
 	}
 	if len(categories) > 0 {
 		qParts = append(qParts, "category_id IN (?)")
-		for _, cat := range categories {
-			vals = append(vals, int64(cat))
-		}
+		vals = append(vals, categoryIDs(categories))
 	}
 	q := strings.Join(qParts, " AND ")
 	var releases []types.Release
@@ -37,6 +50,37 @@ func (d *Handle) SearchReleases(query string, offset, limit int, categories []ty
 	return releases, err
 }
 
+// searchInfoReleases is searchReleases for the releases with a row in table,
+// the TV, movie, music or book info, matching infoParts.
+func (d *Handle) searchInfoReleases(table string, infoParts []string, infoVals []interface{}, query string, categories []types.Category, offset, limit int) ([]types.Release, error) {
+	infoQuery := "SELECT release_id FROM " + table
+	if len(infoParts) > 0 {
+		infoQuery += " WHERE " + strings.Join(infoParts, " AND ")
+	}
+	qParts := []string{"id IN (" + infoQuery + ")", "status NOT IN (?)", "canonical_id IS NULL", inactiveCategoryQuery}
+	vals := append(infoVals, []int{types.ReleaseHidden, types.ReleaseFailed}, false, false)
+	if query != "" {
+		qParts = append(qParts, "search_name LIKE ?")
+		vals = append(vals, fmt.Sprintf("%%%s%%", query))
+	}
+	if len(categories) > 0 {
+		qParts = append(qParts, "category_id IN (?)")
+		vals = append(vals, categoryIDs(categories))
+	}
+	var releases []types.Release
+	err := d.DB.Where(strings.Join(qParts, " AND "), vals...).Preload("Category").Preload("Group").Offset(offset).Limit(limit).Order("posted desc").Find(&releases).Error
+	return releases, err
+}
+
+// categoryIDs returns the IDs of categories for an IN clause.
+func categoryIDs(categories []types.Category) []int64 {
+	ids := make([]int64, len(categories))
+	for i, cat := range categories {
+		ids[i] = int64(cat)
+	}
+	return ids
+}
+
 //FindReleaseByHash returns
 func (d *Handle) FindReleaseByHash(h string) (*types.Release, error) {
 	var rel types.Release
@@ -67,9 +111,21 @@ func NewRegexCleaner(regexes []*types.Regex) (*RegexCleaner, error) {
 	return &RegexCleaner{Regexes: regexes}, nil
 }
 
+// NoRegexID is recorded as the regex id of releases whose name wasn't
+// rewritten by any release regex.
+const NoRegexID = 0
+
 // Clean tries to rewrite the given name using it's regexes that match the
 // given group.
 func (r *RegexCleaner) Clean(name, groupname string) string {
+	cleaned, _ := r.Match(name, groupname)
+	return cleaned
+}
+
+// Match tries to rewrite the given name using it's regexes that match the
+// given group.  It returns the rewritten name and the ID of the regex used or
+// the unchanged name and NoRegexID.
+func (r *RegexCleaner) Match(name, groupname string) (string, int) {
 	for _, r := range r.Regexes {
 		if !r.CompiledGroupRegex.Regex.MatchString(groupname) {
 			continue
@@ -78,26 +134,43 @@ func (r *RegexCleaner) Clean(name, groupname string) string {
 		if len(m) == 0 {
 			continue
 		}
-		// Sort keynames and then concatinate values in that order
-		var keys = make([]string, len(m))
-		i := 0
-		for k := range m {
-			keys[i] = k
-			i++
-		}
-		sort.Strings(keys)
-		var parts = make([]string, len(m))
-		for i, k := range keys {
-			parts[i] = m[k]
-		}
-		return strings.Join(parts, "")
+		return joinNamedMatches(m), r.ID
 	}
-	return name
+	return name, NoRegexID
+}
+
+// Explain returns a trace of every regex that applies to groupname.
+func (r *RegexCleaner) Explain(name, groupname string) []RegexTrace {
+	return explainRegexes(r.Regexes, name, groupname)
+}
+
+// PreDBFinder looks up pres by their exact title.
+type PreDBFinder interface {
+	FindPreDBByTitle(title string) (*types.PreDB, error)
+}
+
+// RequestIDFinder looks up the requests filled in a group.
+type RequestIDFinder interface {
+	FindRequestID(reqid int64, groupname string) (*types.RequestID, error)
 }
 
 // ReleaseCleaner rewrites release names based on regexes and hard coded rules.
 type ReleaseCleaner struct {
 	RegexCleaner *RegexCleaner
+	// Pres are used to find the proper name of a release if this is set.
+	PreDB PreDBFinder
+	// Request IDs in subjects are looked up if this is set.
+	RequestIDs RequestIDFinder
+}
+
+// CleanedRelease is the name a release should get and where it came from.
+type CleanedRelease struct {
+	Name    string
+	RegexID int          // NoRegexID unless a release regex named it.
+	PreDB   *types.PreDB // Pre the name was taken from, if any.
+	// Request ID in the subject and whether the name was taken from it.
+	RequestID       int64
+	RequestResolved bool
 }
 
 //NewReleaseCleaner returns a new ReleaseCleaner
@@ -111,27 +184,243 @@ func NewReleaseCleaner(regexes []*types.Regex) (*ReleaseCleaner, error) {
 
 // Clean rewrites the release name based on regexes and hard coded rules.
 func (r *ReleaseCleaner) Clean(name, poster, groupname string, size int64) string {
-	// TODO: predb check
-	// TODO: deal with reqid
+	cleaned, _ := r.Match(name, poster, groupname, size)
+	return cleaned
+}
 
-	cleanedName := r.RegexCleaner.Clean(name, groupname)
-	if cleanedName != name {
-		return cleanedName
+// Match rewrites the release name based on regexes and hard coded rules and
+// returns the ID of the regex used, or NoRegexID if none matched.
+func (r *ReleaseCleaner) Match(name, poster, groupname string, size int64) (string, int) {
+	cleanedName, regexID := r.RegexCleaner.Match(name, groupname)
+	if regexID != NoRegexID {
+		return cleanedName, regexID
 	}
+	cleanedName, _ = HardcodedReleaseName(name, groupname)
+	return cleanedName, NoRegexID
+}
 
-	// Try release_naming_regexes
-	// Try to clean with hardcoded www.town.ag regexes
-	// switch on groupname
-	// teevee -> clean with teevee hardcode
-	// default -> clean with generic
-	return name
+// Scene style names like Some.Show.S01E01.720p.HDTV.x264-GROUP.  Ported from
+// nzedb's ReleaseCleaning.
+var preTitleRegex = regexp.MustCompile(`([\w()]+[\s._-]([\w()]+[\s._-])+[\w()]+-\w+)`)
+
+// Resolve returns the name a release should get.  The title of a known
+// request ID in the subject is used instead of the name Match would return,
+// as is the title of a pre found in the subject or matching the name.
+func (r *ReleaseCleaner) Resolve(name, poster, groupname string, size int64) (*CleanedRelease, error) {
+	cleanedName, regexID := r.Match(name, poster, groupname, size)
+	res := &CleanedRelease{Name: cleanedName, RegexID: regexID, RequestID: ParseRequestID(name)}
+	if res.RequestID != 0 && r.RequestIDs != nil {
+		req, err := r.RequestIDs.FindRequestID(res.RequestID, groupname)
+		if err != nil && err != gorm.RecordNotFound {
+			return nil, err
+		}
+		if err == nil && req.Title != "" {
+			res.Name = req.Title
+			res.RequestResolved = true
+		}
+	}
+	if r.PreDB == nil {
+		return res, nil
+	}
+	pre, err := r.findPre(name, res.Name)
+	if err != nil {
+		return nil, err
+	}
+	if pre != nil {
+		res.Name = pre.Title
+		res.PreDB = pre
+	}
+	return res, nil
+}
+
+// findPre returns the first pre whose title is the cleaned name or looks
+// like a scene name in the subject, or nil if there isn't one.
+func (r *ReleaseCleaner) findPre(subject, cleanedName string) (*types.PreDB, error) {
+	candidates := append([]string{cleanedName}, preTitleRegex.FindAllString(subject, -1)...)
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		c = strings.TrimSpace(c)
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		pre, err := r.PreDB.FindPreDBByTitle(c)
+		if err == gorm.RecordNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return pre, nil
+	}
+	return nil, nil
+}
+
+// categorizeRelease categorizes a release by the first of rules it matches or
+// else its name, falling back to the section of its pre for names the
+// categorizer can't place.
+func categorizeRelease(rules []*types.CategoryRule, name, groupname string, size int64, pre *types.PreDB) types.Category {
+	if cat, _ := categorize.FromRules(rules, name, groupname, size); cat != types.Unknown {
+		return cat
+	}
+	cat := categorize.Categorize(name, groupname)
+	if pre == nil {
+		return cat
+	}
+	switch cat {
+	case types.Unknown, types.Other_Misc, types.Other_Hashed:
+		if precat := categorize.FromPreSection(pre.Category); precat != types.Unknown {
+			return precat
+		}
+	}
+	return cat
+}
+
+// saveReleaseInfo replaces the TV, movie, music and book info of a release
+// with what can be parsed from its name in the given category.
+func saveReleaseInfo(tx *gorm.DB, releaseID int64, name string, cat types.Category) error {
+	for _, save := range []releaseInfoSaver{saveTVInfo, saveMovieInfo, saveMusicInfo, saveBookInfo} {
+		_, err := save(tx, releaseID, name, cat)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseOptions controls which binaries MakeReleases turns into releases.
+type ReleaseOptions struct {
+	// Percentage of segments that must be available.  Groups can override
+	// this with their own MinCompletion.
+	MinCompletion float64
+	// Binaries older than this are released when at least
+	// PartialMinCompletion percent complete.  Zero disables partial releases.
+	PartialAfter         time.Duration
+	PartialMinCompletion float64
+}
+
+// DefaultReleaseOptions only releases complete binaries.
+func DefaultReleaseOptions() ReleaseOptions {
+	return ReleaseOptions{MinCompletion: 100}
+}
+
+// requiredCompletion returns the completion percentage a binary posted at
+// posted in grp needs to be released.
+func (o ReleaseOptions) requiredCompletion(grp *types.Group, posted time.Time) float64 {
+	required := o.MinCompletion
+	if grp.MinCompletion > 0 {
+		required = grp.MinCompletion
+	}
+	if o.PartialAfter > 0 && time.Since(posted) > o.PartialAfter && o.PartialMinCompletion < required {
+		required = o.PartialMinCompletion
+	}
+	return required
+}
+
+// lowestCompletion returns the lowest completion any binary could need.
+func (o ReleaseOptions) lowestCompletion(groups map[string]*types.Group) float64 {
+	lowest := o.MinCompletion
+	for _, g := range groups {
+		if g.MinCompletion > 0 && g.MinCompletion < lowest {
+			lowest = g.MinCompletion
+		}
+	}
+	if o.PartialAfter > 0 && o.PartialMinCompletion < lowest {
+		lowest = o.PartialMinCompletion
+	}
+	return lowest
+}
+
+// releasableBinary is a binary with its completion percentage.
+type releasableBinary struct {
+	ID         int64
+	Name       string
+	Posted     time.Time
+	TotalParts int
+	GroupName  string
+	From       string
+	Completion float64
+}
+
+// Reasons binaries are rejected instead of being made into releases.
+const (
+	RejectTooFewFiles     = "too few files"
+	RejectTooManyFiles    = "too many files"
+	RejectGroupMinSize    = "smaller than group minimum"
+	RejectGroupMaxSize    = "larger than group maximum"
+	RejectCategoryMinSize = "smaller than category minimum"
+)
+
+// RejectReasons lists every reason a binary can be rejected for.
+var RejectReasons = []string{
+	RejectTooFewFiles,
+	RejectTooManyFiles,
+	RejectGroupMinSize,
+	RejectGroupMaxSize,
+	RejectCategoryMinSize,
+}
+
+// ReleaseStats counts what MakeReleases did with the binaries it looked at.
+type ReleaseStats struct {
+	Created    int
+	Duplicates int
+	Rejected   map[string]int // Rejected binaries by reason
+	// Releases made earlier that were renamed from their request ID.
+	RequestIDsResolved int
+	// Reposts linked to an earlier release, and how many of those added
+	// missing segments to it.
+	Reposts int
+	Merged  int
+}
+
+// checkReleaseLimits returns why a binary with the given number of files and
+// size can't be a release in grp, or an empty string if it can.
+func checkReleaseLimits(grp *types.Group, files int, size int64, catMinSize int64) string {
+	switch {
+	case files < grp.MinFiles:
+		return RejectTooFewFiles
+	case grp.MaxFiles > 0 && files > grp.MaxFiles:
+		return RejectTooManyFiles
+	case size < grp.MinSize:
+		return RejectGroupMinSize
+	case grp.MaxSize > 0 && size > grp.MaxSize:
+		return RejectGroupMaxSize
+	case size < catMinSize:
+		return RejectCategoryMinSize
+	}
+	return ""
 }
 
 // MakeReleases searchs for complete binaries and create releases from them deleting the
 // binaries in the process.
 func (d *Handle) MakeReleases() error {
-	var binaries []types.Binary
-	q := `SELECT binary.id, binary.name, binary.posted, binary.total_parts, binary.group_name
+	_, err := d.MakeReleasesWithOptions(DefaultReleaseOptions())
+	return err
+}
+
+// MakeReleasesWithOptions creates releases from all binaries that are
+// complete enough according to opts, deleting the binaries in the process.
+func (d *Handle) MakeReleasesWithOptions(opts ReleaseOptions) (*ReleaseStats, error) {
+	stats := &ReleaseStats{Rejected: map[string]int{}}
+	groups, err := d.GetAllGroups()
+	if err != nil {
+		return stats, err
+	}
+	catMinSizes, err := d.GetCategoryMinSizes()
+	if err != nil {
+		return stats, err
+	}
+	groupMap := make(map[string]*types.Group, len(groups))
+	for i := range groups {
+		groupMap[groups[i].Name] = &groups[i]
+	}
+
+	// Completion is the share of segments available in the parts we have,
+	// scaled down by the share of parts we have.
+	var binaries []releasableBinary
+	q := `SELECT binary.id, binary.name, binary.posted, binary.total_parts, binary.group_name, ` + "`binary`.`from`" + `,
+		(sum(part.available_segments) * 1.0 / sum(part.total_segments)) *
+		(CASE WHEN binary.total_parts > count(*) THEN count(*) * 1.0 / binary.total_parts ELSE 1 END) * 100 AS completion
 	FROM ` + "`binary`" + `
 	INNER JOIN (
 		SELECT
@@ -142,99 +431,173 @@ func (d *Handle) MakeReleases() error {
 	) as part
 	ON binary.id = part.binary_id
 	GROUP BY binary.id
-	HAVING count(*) >= binary.total_parts AND (sum(part.available_segments) / sum(part.total_segments)) * 100 >= ?
+	HAVING completion >= ?
 	ORDER BY binary.posted DESC`
-	err := d.DB.Raw(q, 100).Scan(&binaries).Error
+	err = d.DB.Raw(q, opts.lowestCompletion(groupMap)).Scan(&binaries).Error
 	if err != nil {
-		return err
+		return stats, err
 	}
 	logrus.Infof("Got %d binaries to scan", len(binaries))
-	var regex []*types.Regex
-	err = d.DB.Find(&regex).Error
+	regex, err := d.GetReleaseRegexes()
 	if err != nil {
-		return err
+		return stats, err
 	}
 	cleaner, err := NewReleaseCleaner(regex)
 	if err != nil {
-		return err
+		return stats, err
+	}
+	cleaner.PreDB = d
+	cleaner.RequestIDs = d
+	rules, err := d.GetCategoryRules(false)
+	if err != nil {
+		return stats, err
 	}
 
 	for _, b := range binaries {
+		grp, ok := groupMap[b.GroupName]
+		if !ok {
+			logrus.Errorf("Unknown group %s for binary %d: %s. Skipping...", b.GroupName, b.ID, b.Name)
+			continue
+		}
+		if b.Completion > 100 {
+			b.Completion = 100
+		}
+		if required := opts.requiredCompletion(grp, b.Posted); b.Completion < required {
+			logrus.Debugf("Binary %s is only %.2f%% complete (need %.2f%%)", b.Name, b.Completion, required)
+			continue
+		}
+
 		// Get Binary and all it's parts and segments.
 		dbbin := &types.Binary{}
 		err = d.DB.Preload("Parts").Preload("Parts.Segments").First(dbbin, b.ID).Error
 		if err != nil {
-			return err
+			return stats, err
 		}
 
-		cleanName := cleaner.Clean(b.Name, b.From, b.GroupName, dbbin.Size())
+		cleaned, err := cleaner.Resolve(b.Name, b.From, b.GroupName, dbbin.Size())
+		if err != nil {
+			return stats, err
+		}
+		cleanName, regexID := cleaned.Name, cleaned.RegexID
 
 		hash := makeShaHash(cleanName, b.GroupName, strconv.FormatInt(b.Posted.Unix(), 10), strconv.FormatInt(dbbin.Size(), 10))
-		rel, err := d.FindReleaseByHash(hash)
+		_, err = d.FindReleaseByHash(hash)
 		if err != nil && err != gorm.RecordNotFound {
-			return err
+			return stats, err
+		}
+		// Releases deleted by hand count as duplicates so they aren't made
+		// again.
+		duplicate := err == nil
+		if !duplicate {
+			duplicate, err = d.releaseDeleted(hash)
+			if err != nil {
+				return stats, err
+			}
 		}
-		if err == nil {
-			logrus.Infof("Found duplicate release hash: %s for binary %s", rel.Hash, b.Name)
+		if duplicate {
+			logrus.Infof("Found duplicate release hash: %s for binary %s", hash, b.Name)
+			stats.Duplicates++
 			err = deleteBinary(&d.DB, dbbin)
 			if err != nil {
-				return err
+				return stats, err
 			}
 			continue
 		}
 
-		grp, err := d.FindGroupByName(b.GroupName)
-		if err != nil {
-			logrus.Errorf("Unknown group %s for binary %d: %s. Skipping...", b.GroupName, b.ID, b.Name)
-			continue
-		}
+		// Categorize
+		cat := categorizeRelease(rules, cleanName, grp.Name, dbbin.Size(), cleaned.PreDB)
 
-		// Check if too few files
-		if len(dbbin.Parts) < grp.MinFiles {
-			logrus.Infof("Too few files for %s in group %s (%d < %d)", b.Name, grp.Name, len(dbbin.Parts), grp.MinFiles)
+		if reason := checkReleaseLimits(grp, len(dbbin.Parts), dbbin.Size(), catMinSizes[cat]); reason != "" {
+			logrus.Infof("Rejecting %s in group %s: %s (%d files, %d bytes)", b.Name, grp.Name, reason, len(dbbin.Parts), dbbin.Size())
+			stats.Rejected[reason]++
 			err = deleteBinary(&d.DB, dbbin)
 			if err != nil {
-				return err
+				return stats, err
 			}
 			continue
 		}
 
 		nzbstr, err := nzb.WriteNZB(dbbin)
 		if err != nil {
-			return err
+			return stats, err
 		}
 		newrel := &types.Release{
-			Name:         cleanName,
-			OriginalName: b.Name,
-			SearchName:   cleanReleaseName(cleanName),
-			Posted:       b.Posted,
-			From:         b.From,
-			Group:        *grp,
-			Size:         dbbin.Size(),
-			NZB:          nzbstr,
-			Hash:         hash,
+			Name:           cleanName,
+			OriginalName:   b.Name,
+			SearchName:     cleanReleaseName(cleanName),
+			NormalizedName: normalizeReleaseName(cleanName),
+			Files:          len(dbbin.Parts),
+			Posted:         b.Posted,
+			From:           b.From,
+			Group:          *grp,
+			Size:           dbbin.Size(),
+			Hash:           hash,
+			RegexID:        sql.NullInt64{Int64: int64(regexID), Valid: true},
+			Completion:     b.Completion,
+			CategoryID:     sql.NullInt64{Int64: int64(cat), Valid: true},
+		}
+		if cleaned.RequestID != 0 {
+			newrel.RequestID = cleaned.RequestID
+			newrel.RequestStatus = types.RequestIDUnresolved
+			if cleaned.RequestResolved {
+				newrel.RequestStatus = types.RequestIDResolved
+			}
+		}
+		if cleaned.PreDB != nil {
+			newrel.PreDBID = sql.NullInt64{Int64: cleaned.PreDB.ID, Valid: true}
+			newrel.Nuked = cleaned.PreDB.Nuked
+			logrus.Infof("Named %s from pre %d", cleanName, cleaned.PreDB.ID)
 		}
-
-		// Categorize
-		cat := categorize.Categorize(newrel.Name, newrel.Group.Name)
-		newrel.CategoryID = sql.NullInt64{Int64: int64(cat), Valid: true}
 		logrus.Infof("New %s release found: %s", cat, b.Name)
-		// Check if size is too small
 		tx := d.DB.Begin()
 		logrus.Infof("Saving new release: %s", newrel.Name)
 		err = tx.Save(newrel).Error
 		if err != nil {
 			tx.Rollback()
-			return err
+			return stats, err
 		}
-		err = deleteBinary(tx, dbbin)
+		file, err := d.saveNZB(tx, newrel.ID, nzbstr)
 		if err != nil {
 			tx.Rollback()
-			return err
+			return stats, err
+		}
+		err = saveReleaseInfo(tx, newrel.ID, newrel.Name, cat)
+		if err != nil {
+			d.rollbackNZBs(tx, file)
+			return stats, err
+		}
+		err = deleteBinary(tx, dbbin)
+		if err != nil {
+			d.rollbackNZBs(tx, file)
+			return stats, err
+		}
+		err = saveRegexHits(tx, ReleaseRegexKind, map[int]int64{regexID: 1}, time.Now())
+		if err != nil {
+			d.rollbackNZBs(tx, file)
+			return stats, err
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			d.rollbackNZBs(tx, file)
+			return stats, err
+		}
+		stats.Created++
+
+		dupe, merged, err := d.dedupeRelease(newrel)
+		if err != nil {
+			return stats, err
+		}
+		if dupe {
+			stats.Reposts++
+		}
+		if merged {
+			stats.Merged++
 		}
-		tx.Commit()
 	}
-	return nil
+
+	// Requests may have been imported since earlier releases were made.
+	stats.RequestIDsResolved, err = d.ResolveRequestIDs(1000)
+	return stats, err
 }
 
 func deleteBinary(tx *gorm.DB, dbbin *types.Binary) error {
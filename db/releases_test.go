@@ -1,7 +1,9 @@
 package db
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/hobeone/gonab/types"
@@ -13,7 +15,8 @@ func TestSearchReleases(t *testing.T) {
 	r := types.Release{
 		Name:       "foo",
 		SearchName: "foo.bar",
-		Category:   types.DBCategory{ID: int64(types.TV_HD)},
+		// Setting Category would save it over the real one.
+		CategoryID: sql.NullInt64{Int64: int64(types.TV_HD), Valid: true},
 	}
 
 	err := dbh.DB.Create(&r).Error
@@ -28,6 +31,15 @@ func TestSearchReleases(t *testing.T) {
 	if len(dbrel) != 1 {
 		t.Fatalf("Expected length 1 for search result, got %d", len(dbrel))
 	}
+
+	// Every category counts, not just the first.
+	dbrel, err = dbh.SearchReleases("foo", 0, 10, []types.Category{types.TV_SD, types.TV_HD})
+	if err != nil {
+		t.Fatalf("Error searching for release: %s", err)
+	}
+	if len(dbrel) != 1 {
+		t.Fatalf("Expected length 1 for search in the second category, got %d", len(dbrel))
+	}
 }
 
 func TestMakeReleases(t *testing.T) {
@@ -53,3 +65,122 @@ func TestMakeReleases(t *testing.T) {
 		t.Fatalf("Unexpected number of releases: %d != 47", len(rels))
 	}
 }
+
+func TestRequiredCompletion(t *testing.T) {
+	opts := ReleaseOptions{
+		MinCompletion:        100,
+		PartialAfter:         24 * time.Hour,
+		PartialMinCompletion: 90,
+	}
+	grp := &types.Group{Name: "misc.test"}
+	override := &types.Group{Name: "misc.other", MinCompletion: 98}
+	old := time.Now().Add(-48 * time.Hour)
+
+	cases := []struct {
+		Group    *types.Group
+		Posted   time.Time
+		Expected float64
+	}{
+		{grp, time.Now(), 100},
+		{override, time.Now(), 98},
+		{grp, old, 90},
+		{override, old, 90},
+	}
+	for _, c := range cases {
+		got := opts.requiredCompletion(c.Group, c.Posted)
+		if got != c.Expected {
+			t.Errorf("Expected %s posted %s to need %.1f%%, got %.1f%%", c.Group.Name, c.Posted, c.Expected, got)
+		}
+	}
+
+	groups := map[string]*types.Group{grp.Name: grp, override.Name: override}
+	if l := opts.lowestCompletion(groups); l != 90 {
+		t.Errorf("Expected lowest completion of 90%%, got %.1f%%", l)
+	}
+}
+
+func TestMakeReleasesIncomplete(t *testing.T) {
+	logrus.SetLevel(logrus.ErrorLevel)
+	dbh := NewMemoryDBHandle(false, true)
+	part := types.Part{
+		Hash:          "incomplete",
+		Subject:       `Incomplete Show - 01 [1080p] [1/1] - "Incomplete Show - 01.mkv" yEnc`,
+		TotalSegments: 4,
+		Posted:        time.Now(),
+		From:          "foo@bar.com",
+		GroupName:     "misc.test",
+		Segments: []types.Segment{
+			{Segment: 1, Size: 1024, MessageID: randString()},
+			{Segment: 2, Size: 1024, MessageID: randString()},
+			{Segment: 3, Size: 1024, MessageID: randString()},
+		},
+	}
+	err := dbh.DB.Save(&part).Error
+	if err != nil {
+		t.Fatalf("Error saving part: %v", err)
+	}
+	_, err = dbh.AddGroup("misc.test")
+	if err != nil {
+		t.Fatalf("Error saving group: %v", err)
+	}
+	err = dbh.MakeBinaries()
+	if err != nil {
+		t.Fatalf("Error making binaries: %s", err)
+	}
+
+	err = dbh.MakeReleases()
+	if err != nil {
+		t.Fatalf("Error making releases: %s", err)
+	}
+	var rels []types.Release
+	dbh.DB.Find(&rels)
+	if len(rels) != 0 {
+		t.Fatalf("Expected incomplete binary not to be released, got %d releases", len(rels))
+	}
+
+	stats, err := dbh.MakeReleasesWithOptions(ReleaseOptions{MinCompletion: 75})
+	if err != nil {
+		t.Fatalf("Error making releases: %s", err)
+	}
+	if stats.Created != 1 {
+		t.Fatalf("Expected stats to count 1 created release, got %d", stats.Created)
+	}
+	dbh.DB.Find(&rels)
+	if len(rels) != 1 {
+		t.Fatalf("Expected 1 release, got %d", len(rels))
+	}
+	if rels[0].Completion != 75 {
+		t.Fatalf("Expected release completion of 75%%, got %.2f", rels[0].Completion)
+	}
+}
+
+func TestCheckReleaseLimits(t *testing.T) {
+	grp := &types.Group{
+		MinFiles: 2,
+		MaxFiles: 10,
+		MinSize:  1000,
+		MaxSize:  5000,
+	}
+	cases := []struct {
+		Files      int
+		Size       int64
+		CatMinSize int64
+		Expected   string
+	}{
+		{5, 2000, 0, ""},
+		{1, 2000, 0, RejectTooFewFiles},
+		{11, 2000, 0, RejectTooManyFiles},
+		{5, 999, 0, RejectGroupMinSize},
+		{5, 5001, 0, RejectGroupMaxSize},
+		{5, 2000, 3000, RejectCategoryMinSize},
+	}
+	for _, c := range cases {
+		got := checkReleaseLimits(grp, c.Files, c.Size, c.CatMinSize)
+		if got != c.Expected {
+			t.Errorf("Expected %d files of %d bytes to get %q, got %q", c.Files, c.Size, c.Expected, got)
+		}
+	}
+	if got := checkReleaseLimits(&types.Group{}, 100, 1<<40, 0); got != "" {
+		t.Errorf("Expected group without limits to accept everything, got %q", got)
+	}
+}
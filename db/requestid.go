@@ -0,0 +1,149 @@
+package db
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// Request groups prefix subjects with the ID of the request being filled:
+// [12345]-[FULL]-[#a.b.teevee@EFNet]-[ Some.Show.S01E01 ]-[01/20] ...
+var requestIDRegex = regexp.MustCompile(`^\s*\[\s*(\d{3,9})\s*\]`)
+
+// ParseRequestID returns the request ID at the start of subject, or 0 if
+// there isn't one.
+func ParseRequestID(subject string) int64 {
+	m := requestIDRegex.FindStringSubmatch(subject)
+	if m == nil {
+		return 0
+	}
+	reqid, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return reqid
+}
+
+// FindRequestID returns the request with the given ID in groupname.
+func (d *Handle) FindRequestID(reqid int64, groupname string) (*types.RequestID, error) {
+	var req types.RequestID
+	err := d.DB.Where("request_id = ? AND group_name = ?", reqid, groupname).First(&req).Error
+	return &req, err
+}
+
+// SaveRequestIDs adds the given requests, updating the title of the ones
+// already known.  Returns the number of requests added and updated.
+func (d *Handle) SaveRequestIDs(reqs []*types.RequestID) (int, int, error) {
+	added, updated := 0, 0
+	tx := d.DB.Begin()
+	for _, r := range reqs {
+		var dbreq types.RequestID
+		err := tx.Where("request_id = ? AND group_name = ?", r.RequestID, r.GroupName).First(&dbreq).Error
+		if err != nil && err != gorm.RecordNotFound {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		if err == nil {
+			r.ID = dbreq.ID
+			updated++
+		} else {
+			added++
+		}
+		err = tx.Save(r).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+	}
+	err := tx.Commit().Error
+	return added, updated, err
+}
+
+// CountRequestIDs returns the number of requests in the database.
+func (d *Handle) CountRequestIDs() (int64, error) {
+	var count int64
+	err := d.DB.Model(&types.RequestID{}).Count(&count).Error
+	return count, err
+}
+
+// ResolveRequestIDs renames and recategorizes the releases whose request ID
+// wasn't known when they were made, unless they were edited by hand, batch
+// releases at a time.  Returns the number of releases renamed.
+func (d *Handle) ResolveRequestIDs(batch int) (int, error) {
+	if batch < 1 {
+		batch = 1000
+	}
+	rules, err := d.GetCategoryRules(false)
+	if err != nil {
+		return 0, err
+	}
+	resolved := 0
+	lastID := int64(0)
+	for {
+		var releases []types.Release
+		err = d.DB.Where("request_status = ? AND name_locked = ? AND id > ?", types.RequestIDUnresolved, false, lastID).Preload("Group").Order("id").Limit(batch).Find(&releases).Error
+		if err != nil {
+			return resolved, err
+		}
+		if len(releases) == 0 {
+			break
+		}
+		lastID = releases[len(releases)-1].ID
+		n, err := d.resolveRequestIDs(rules, releases)
+		resolved += n
+		if err != nil {
+			return resolved, err
+		}
+	}
+	return resolved, nil
+}
+
+func (d *Handle) resolveRequestIDs(rules []*types.CategoryRule, releases []types.Release) (int, error) {
+	resolved := 0
+	for _, rel := range releases {
+		req, err := d.FindRequestID(rel.RequestID, rel.Group.Name)
+		if err == gorm.RecordNotFound {
+			continue
+		}
+		if err != nil {
+			return resolved, err
+		}
+		if req.Title == "" {
+			continue
+		}
+		updates := map[string]interface{}{
+			"name":           req.Title,
+			"search_name":    cleanReleaseName(req.Title),
+			"request_status": types.RequestIDResolved,
+		}
+		pre, err := d.FindPreDBByTitle(req.Title)
+		switch {
+		case err == gorm.RecordNotFound:
+			pre = nil
+		case err != nil:
+			return resolved, err
+		default:
+			updates["predb_id"] = pre.ID
+			updates["nuked"] = pre.Nuked
+		}
+		cat := rel.CategoryName()
+		if !rel.CategoryLocked {
+			cat = categorizeRelease(rules, req.Title, rel.Group.Name, rel.Size, pre)
+			updates["category_id"] = int64(cat)
+		}
+		err = d.DB.Model(types.Release{}).Where("id = ?", rel.ID).UpdateColumns(updates).Error
+		if err != nil {
+			return resolved, err
+		}
+		err = saveReleaseInfo(&d.DB, rel.ID, req.Title, cat)
+		if err != nil {
+			return resolved, err
+		}
+		logrus.Infof("Renamed release %d from request %d: %s", rel.ID, rel.RequestID, req.Title)
+		resolved++
+	}
+	return resolved, nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseRequestID(t *testing.T) {
+	RegisterTestingT(t)
+	tests := map[string]int64{
+		`[169018]-[FULL]-[a.b.teevee]-[ House.of.Lies.S01E01.720p.WEB-DL.DD5.1.H.264-BS ]-[04/32] - "house.of.lies.s01e01.r04" yEnc`: 169018,
+		`[ 12345 ]-[FULL]-[#a.b.moovee@EFNet]-[ Some.Movie.2015.720p.BluRay.x264-GRP ] [1/5] - "sm.nfo" yEnc`:                        12345,
+		`[######]-[FULL]-[#a.b.teevee@EFNet]-[ Misfits.S01.SUBPACK.DVDRip.XviD-P0W4DVD ] [1/5] - "Misfits.nfo" yEnc`:                 0,
+		`Long Show Name - 01 [1080p] [01/20] - "Long Show Name - 01.mkv.rar" yEnc`:                                                   0,
+	}
+	for subj, expected := range tests {
+		Expect(ParseRequestID(subj)).To(Equal(expected), subj)
+	}
+}
+
+func TestResolveRequestIDs(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	grp := types.Group{Name: "alt.binaries.teevee"}
+	err := dbh.DB.Save(&grp).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	cleaner, err := NewReleaseCleaner([]*types.Regex{})
+	Expect(err).ToNot(HaveOccurred())
+	cleaner.RequestIDs = dbh
+
+	subj := `[169018]-[FULL]-[a.b.teevee]-[ h.o.l.101 ]-[04/32] - "hol101.r04" yEnc`
+	cleaned, err := cleaner.Resolve(subj, "", grp.Name, 0)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cleaned.RequestID).To(Equal(int64(169018)))
+	Expect(cleaned.RequestResolved).To(BeFalse())
+
+	// Requests that stay unknown don't stop later releases from resolving.
+	unknown := types.Release{
+		Name:          "unknown request",
+		Hash:          "unknownhash",
+		Group:         grp,
+		RequestID:     99999,
+		RequestStatus: types.RequestIDUnresolved,
+	}
+	err = dbh.DB.Save(&unknown).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	rel := types.Release{
+		Name:          cleaned.Name,
+		Hash:          "reqhash",
+		Group:         grp,
+		RequestID:     cleaned.RequestID,
+		RequestStatus: types.RequestIDUnresolved,
+	}
+	err = dbh.DB.Save(&rel).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	resolved, err := dbh.ResolveRequestIDs(1)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(resolved).To(Equal(0))
+
+	title := "House.of.Lies.S01E01.720p.WEB-DL.DD5.1.H.264-BS"
+	_, _, err = dbh.SaveRequestIDs([]*types.RequestID{
+		{RequestID: 169018, GroupName: grp.Name, Title: title},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	cleaned, err = cleaner.Resolve(subj, "", grp.Name, 0)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(cleaned.RequestResolved).To(BeTrue())
+	Expect(cleaned.Name).To(Equal(title))
+
+	// Releases renamed by hand keep their name.
+	locked := types.Release{
+		Name:          "Named.By.Hand",
+		Hash:          "lockedhash",
+		Group:         grp,
+		RequestID:     cleaned.RequestID,
+		RequestStatus: types.RequestIDUnresolved,
+		NameLocked:    true,
+	}
+	err = dbh.DB.Save(&locked).Error
+	Expect(err).ToNot(HaveOccurred())
+
+	resolved, err = dbh.ResolveRequestIDs(1)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(resolved).To(Equal(1))
+
+	err = dbh.DB.First(&locked, locked.ID).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(locked.Name).To(Equal("Named.By.Hand"))
+
+	var dbrel types.Release
+	err = dbh.DB.First(&dbrel, rel.ID).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(dbrel.Name).To(Equal(title))
+	Expect(dbrel.SearchName).To(Equal(cleanReleaseName(title)))
+	Expect(dbrel.RequestStatus).To(Equal(types.RequestIDResolved))
+	Expect(dbrel.CategoryName()).To(Equal(types.TV_WEBDL))
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// normalizeEpisodeNumber strips the leading zeros of season and episode
+// numbers so S03E01 and 3x01 match.  Dates are left alone.
+func normalizeEpisodeNumber(s string) string {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return s
+	}
+	s = strings.TrimLeft(s, "0")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// parseTVInfo returns the TV info in the name of a release and the year in
+// it, or nil if the name can't be parsed.
+func parseTVInfo(releaseID int64, name string) (*types.TVInfo, string) {
+	res, err := processing.ParseInfo(name)
+	if err != nil || res.Name == "" {
+		return nil, ""
+	}
+	lastEp, _ := strconv.Atoi(res.LastEpisode)
+	return &types.TVInfo{
+		ReleaseID:   releaseID,
+		ShowName:    res.Name,
+		ShowKey:     processing.NameKey(res.Name),
+		Season:      normalizeEpisodeNumber(res.Season),
+		Episode:     normalizeEpisodeNumber(res.Episode),
+		LastEpisode: lastEp,
+		Airdate:     strings.NewReplacer(".", "-", "/", "-").Replace(res.Airdate),
+	}, res.Year
+}
+
+// saveTVInfo replaces the TV info of a release with what can be parsed from
+// its name and links it to the matching TV show.  Releases not in a TV
+// category don't get any.  Returns whether TV info was saved.
+func saveTVInfo(tx *gorm.DB, releaseID int64, name string, cat types.Category) (bool, error) {
+	err := tx.Where("release_id = ?", releaseID).Delete(types.TVInfo{}).Error
+	if err != nil || cat.Parent() != types.TV {
+		return false, err
+	}
+	info, year := parseTVInfo(releaseID, name)
+	if info == nil {
+		return false, nil
+	}
+	show, err := findTVShow(tx, info.ShowName, year)
+	if err != nil {
+		return false, err
+	}
+	if show != nil {
+		info.TVShowID = sql.NullInt64{Int64: show.ID, Valid: true}
+	}
+	return true, tx.Save(info).Error
+}
+
+// categoryTreeQuery selects releases in a parent category and its
+// subcategories, including custom ones.  Given a subcategory it selects only
+// the releases in it.
+func categoryTreeQuery(parent types.Category) (string, []interface{}) {
+	if parent.Parent() != parent {
+		return "category_id = ?", []interface{}{int64(parent)}
+	}
+	min, max, _ := types.CustomCategoryRange(parent)
+	return "(category_id BETWEEN ? AND ? OR category_id BETWEEN ? AND ?)",
+		[]interface{}{int64(parent), int64(parent) + 999, int64(min), int64(max)}
+}
+
+// releaseInfoSaver saves the info parsed from the name of a release, see
+// saveTVInfo.
+type releaseInfoSaver func(tx *gorm.DB, releaseID int64, name string, cat types.Category) (bool, error)
+
+// backfillReleaseInfo runs save on the releases in parent, see
+// categoryTreeQuery, made without a row in table, batch releases at a time.
+// Returns the number of releases checked and the number that got info.
+func (d *Handle) backfillReleaseInfo(parent types.Category, table string, batch int, save releaseInfoSaver) (int, int, error) {
+	if batch < 1 {
+		batch = 1000
+	}
+	checked, parsed := 0, 0
+	catQuery, vals := categoryTreeQuery(parent)
+	lastID := int64(0)
+	for {
+		var releases []types.Release
+		err := d.DB.Select("id, name, category_id").Where(catQuery+" AND id > ? AND id NOT IN (SELECT release_id FROM "+table+")", append(vals, lastID)...).Order("id").Limit(batch).Find(&releases).Error
+		if err != nil {
+			return checked, parsed, err
+		}
+		if len(releases) == 0 {
+			break
+		}
+		lastID = releases[len(releases)-1].ID
+		tx := d.DB.Begin()
+		for _, rel := range releases {
+			checked++
+			ok, err := save(tx, rel.ID, rel.Name, rel.CategoryName())
+			if err != nil {
+				tx.Rollback()
+				return checked, parsed, err
+			}
+			if ok {
+				parsed++
+			}
+		}
+		err = tx.Commit().Error
+		if err != nil {
+			return checked, parsed, err
+		}
+		logrus.Infof("Parsed %d of %d releases into %s", parsed, checked, table)
+	}
+	return checked, parsed, nil
+}
+
+// BackfillTVInfo parses the names of TV releases made without TV info, batch
+// releases at a time.  Returns the number of releases checked and the number
+// that got TV info.
+func (d *Handle) BackfillTVInfo(batch int) (int, int, error) {
+	return d.backfillReleaseInfo(types.TV, "tv_info", batch, saveTVInfo)
+}
+
+// GetTVInfo returns the TV info of a release.
+func (d *Handle) GetTVInfo(releaseID int64) (*types.TVInfo, error) {
+	info := &types.TVInfo{}
+	err := d.DB.Where("release_id = ?", releaseID).First(info).Error
+	return info, err
+}
+
+// TVSearch selects the releases SearchTVReleases returns.  Empty fields and
+// zero IDs don't filter, shows with any of the given IDs match.
+type TVSearch struct {
+	Show       string
+	TVMazeID   int64
+	TVDBID     int64
+	TVRageID   int64
+	Season     string
+	Episode    string
+	Categories []types.Category
+	Offset     int
+	Limit      int
+}
+
+// SearchTVReleases returns the releases with TV info matching s, newest
+// first.  Hidden releases, duplicates and inactive categories are left out.
+func (d *Handle) SearchTVReleases(s TVSearch) ([]types.Release, error) {
+	tvParts := []string{}
+	tvVals := []interface{}{}
+	showIDs := []struct {
+		column string
+		id     int64
+	}{
+		{"tvmaze_id", s.TVMazeID},
+		{"tvdb_id", s.TVDBID},
+		{"tvrage_id", s.TVRageID},
+	}
+	// Shows imported from one dump only have its ID, so any of them match.
+	idParts := []string{}
+	for _, i := range showIDs {
+		if i.id != 0 {
+			idParts = append(idParts, i.column+" = ?")
+			tvVals = append(tvVals, i.id)
+		}
+	}
+	if len(idParts) > 0 {
+		tvParts = append(tvParts, "tv_show_id IN (SELECT id FROM tv_show WHERE "+strings.Join(idParts, " OR ")+")")
+	}
+	// The show name only filters when no show ID is given since releases may
+	// be named after an alias of the show.
+	if s.Show != "" && len(tvParts) == 0 {
+		tvParts = append(tvParts, "show_key = ?")
+		tvVals = append(tvVals, processing.NameKey(s.Show))
+	}
+	if s.Season != "" {
+		tvParts = append(tvParts, "season = ?")
+		tvVals = append(tvVals, normalizeEpisodeNumber(s.Season))
+	}
+	if s.Episode != "" {
+		ep := normalizeEpisodeNumber(s.Episode)
+		if n, err := strconv.Atoi(ep); err == nil {
+			// Multi episode releases match every episode they contain.
+			// SIGNED INTEGER casts on both MySQL and SQLite.
+			tvParts = append(tvParts, "(episode = ? OR (last_episode >= ? AND CAST(episode AS SIGNED INTEGER) <= ?))")
+			tvVals = append(tvVals, ep, n, n)
+		} else {
+			tvParts = append(tvParts, "episode = ?")
+			tvVals = append(tvVals, ep)
+		}
+	}
+	return d.searchInfoReleases("tv_info", tvParts, tvVals, "", s.Categories, s.Offset, s.Limit)
+}
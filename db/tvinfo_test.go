@@ -0,0 +1,94 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseTVInfo(t *testing.T) {
+	RegisterTestingT(t)
+
+	info, _ := parseTVInfo(1, "Sleepy.Hollow.S02E03.720p.HDTV.x264-KILLERS")
+	Expect(info).ToNot(BeNil())
+	Expect(info.ShowName).To(Equal("Sleepy Hollow"))
+	Expect(info.ShowKey).To(Equal("sleepy hollow"))
+	Expect(info.Season).To(Equal("2"))
+	Expect(info.Episode).To(Equal("3"))
+	Expect(info.LastEpisode).To(Equal(0))
+
+	info, _ = parseTVInfo(1, "Sleepy.Hollow.S02E05-E07.720p.HDTV.x264-KILLERS")
+	Expect(info).ToNot(BeNil())
+	Expect(info.Episode).To(Equal("5"))
+	Expect(info.LastEpisode).To(Equal(7))
+
+	info, _ = parseTVInfo(1, "Some.Movie.2015.720p.BluRay.x264-GRP")
+	Expect(info).To(BeNil())
+}
+
+func TestBackfillAndSearchTVInfo(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	releases := []*types.Release{
+		{Name: "Sleepy.Hollow.S02E03.720p.HDTV.x264-KILLERS", Hash: "h1", CategoryID: sql.NullInt64{Int64: int64(types.TV_HD), Valid: true}},
+		{Name: "Sleepy.Hollow.S02E04.720p.HDTV.x264-KILLERS", Hash: "h2", CategoryID: sql.NullInt64{Int64: int64(types.TV_HD), Valid: true}},
+		{Name: "Other.Show.2x03.HDTV.XviD-LOL", Hash: "h3", CategoryID: sql.NullInt64{Int64: int64(types.TV_SD), Valid: true}},
+		{Name: "Sleepy.Hollow.S02E03.720p.BluRay.x264-GRP", Hash: "h4", CategoryID: sql.NullInt64{Int64: int64(types.Movie_HD), Valid: true}},
+		{Name: "Sleepy.Hollow.S02E05-E07.720p.HDTV.x264-KILLERS", Hash: "h5", CategoryID: sql.NullInt64{Int64: int64(types.TV_HD), Valid: true}},
+	}
+	for _, rel := range releases {
+		err := dbh.DB.Save(rel).Error
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	checked, parsed, err := dbh.BackfillTVInfo(2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(checked).To(Equal(4))
+	Expect(parsed).To(Equal(4))
+
+	info, err := dbh.GetTVInfo(releases[2].ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.ShowName).To(Equal("Other Show"))
+	Expect(info.Season).To(Equal("2"))
+	Expect(info.Episode).To(Equal("3"))
+
+	// Releases with TV info aren't checked again.
+	checked, _, err = dbh.BackfillTVInfo(2)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(checked).To(Equal(0))
+
+	found, err := dbh.SearchTVReleases(TVSearch{Show: "sleepy hollow", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(3))
+
+	// Multi episode releases are found by every episode in them.
+	for _, ep := range []string{"5", "06", "7"} {
+		found, err = dbh.SearchTVReleases(TVSearch{Show: "Sleepy Hollow", Season: "2", Episode: ep, Limit: 10})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(HaveLen(1), ep)
+		Expect(found[0].ID).To(Equal(releases[4].ID))
+	}
+	found, err = dbh.SearchTVReleases(TVSearch{Show: "Sleepy Hollow", Season: "2", Episode: "8", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeEmpty())
+
+	found, err = dbh.SearchTVReleases(TVSearch{Show: "Sleepy Hollow", Season: "02", Episode: "04", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[1].ID))
+
+	found, err = dbh.SearchTVReleases(TVSearch{Season: "2", Episode: "3", Categories: []types.Category{types.TV_SD}, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[2].ID))
+
+	// Moving a release out of TV drops its TV info.
+	movie := types.Movie_HD
+	_, err = dbh.EditRelease(releases[1].ID, ReleaseEdit{Category: &movie})
+	Expect(err).ToNot(HaveOccurred())
+	_, err = dbh.GetTVInfo(releases[1].ID)
+	Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/hobeone/gonab/processing"
+	"github.com/hobeone/gonab/types"
+	"github.com/jinzhu/gorm"
+)
+
+// findTVShowByIDs returns the show with any of the non zero IDs of s, or nil
+// if there's none.
+func findTVShowByIDs(tx *gorm.DB, s *types.TVShow) (*types.TVShow, error) {
+	ids := []struct {
+		column string
+		id     int64
+	}{
+		{"tvmaze_id", s.TVMazeID},
+		{"tvdb_id", s.TVDBID},
+		{"tvrage_id", s.TVRageID},
+	}
+	for _, i := range ids {
+		if i.id == 0 {
+			continue
+		}
+		var show types.TVShow
+		err := tx.Where(i.column+" = ?", i.id).First(&show).Error
+		if err == nil {
+			return &show, nil
+		}
+		if err != gorm.RecordNotFound {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// SaveTVShows adds the given shows and replaces their aliases.  Shows with a
+// TVMaze, TVDB or TVRage ID that is already known are updated, zero IDs
+// keep what's in the database.  Returns the number of shows added and
+// updated.
+func (d *Handle) SaveTVShows(shows []*types.TVShow) (int, int, error) {
+	added, updated := 0, 0
+	tx := d.DB.Begin()
+	for _, s := range shows {
+		dbshow, err := findTVShowByIDs(tx, s)
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		if dbshow != nil {
+			s.ID = dbshow.ID
+			// Dumps only know their own IDs, keep the ones from the others.
+			if s.TVMazeID == 0 {
+				s.TVMazeID = dbshow.TVMazeID
+			}
+			if s.TVDBID == 0 {
+				s.TVDBID = dbshow.TVDBID
+			}
+			if s.TVRageID == 0 {
+				s.TVRageID = dbshow.TVRageID
+			}
+			updated++
+		} else {
+			added++
+		}
+		s.TitleKey = processing.NameKey(s.Title)
+		err = tx.Save(s).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		err = tx.Where("tv_show_id = ?", s.ID).Delete(types.TVShowAlias{}).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		for _, a := range s.Aliases {
+			alias := &types.TVShowAlias{
+				TVShowID: s.ID,
+				Alias:    a,
+				AliasKey: processing.NameKey(a),
+			}
+			if alias.AliasKey == "" || alias.AliasKey == s.TitleKey {
+				continue
+			}
+			err = tx.Save(alias).Error
+			if err != nil {
+				tx.Rollback()
+				return 0, 0, err
+			}
+		}
+	}
+	err := tx.Commit().Error
+	return added, updated, err
+}
+
+// CountTVShows returns the number of shows in the database.
+func (d *Handle) CountTVShows() (int64, error) {
+	var count int64
+	err := d.DB.Model(&types.TVShow{}).Count(&count).Error
+	return count, err
+}
+
+// findTVShow returns the show whose title or one of its aliases matches the
+// show name parsed from a release, or nil if there's none.  The show that
+// premiered in year is preferred when several match.
+func findTVShow(tx *gorm.DB, name, year string) (*types.TVShow, error) {
+	key := processing.NameKey(name)
+	if key == "" {
+		return nil, nil
+	}
+	var shows []types.TVShow
+	err := tx.Where("title_key = ? OR id IN (SELECT tv_show_id FROM tv_show_alias WHERE alias_key = ?)", key, key).Order("id").Find(&shows).Error
+	if err != nil || len(shows) == 0 {
+		return nil, err
+	}
+	for i := range shows {
+		if year != "" && strconv.Itoa(shows[i].Year) == year {
+			return &shows[i], nil
+		}
+	}
+	return &shows[0], nil
+}
+
+// MatchTVShows matches the TV info of releases to shows imported after the
+// releases were made, batch releases at a time.  Returns the number of
+// releases checked and matched.
+func (d *Handle) MatchTVShows(batch int) (int, int, error) {
+	if batch < 1 {
+		batch = 1000
+	}
+	checked, matched := 0, 0
+	lastID := int64(0)
+	for {
+		var infos []types.TVInfo
+		err := d.DB.Where("tv_show_id IS NULL AND id > ?", lastID).Order("id").Limit(batch).Find(&infos).Error
+		if err != nil {
+			return checked, matched, err
+		}
+		if len(infos) == 0 {
+			break
+		}
+		lastID = infos[len(infos)-1].ID
+		for _, info := range infos {
+			checked++
+			var rel types.Release
+			err = d.DB.Select("id, name").First(&rel, info.ReleaseID).Error
+			if err != nil {
+				return checked, matched, err
+			}
+			_, year := parseTVInfo(rel.ID, rel.Name)
+			show, err := findTVShow(&d.DB, info.ShowName, year)
+			if err != nil {
+				return checked, matched, err
+			}
+			if show == nil {
+				continue
+			}
+			err = d.DB.Model(types.TVInfo{}).Where("id = ?", info.ID).UpdateColumn("tv_show_id", show.ID).Error
+			if err != nil {
+				return checked, matched, err
+			}
+			matched++
+		}
+		logrus.Infof("Matched %d of %d releases to TV shows", matched, checked)
+	}
+	return checked, matched, nil
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestSaveAndMatchTVShows(t *testing.T) {
+	RegisterTestingT(t)
+	dbh := NewMemoryDBHandle(false, false)
+
+	tvHD := sql.NullInt64{Int64: int64(types.TV_HD), Valid: true}
+	early := &types.Release{Name: "Agents.of.SHIELD.S03E01.720p.HDTV.x264-KILLERS", Hash: "h1", CategoryID: tvHD}
+	err := dbh.DB.Save(early).Error
+	Expect(err).ToNot(HaveOccurred())
+	_, _, err = dbh.BackfillTVInfo(10)
+	Expect(err).ToNot(HaveOccurred())
+
+	shows := []*types.TVShow{
+		{TVMazeID: 31, TVDBID: 263365, Title: "Marvel's Agents of S.H.I.E.L.D.", Year: 2013, Aliases: []string{"Agents of SHIELD"}},
+		{TVMazeID: 13, TVDBID: 279121, TVRageID: 36939, Title: "The Flash", Year: 2014},
+		{TVMazeID: 1000, TVDBID: 78650, Title: "The Flash", Year: 1990},
+	}
+	added, updated, err := dbh.SaveTVShows(shows)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(added).To(Equal(3))
+	Expect(updated).To(Equal(0))
+
+	// Known IDs update the show and replace its aliases.
+	added, updated, err = dbh.SaveTVShows([]*types.TVShow{
+		{TVDBID: 263365, Title: "Marvel's Agents of S.H.I.E.L.D.", Country: "US", Year: 2013, Aliases: []string{"Agents of SHIELD", "SHIELD"}},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(added).To(Equal(0))
+	Expect(updated).To(Equal(1))
+	count, err := dbh.CountTVShows()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(count).To(Equal(int64(3)))
+	var shield types.TVShow
+	err = dbh.DB.First(&shield, shows[0].ID).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(shield.TVMazeID).To(Equal(int64(31)))
+	Expect(shield.TVDBID).To(Equal(int64(263365)))
+	Expect(shield.Country).To(Equal("US"))
+	var aliases []types.TVShowAlias
+	err = dbh.DB.Where("tv_show_id = ?", shows[0].ID).Find(&aliases).Error
+	Expect(err).ToNot(HaveOccurred())
+	Expect(aliases).To(HaveLen(2))
+
+	// Releases made before the import are matched by alias.
+	checked, matched, err := dbh.MatchTVShows(10)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(checked).To(Equal(1))
+	Expect(matched).To(Equal(1))
+	info, err := dbh.GetTVInfo(early.ID)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(info.TVShowID.Int64).To(Equal(shows[0].ID))
+
+	// New releases are matched when they're made, the year picks the show.
+	releases := []*types.Release{
+		{Name: "The.Flash.2014.S02E01.720p.HDTV.x264-KILLERS", Hash: "h2", CategoryID: tvHD},
+		{Name: "The.Flash.1990.S01E01.DVDRip.XviD-GRP", Hash: "h3", CategoryID: tvHD},
+		{Name: "Marvels.Agents.of.S.H.I.E.L.D.S03E02.720p.HDTV.x264-AVS", Hash: "h4", CategoryID: tvHD},
+	}
+	for i, rel := range releases {
+		err = dbh.DB.Save(rel).Error
+		Expect(err).ToNot(HaveOccurred())
+		_, err = saveTVInfo(&dbh.DB, rel.ID, rel.Name, types.TV_HD)
+		Expect(err).ToNot(HaveOccurred())
+		info, err = dbh.GetTVInfo(rel.ID)
+		Expect(err).ToNot(HaveOccurred())
+		expected := []int64{shows[1].ID, shows[2].ID, shows[0].ID}[i]
+		Expect(info.TVShowID.Int64).To(Equal(expected), rel.Name)
+	}
+
+	found, err := dbh.SearchTVReleases(TVSearch{TVDBID: 263365, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(2))
+
+	found, err = dbh.SearchTVReleases(TVSearch{TVRageID: 36939, Season: "2", Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[0].ID))
+
+	found, err = dbh.SearchTVReleases(TVSearch{TVMazeID: 99, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeEmpty())
+
+	// The Flash from 1990 has no TVRage ID, its TVDB ID finds it.
+	found, err = dbh.SearchTVReleases(TVSearch{TVDBID: 78650, TVRageID: 5555, Limit: 10})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(HaveLen(1))
+	Expect(found[0].ID).To(Equal(releases[1].ID))
+}
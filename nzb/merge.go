@@ -0,0 +1,66 @@
+package nzb
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	quotedFileNameRegex = regexp.MustCompile(`"(.+?)"`)
+	segmentCountRegex   = regexp.MustCompile(`[\[(]\d+\/\d+[\])]`)
+)
+
+// fileKey returns what identifies a file across posts: the file name in its
+// subject, or the subject without segment counts if it doesn't quote one.
+func fileKey(f File) string {
+	if m := quotedFileNameRegex.FindStringSubmatch(f.Subject); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return strings.TrimSpace(segmentCountRegex.ReplaceAllString(f.Subject, ""))
+}
+
+// Merge adds the files and segments of extra that are missing from nz, for
+// example when extra is a repost of an incomplete release.  Returns the
+// number of segments added.
+func (nz *NZB) Merge(extra *NZB) int {
+	files := map[string]int{}
+	for i, f := range nz.Files {
+		files[fileKey(f)] = i
+	}
+	added := 0
+	for _, ef := range extra.Files {
+		i, ok := files[fileKey(ef)]
+		if !ok {
+			nz.Files = append(nz.Files, ef)
+			files[fileKey(ef)] = len(nz.Files) - 1
+			added += len(ef.Segments)
+			continue
+		}
+		have := map[int]bool{}
+		for _, seg := range nz.Files[i].Segments {
+			have[seg.Number] = true
+		}
+		for _, seg := range ef.Segments {
+			if !have[seg.Number] {
+				nz.Files[i].Segments = append(nz.Files[i].Segments, seg)
+				have[seg.Number] = true
+				added++
+			}
+		}
+		sort.Sort(segmentSlice(nz.Files[i].Segments))
+	}
+	sort.Sort(fileSlice(nz.Files))
+	return added
+}
+
+// Size returns the number of bytes in all segments of nz.
+func (nz *NZB) Size() int64 {
+	var size int64
+	for _, f := range nz.Files {
+		for _, seg := range f.Segments {
+			size += seg.Bytes
+		}
+	}
+	return size
+}
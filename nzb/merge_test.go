@@ -0,0 +1,78 @@
+package nzb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hobeone/gonab/types"
+)
+
+func testBinary(subject string, segments ...int) *types.Binary {
+	part := types.Part{
+		Subject:   subject,
+		From:      "test@foo.bar",
+		Posted:    time.Unix(0, 0),
+		GroupName: "misc.test",
+	}
+	for _, n := range segments {
+		part.Segments = append(part.Segments, types.Segment{
+			MessageID: subject + string(rune('a'+n)) + "@foo.bar",
+			Size:      100,
+			Segment:   n,
+		})
+	}
+	return &types.Binary{Name: "TestBinary", Parts: []types.Part{part}}
+}
+
+func TestMerge(t *testing.T) {
+	orig, err := WriteNZB(testBinary(`Test [1/2] - "test.r00" yEnc (1/3)`, 1, 3))
+	if err != nil {
+		t.Fatalf("Error creating NZB: %v", err)
+	}
+	repost, err := WriteNZB(testBinary(`Repost [1/2] - "test.r00" yEnc (1/3)`, 1, 2, 3))
+	if err != nil {
+		t.Fatalf("Error creating NZB: %v", err)
+	}
+	extraFile, err := WriteNZB(testBinary(`Repost [2/2] - "test.r01" yEnc (1/1)`, 1))
+	if err != nil {
+		t.Fatalf("Error creating NZB: %v", err)
+	}
+
+	nz, err := ParseNZB(orig)
+	if err != nil {
+		t.Fatalf("Error parsing NZB: %v", err)
+	}
+	for _, doc := range []string{repost, extraFile} {
+		other, err := ParseNZB(doc)
+		if err != nil {
+			t.Fatalf("Error parsing NZB: %v", err)
+		}
+		if added := nz.Merge(other); added != 1 {
+			t.Errorf("Expected 1 segment to be added, got %d", added)
+		}
+	}
+
+	if len(nz.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(nz.Files))
+	}
+	// Files are sorted by subject, the repost of test.r01 comes first.
+	segs := nz.Files[1].Segments
+	if len(segs) != 3 || segs[1].Number != 2 || segs[1].ID != `Repost [1/2] - "test.r00" yEnc (1/3)c@foo.bar` {
+		t.Errorf("Unexpected segments after merge: %+v", segs)
+	}
+	if nz.Size() != 400 {
+		t.Errorf("Expected size 400, got %d", nz.Size())
+	}
+
+	doc, err := EncodeNZB(nz)
+	if err != nil {
+		t.Fatalf("Error encoding NZB: %v", err)
+	}
+	again, err := ParseNZB(doc)
+	if err != nil {
+		t.Fatalf("Error parsing merged NZB: %v", err)
+	}
+	if len(again.Files) != 2 || again.Size() != 400 {
+		t.Errorf("Merged NZB didn't round trip: %s", doc)
+	}
+}
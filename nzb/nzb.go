@@ -97,7 +97,11 @@ func WriteNZB(b *types.Binary) (string, error) {
 		}
 	}
 	sort.Sort(fileSlice(nz.Files))
+	return EncodeNZB(&nz)
+}
 
+// EncodeNZB returns nz as an NZB document.
+func EncodeNZB(nz *NZB) (string, error) {
 	xmlWriter := bytes.NewBufferString("")
 	xmlWriter.WriteString(nzbHeader)
 
@@ -108,3 +112,16 @@ func WriteNZB(b *types.Binary) (string, error) {
 	}
 	return xmlWriter.String() + "\n", nil
 }
+
+// ParseNZB parses an NZB document as written by WriteNZB.
+func ParseNZB(doc string) (*NZB, error) {
+	nz := &NZB{}
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	// The DOCTYPE is skipped, entities are the only thing it could add.
+	dec.Strict = false
+	err := dec.Decode(nz)
+	if err != nil {
+		return nil, err
+	}
+	return nz, nil
+}
@@ -136,3 +136,23 @@ func TestNZBCreate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestNZBParse(t *testing.T) {
+	nz, err := ParseNZB(goldenOutput)
+	if err != nil {
+		t.Fatalf("Error parsing NZB: %v", err)
+	}
+	if len(nz.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(nz.Files))
+	}
+	f := nz.Files[0]
+	if f.Subject != "TestBinary.r01" || f.Poster != "test@foo.bar" {
+		t.Errorf("Unexpected first file: %+v", f)
+	}
+	if len(f.Segments) != 2 || f.Segments[0].ID != "123456@foo.bar" || f.Segments[0].Bytes != 12356 {
+		t.Errorf("Unexpected segments: %+v", f.Segments)
+	}
+	if len(f.Groups) != 1 || f.Groups[0] != "misc.test" {
+		t.Errorf("Unexpected groups: %v", f.Groups)
+	}
+}
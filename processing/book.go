@@ -0,0 +1,127 @@
+package processing
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Author - Title (2016) (epub), the names of the ebooks release cleaner.
+	bookSpacedRegex = regexp.MustCompile(`^(?P<author>.+?) +- +(?P<title>.+?)(?:[ ._]*[(\[{]|[ ._]+(?:19|20)\d{2}(?:[^0-9]|$)|\.[a-zA-Z0-9]{3,4}$|$)`)
+	// Author-Title-Tags-Group, scene names with . or _ for spaces.
+	bookSceneRegex = regexp.MustCompile(`^(?P<author>[^- ]+)-(?P<title>[^- ]+)-(?P<tags>[^ ]+)$`)
+	// The year and tags that end the title.
+	bookTagRegex = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:(?:19|20)\d{2}|RETAIL|e-?books?|EPUB|MOBI|AZW3?|PDF|DJVU|CHM|M4B|MP3|Audio[-._ ]?books?|ABOOK|Unabridged|Abridged)(?:[^a-z0-9]|$)`)
+
+	bookYearRegex   = regexp.MustCompile(`(?:^|[^a-zA-Z0-9])((?:19|20)\d{2})(?:[^a-zA-Z0-9]|$)`)
+	bookFormatRegex = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(EPUB|MOBI|AZW3?|PDF|DJVU|CHM|CBR|CBZ|M4B|MP3|AAC|FLAC)(?:[^a-z0-9]|$)`)
+)
+
+// bookEvidence are the tags telling audiobooks from ebooks and how much
+// each one counts.
+var bookEvidence = []struct {
+	regex  *regexp.Regexp
+	audio  bool
+	weight float64
+}{
+	{regexp.MustCompile(`(?i)(?:^|[^a-z0-9])M4B(?:[^a-z0-9]|$)`), true, 3},
+	{regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:MP3|M4A|AAC|OGG|FLAC|\d{2,3}[-._ ]?kbps)(?:[^a-z0-9]|$)`), true, 1},
+	{regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:Audio[-._ ]?books?|ABOOK|H(?:oe|ö)rbuch|Unabridged|Abridged|Narrated[-._ ]by|Read[-._ ]by)(?:[^a-z0-9]|$)`), true, 1},
+	{regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:EPUB|MOBI|AZW3?|PDF|DJVU|CHM|CBR|CBZ)(?:[^a-z0-9]|$)`), false, 2},
+	{regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:e-?books?|RETAIL)(?:[^a-z0-9]|$)`), false, 1},
+}
+
+// BookNameParseResult represents extracted information from an ebook or
+// audiobook release name.  Fields that aren't in the name are empty.
+type BookNameParseResult struct {
+	Author string
+	Title  string
+	Year   int
+	Format string // e.g. EPUB, MOBI, PDF or M4B.
+	// AudiobookConfidence is how sure the parser is that the release is an
+	// audiobook rather than an ebook, from 0 to 1.  Names without any hint
+	// get 0.5.
+	AudiobookConfidence float64
+}
+
+// IsAudiobook returns whether the release is more likely an audiobook than an
+// ebook.
+func (r *BookNameParseResult) IsAudiobook() bool {
+	return r.AudiobookConfidence > 0.5
+}
+
+// cutBookTags returns s up to the first year or book tag after its start, so
+// titles like 2001 A Space Odyssey keep their number.
+func cutBookTags(s string) string {
+	// Matches share their delimiters, so search again after each one.
+	for start := 0; start < len(s); {
+		loc := bookTagRegex.FindStringIndex(s[start:])
+		if loc == nil {
+			break
+		}
+		if start+loc[0] > 0 {
+			return s[:start+loc[0]]
+		}
+		start += loc[1] - 1
+	}
+	return s
+}
+
+// audiobookConfidence weighs the audiobook tags of name against its ebook
+// tags.
+func audiobookConfidence(name string) float64 {
+	audio, ebook := 0.0, 0.0
+	for _, e := range bookEvidence {
+		if !e.regex.MatchString(name) {
+			continue
+		}
+		if e.audio {
+			audio += e.weight
+		} else {
+			ebook += e.weight
+		}
+	}
+	if audio+ebook == 0 {
+		return 0.5
+	}
+	return audio / (audio + ebook)
+}
+
+// ParseBookName returns information about the given ebook or audiobook
+// release.  It understands the Author - Title (Year) names of the release
+// cleaners and Author-Title-Tags-Group scene names.  Names like
+// Title.2016.RETAIL.EBOOK-GRP are parsed without an author.
+func ParseBookName(name string) (*BookNameParseResult, error) {
+	res := &BookNameParseResult{}
+	var rest string
+	if m := bookSpacedRegex.FindStringSubmatchIndex(name); m != nil {
+		res.Author, res.Title = name[m[2]:m[3]], name[m[4]:m[5]]
+		rest = name[m[5]:]
+	} else if m := bookSceneRegex.FindStringSubmatchIndex(name); m != nil {
+		res.Author, res.Title = name[m[2]:m[3]], name[m[4]:m[5]]
+		rest = name[m[5]:]
+	} else {
+		// Without an author the title has to end at a tag.
+		loc := bookTagRegex.FindStringIndex(name)
+		if loc == nil || loc[0] == 0 {
+			return nil, fmt.Errorf("Error parsing %s", name)
+		}
+		res.Title, rest = name[:loc[0]], name[loc[0]:]
+	}
+	res.Author = cleanMusicField(res.Author)
+	res.Title = strings.Trim(cleanMusicField(cutBookTags(res.Title)), " -")
+	if res.Title == "" {
+		return nil, fmt.Errorf("Error parsing %s", name)
+	}
+
+	if m := bookYearRegex.FindStringSubmatch(rest); m != nil {
+		res.Year, _ = strconv.Atoi(m[1])
+	}
+	if m := bookFormatRegex.FindStringSubmatch(name); m != nil {
+		res.Format = strings.ToUpper(m[1])
+	}
+	res.AudiobookConfidence = audiobookConfidence(name)
+	return res, nil
+}
@@ -0,0 +1,82 @@
+package processing
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestParseBookName(t *testing.T) {
+	expectations := map[string]*BookNameParseResult{
+		"Andy Weir - The Martian (2014) (retail) (epub)": {
+			Author: "Andy Weir", Title: "The Martian", Year: 2014, Format: "EPUB", AudiobookConfidence: 0,
+		},
+		"Stephen King - It (2016) [M4B]": {
+			Author: "Stephen King", Title: "It", Year: 2016, Format: "M4B", AudiobookConfidence: 1,
+		},
+		"Brandon Sanderson - Mistborn 01 - The Final Empire Unabridged MP3": {
+			Author: "Brandon Sanderson", Title: "Mistborn 01 - The Final Empire", Format: "MP3", AudiobookConfidence: 1,
+		},
+		"Stephen.King-The.Stand.Unabridged-AUDIOBOOK": {
+			Author: "Stephen King", Title: "The Stand", AudiobookConfidence: 1,
+		},
+		"Neil_Gaiman-American_Gods-RETAIL-EPUB-eBook-GRP": {
+			Author: "Neil Gaiman", Title: "American Gods", Format: "EPUB", AudiobookConfidence: 0,
+		},
+		"OReilly.Learning.Go.2016.RETAIL.EPUB.eBook-GRP": {
+			Title: "OReilly Learning Go", Year: 2016, Format: "EPUB", AudiobookConfidence: 0,
+		},
+		"Arthur C. Clarke - 2001 A Space Odyssey Unabridged MP3": {
+			Author: "Arthur C. Clarke", Title: "2001 A Space Odyssey", Format: "MP3", AudiobookConfidence: 1,
+		},
+		"Author - Title": {
+			Author: "Author", Title: "Title", AudiobookConfidence: 0.5,
+		},
+		"Author - Title (Audiobook Companion) [PDF]": {
+			Author: "Author", Title: "Title", Format: "PDF", AudiobookConfidence: 1.0 / 3,
+		},
+		"Author - Title (Unabridged) [M4B + PDF]": {
+			Author: "Author", Title: "Title", Format: "M4B", AudiobookConfidence: 4.0 / 6,
+		},
+	}
+	for name, v := range expectations {
+		res, err := ParseBookName(name)
+		if err != nil {
+			t.Errorf("Error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(v, res) {
+			t.Errorf("Diff in Parse output for %s", name)
+			fmt.Println("Expected:")
+			spew.Dump(v)
+			fmt.Println("Got:")
+			spew.Dump(res)
+		}
+	}
+
+	for _, name := range []string{"Some Random Words", "RETAIL.EPUB-GRP"} {
+		if res, err := ParseBookName(name); err == nil {
+			t.Errorf("Expected %s not to parse, got %+v", name, res)
+		}
+	}
+}
+
+func TestBookIsAudiobook(t *testing.T) {
+	tests := map[string]bool{
+		"Stephen King - It (2016) [M4B]":             true,
+		"Author - Title (Audiobook Companion) [PDF]": false,
+		"Author - Title":                             false,
+		"Author - Title (Audiobook) [eBook]":         false,
+	}
+	for name, audio := range tests {
+		res, err := ParseBookName(name)
+		if err != nil {
+			t.Fatalf("Error %v", err)
+		}
+		if res.IsAudiobook() != audio {
+			t.Errorf("%s: expected IsAudiobook %v, confidence %v", name, audio, res.AudiobookConfidence)
+		}
+	}
+}
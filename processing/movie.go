@@ -0,0 +1,133 @@
+package processing
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// A year between delimiters, the last one in a name is the release year so
+	// titles like 2001.A.Space.Odyssey.1968 keep their number.
+	movieYearRegex = regexp.MustCompile(`(?i)[^a-z0-9]\(?((?:19|20)\d{2})\)?(?:[^a-z0-9]|$)`)
+	// Tags that end the title of movies without a year.
+	movieTagRegex = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:\d{3,4}[pi]|4K|UHD|Blu-?Ray|BD(?:Rip|25|50)?|BRRip|WEB[-_. ]?(?:DL|Rip)?|HDTV|HDRip|DVD(?:Rip|SCR|R|9|5)?|NTSC|PAL|R5|CAM|TS|TC|x26[45]|[hx][-_. ]?26[45]|HEVC|XviD|DivX|PROPER|REPACK|LIMITED|UNRATED|EXTENDED|REMUX|(?:TRUE)?FRENCH|GERMAN|MULTi)(?:[^a-z0-9]|$)`)
+
+	movieResolutionRegex = regexp.MustCompile(`(?i)[^a-z0-9](\d{3,4})([pi])(?:[^a-z0-9]|$)|[^a-z0-9](4K|UHD)(?:[^a-z0-9]|$)`)
+	movieGroupRegex      = regexp.MustCompile(`(?i)-([a-z0-9]+)(?:\.[a-z0-9]{2,4})?$`)
+	movieCleanerRegex    = regexp.MustCompile(`[._]`)
+)
+
+// movieSources maps the source tags of release names to the name they're
+// stored as.  They're tried in order, the first match wins.
+var movieSources = []struct {
+	source string
+	regex  *regexp.Regexp
+}{
+	{"Remux", regexp.MustCompile(`(?i)[^a-z0-9]Remux(?:[^a-z0-9]|$)`)},
+	{"BluRay", regexp.MustCompile(`(?i)[^a-z0-9](?:Blu-?Ray|BD(?:25|50)?|BDRip|BRRip)(?:[^a-z0-9]|$)`)},
+	{"WEB-DL", regexp.MustCompile(`(?i)[^a-z0-9]WEB[-_. ]?DL(?:[^a-z0-9]|$)`)},
+	{"WEBRip", regexp.MustCompile(`(?i)[^a-z0-9](?:WEB[-_. ]?Rip|WEB)(?:[^a-z0-9]|$)`)},
+	{"HDTV", regexp.MustCompile(`(?i)[^a-z0-9]HDTV(?:[^a-z0-9]|$)`)},
+	{"HDRip", regexp.MustCompile(`(?i)[^a-z0-9]HDRip(?:[^a-z0-9]|$)`)},
+	{"DVDScr", regexp.MustCompile(`(?i)[^a-z0-9]DVD-?SCR(?:[^a-z0-9]|$)`)},
+	{"DVDRip", regexp.MustCompile(`(?i)[^a-z0-9]DVDRip(?:[^a-z0-9]|$)`)},
+	{"DVD", regexp.MustCompile(`(?i)[^a-z0-9](?:DVD(?:R|5|9)?|NTSC|PAL)(?:[^a-z0-9]|$)`)},
+	{"R5", regexp.MustCompile(`(?i)[^a-z0-9]R5(?:[^a-z0-9]|$)`)},
+	{"Telecine", regexp.MustCompile(`(?i)[^a-z0-9](?:TC|Telecine)(?:[^a-z0-9]|$)`)},
+	{"Telesync", regexp.MustCompile(`(?i)[^a-z0-9](?:TS|HDTS|Telesync)(?:[^a-z0-9]|$)`)},
+	{"CAM", regexp.MustCompile(`(?i)[^a-z0-9](?:CAM|HDCAM)(?:[^a-z0-9]|$)`)},
+}
+
+// movieCodecs maps the codec tags of release names to the name they're
+// stored as.
+var movieCodecs = []struct {
+	codec string
+	regex *regexp.Regexp
+}{
+	{"x265", regexp.MustCompile(`(?i)[^a-z0-9](?:[hx][-_. ]?265|HEVC)(?:[^a-z0-9]|$)`)},
+	{"x264", regexp.MustCompile(`(?i)[^a-z0-9](?:[hx][-_. ]?264|AVC)(?:[^a-z0-9]|$)`)},
+	{"XviD", regexp.MustCompile(`(?i)[^a-z0-9]XviD(?:[^a-z0-9]|$)`)},
+	{"DivX", regexp.MustCompile(`(?i)[^a-z0-9]DivX(?:[^a-z0-9]|$)`)},
+	{"VC-1", regexp.MustCompile(`(?i)[^a-z0-9]VC-?1(?:[^a-z0-9]|$)`)},
+	{"MPEG2", regexp.MustCompile(`(?i)[^a-z0-9]MPEG-?2(?:[^a-z0-9]|$)`)},
+}
+
+// MovieNameParseResult represents extracted information from a movie release
+// name.  Fields that aren't in the name are empty.
+type MovieNameParseResult struct {
+	Title      string
+	Year       int
+	Resolution string // e.g. 1080p, 4K releases are 2160p.
+	Source     string // e.g. BluRay or WEB-DL.
+	Codec      string // e.g. x264.
+	Group      string
+}
+
+// ParseMovieName returns information about the given movie release.  The
+// title ends at the release year or, for movies without one, at the first
+// quality tag.
+func ParseMovieName(name string) (*MovieNameParseResult, error) {
+	res := &MovieNameParseResult{}
+	end := -1
+	// Matches share their delimiters, so search again after each one.  The
+	// year can't start the title.
+	for start := 0; start < len(name); {
+		loc := movieYearRegex.FindStringSubmatchIndex(name[start:])
+		if loc == nil {
+			break
+		}
+		if start+loc[0] > 0 {
+			end = start + loc[0]
+			res.Year, _ = strconv.Atoi(name[start+loc[2] : start+loc[3]])
+		}
+		start += loc[2]
+	}
+	if end < 0 {
+		loc := movieTagRegex.FindStringIndex(name)
+		if loc == nil {
+			return nil, fmt.Errorf("Error parsing %s", name)
+		}
+		end = loc[0]
+	}
+	title := movieCleanerRegex.ReplaceAllString(name[:end], " ")
+	res.Title = strings.Trim(strings.Join(strings.Fields(title), " "), " -([")
+	if res.Title == "" {
+		return nil, fmt.Errorf("Error parsing %s", name)
+	}
+
+	if m := movieResolutionRegex.FindStringSubmatch(name); m != nil {
+		if m[3] != "" {
+			res.Resolution = "2160p"
+		} else {
+			res.Resolution = m[1] + strings.ToLower(m[2])
+		}
+	}
+	for _, s := range movieSources {
+		if s.regex.MatchString(name) {
+			res.Source = s.source
+			break
+		}
+	}
+	for _, c := range movieCodecs {
+		if c.regex.MatchString(name) {
+			res.Codec = c.codec
+			break
+		}
+	}
+	if m := movieGroupRegex.FindStringSubmatch(name); m != nil {
+		res.Group = m[1]
+	}
+	return res, nil
+}
+
+// ParseIMDBID parses an IMDB ID with or without its tt prefix, e.g. tt0111161
+// or 0111161.
+func ParseIMDBID(s string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.ToLower(s), "tt"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid IMDB id %q", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,89 @@
+package processing
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestParseMovieName(t *testing.T) {
+	expectations := map[string]*MovieNameParseResult{
+		"The.Martian.2015.1080p.BluRay.x264-SPARKS": {
+			Title: "The Martian", Year: 2015, Resolution: "1080p", Source: "BluRay", Codec: "x264", Group: "SPARKS",
+		},
+		"2001.A.Space.Odyssey.1968.720p.BluRay.DTS.x264-ESiR": {
+			Title: "2001 A Space Odyssey", Year: 1968, Resolution: "720p", Source: "BluRay", Codec: "x264", Group: "ESiR",
+		},
+		"Blade.Runner.2049.2017.2160p.UHD.BluRay.REMUX.HDR.HEVC.Atmos-EPSiLON": {
+			Title: "Blade Runner 2049", Year: 2017, Resolution: "2160p", Source: "Remux", Codec: "x265", Group: "EPSiLON",
+		},
+		"Mad Max Fury Road (2015) 1080p WEB-DL DD5.1 H264-FGT": {
+			Title: "Mad Max Fury Road", Year: 2015, Resolution: "1080p", Source: "WEB-DL", Codec: "x264", Group: "FGT",
+		},
+		"1917.2019.1080p.WEB-DL.DD5.1.H264-FGT": {
+			Title: "1917", Year: 2019, Resolution: "1080p", Source: "WEB-DL", Codec: "x264", Group: "FGT",
+		},
+		"Inside.Out.2015.DVDRip.XviD-EVO": {
+			Title: "Inside Out", Year: 2015, Source: "DVDRip", Codec: "XviD", Group: "EVO",
+		},
+		"Spectre.2015.HDCAM.x264-GRP": {
+			Title: "Spectre", Year: 2015, Source: "CAM", Codec: "x264", Group: "GRP",
+		},
+		"The_Revenant_2015_DVDSCR_x264-GRP": {
+			Title: "The Revenant", Year: 2015, Source: "DVDScr", Codec: "x264", Group: "GRP",
+		},
+		"Amelie.2001.FRENCH.1080p.BluRay.x264-LOST": {
+			Title: "Amelie", Year: 2001, Resolution: "1080p", Source: "BluRay", Codec: "x264", Group: "LOST",
+		},
+		"Heat.1995.4K.WEBRip.x265-GRP": {
+			Title: "Heat", Year: 1995, Resolution: "2160p", Source: "WEBRip", Codec: "x265", Group: "GRP",
+		},
+		"Casablanca.NTSC.DVDR-GRP": {
+			Title: "Casablanca", Source: "DVD", Group: "GRP",
+		},
+		"Alien.Directors.Cut.720p.BluRay.x264-GRP": {
+			Title: "Alien Directors Cut", Resolution: "720p", Source: "BluRay", Codec: "x264", Group: "GRP",
+		},
+	}
+	for name, v := range expectations {
+		res, err := ParseMovieName(name)
+		if err != nil {
+			t.Errorf("Error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(v, res) {
+			t.Errorf("Diff in Parse output for %s", name)
+			fmt.Println("Expected:")
+			spew.Dump(v)
+			fmt.Println("Got:")
+			spew.Dump(res)
+		}
+	}
+
+	for _, name := range []string{"Some Random Words", "1080p.BluRay.x264-GRP"} {
+		if res, err := ParseMovieName(name); err == nil {
+			t.Errorf("Expected %s not to parse, got %v", name, res)
+		}
+	}
+}
+
+func TestParseIMDBID(t *testing.T) {
+	expectations := map[string]int64{
+		"tt0111161": 111161,
+		"0111161":   111161,
+		"TT4154796": 4154796,
+	}
+	for s, id := range expectations {
+		res, err := ParseIMDBID(s)
+		if err != nil || res != id {
+			t.Errorf("Expected %s to parse to %d got %d (%v)", s, id, res, err)
+		}
+	}
+	for _, s := range []string{"", "tt", "nm0000151", "0"} {
+		if res, err := ParseIMDBID(s); err == nil {
+			t.Errorf("Expected %s not to parse, got %d", s, res)
+		}
+	}
+}
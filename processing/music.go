@@ -0,0 +1,86 @@
+package processing
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Artist - Album (2015) [FLAC], the names of the sounds release cleaner.
+	musicSpacedRegex = regexp.MustCompile(`^(?P<artist>.+?) +- +(?P<album>.+?)(?:[ ._]*[(\[]|[ ._]+(?:19|20)\d{2}(?:[^0-9]|$)|$)`)
+	// Artist-Album-WEB-2016-GRP, scene names with _ for spaces.
+	musicSceneRegex = regexp.MustCompile(`^(?P<artist>[^- ]+)-(?P<album>[^- ]+)(?:-(?P<tags>[^ ]+))?-(?P<group>[a-zA-Z0-9]+)$`)
+
+	musicYearRegex    = regexp.MustCompile(`(?:^|[^a-zA-Z0-9])((?:19|20)\d{2})(?:[^a-zA-Z0-9]|$)`)
+	musicFormatRegex  = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(FLAC|MP3|AAC|OGG|ALAC|WAV|APE|M4A|WMA|DSD)(?:[^a-z0-9]|$)`)
+	musicBitrateRegex = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:(\d{2,3})[ ._-]?kbps|(V0|V1|V2|VBR|CBR|APS|APX)|(16|24)[ ._-]?bit)(?:[^a-z0-9]|$)`)
+)
+
+// MusicNameParseResult represents extracted information from a music release
+// name.  Fields that aren't in the name are empty.
+type MusicNameParseResult struct {
+	Artist  string
+	Album   string
+	Year    int
+	Format  string // e.g. MP3 or FLAC.
+	Bitrate string // e.g. 320kbps, V0 or 24bit.
+	Group   string
+}
+
+// cleanMusicField turns the _ and . used for spaces in scene names into
+// spaces.
+func cleanMusicField(s string) string {
+	s = strings.Replace(s, "_", " ", -1)
+	if !strings.Contains(s, " ") {
+		s = strings.Replace(s, ".", " ", -1)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ParseMusicName returns information about the given music release.  It
+// understands the Artist - Album (Year) names of the release cleaners and
+// Artist-Album-Tags-Year-Group scene names.
+func ParseMusicName(name string) (*MusicNameParseResult, error) {
+	res := &MusicNameParseResult{}
+	scene := false
+	// The year is looked for after the album, which can be a year itself,
+	// e.g. Taylor_Swift-1989-2014-GRP.
+	rest := ""
+	if m := musicSpacedRegex.FindStringSubmatchIndex(name); m != nil {
+		res.Artist, res.Album = name[m[2]:m[3]], name[m[4]:m[5]]
+		rest = name[m[5]:]
+	} else if m := musicSceneRegex.FindStringSubmatchIndex(name); m != nil {
+		res.Artist, res.Album, res.Group = name[m[2]:m[3]], name[m[4]:m[5]], name[m[8]:m[9]]
+		rest = name[m[5]:]
+		scene = true
+	}
+	res.Artist = cleanMusicField(res.Artist)
+	res.Album = strings.Trim(cleanMusicField(res.Album), " -")
+	if res.Artist == "" || res.Album == "" {
+		return nil, fmt.Errorf("Error parsing %s", name)
+	}
+
+	if m := musicYearRegex.FindStringSubmatch(rest); m != nil {
+		res.Year, _ = strconv.Atoi(m[1])
+	}
+	if m := musicFormatRegex.FindStringSubmatch(name); m != nil {
+		res.Format = strings.ToUpper(m[1])
+	}
+	if m := musicBitrateRegex.FindStringSubmatch(name); m != nil {
+		switch {
+		case m[1] != "":
+			res.Bitrate = m[1] + "kbps"
+		case m[2] != "":
+			res.Bitrate = strings.ToUpper(m[2])
+		default:
+			res.Bitrate = m[3] + "bit"
+		}
+	}
+	// Scene only tags lossless releases, and MP3 bitrates.
+	if res.Format == "" && (scene || strings.HasSuffix(res.Bitrate, "kbps") || strings.HasPrefix(res.Bitrate, "V")) {
+		res.Format = "MP3"
+	}
+	return res, nil
+}
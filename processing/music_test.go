@@ -0,0 +1,64 @@
+package processing
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestParseMusicName(t *testing.T) {
+	expectations := map[string]*MusicNameParseResult{
+		"Daft Punk - Random Access Memories": {
+			Artist: "Daft Punk", Album: "Random Access Memories",
+		},
+		"Adele - 25 (2015) [FLAC]": {
+			Artist: "Adele", Album: "25", Year: 2015, Format: "FLAC",
+		},
+		"Taylor Swift - 1989 (2014) MP3 320kbps": {
+			Artist: "Taylor Swift", Album: "1989", Year: 2014, Format: "MP3", Bitrate: "320kbps",
+		},
+		"Radiohead - OK Computer OKNOTOK 1997 2017 [24bit FLAC]": {
+			Artist: "Radiohead", Album: "OK Computer OKNOTOK", Year: 1997, Format: "FLAC", Bitrate: "24bit",
+		},
+		"Artist-Album-WEB-2016-GRP": {
+			Artist: "Artist", Album: "Album", Year: 2016, Format: "MP3", Group: "GRP",
+		},
+		"Daft_Punk-Random_Access_Memories-2013-FLAC-GRP": {
+			Artist: "Daft Punk", Album: "Random Access Memories", Year: 2013, Format: "FLAC", Group: "GRP",
+		},
+		"Taylor_Swift-1989-(Deluxe_Edition)-2014-V0-GRP": {
+			Artist: "Taylor Swift", Album: "1989", Year: 2014, Format: "MP3", Bitrate: "V0", Group: "GRP",
+		},
+		"Armin_van_Buuren-A_State_Of_Trance_Episode_750-SAT-02-12-2016-GRP": {
+			Artist: "Armin van Buuren", Album: "A State Of Trance Episode 750", Year: 2016, Format: "MP3", Group: "GRP",
+		},
+		"VA-Ministry_Of_Sound_Anthems-3CD-2015-GRP": {
+			Artist: "VA", Album: "Ministry Of Sound Anthems", Year: 2015, Format: "MP3", Group: "GRP",
+		},
+		"The.Beatles-Abbey.Road-Remastered-CD-FLAC-2009-GRP": {
+			Artist: "The Beatles", Album: "Abbey Road", Year: 2009, Format: "FLAC", Group: "GRP",
+		},
+	}
+	for name, v := range expectations {
+		res, err := ParseMusicName(name)
+		if err != nil {
+			t.Errorf("Error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(v, res) {
+			t.Errorf("Diff in Parse output for %s", name)
+			fmt.Println("Expected:")
+			spew.Dump(v)
+			fmt.Println("Got:")
+			spew.Dump(res)
+		}
+	}
+
+	for _, name := range []string{"Some Random Words", "The.Martian.2015.1080p.BluRay.x264-SPARKS"} {
+		if res, err := ParseMusicName(name); err == nil {
+			t.Errorf("Expected %s not to parse, got %v", name, res)
+		}
+	}
+}
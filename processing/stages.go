@@ -0,0 +1,185 @@
+package processing
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hobeone/gonab/nzb"
+	"github.com/hobeone/gonab/types"
+)
+
+// Stage is a post processing step.  Releases in state From are run through
+// it and move to state To when Run succeeds.  Run returns a short note of
+// what it found.
+type Stage struct {
+	Name string
+	From int
+	To   int
+	Run  func(rel *types.Release) (string, error)
+}
+
+// HideError is returned by a stage to hide the release it ran on instead of
+// retrying the stage.
+type HideError struct {
+	Reason string
+}
+
+func (e *HideError) Error() string {
+	return e.Reason
+}
+
+var stages = []Stage{
+	{Name: "nfo", From: types.ReleaseNew, To: types.ReleaseNFOChecked, Run: checkNFO},
+	{Name: "par2", From: types.ReleaseNFOChecked, To: types.ReleasePar2Checked, Run: checkPar2},
+	{Name: "password", From: types.ReleasePar2Checked, To: types.ReleasePasswordChecked, Run: checkPassword},
+	{Name: "metadata", From: types.ReleasePasswordChecked, To: types.ReleaseMetadataMatched, Run: matchMetadata},
+}
+
+// Stages returns the registered post processing stages in the order they
+// are run.
+func Stages() []Stage {
+	return stages
+}
+
+// MetadataMatcher looks up the metadata of a release.  It returns false if
+// the release isn't something it knows about.
+type MetadataMatcher func(rel *types.Release) (string, bool, error)
+
+var metadataMatchers = []MetadataMatcher{matchTV, matchMovie, matchMusic, matchBook}
+
+// RegisterMetadataMatcher adds a matcher to the metadata stage.  Matchers are
+// tried in the order they were registered until one knows the release.
+func RegisterMetadataMatcher(m MetadataMatcher) {
+	metadataMatchers = append(metadataMatchers, m)
+}
+
+// releaseFiles returns the subjects of the files in the NZB of rel.
+func releaseFiles(rel *types.Release) ([]string, error) {
+	nz, err := nzb.ParseNZB(rel.NZB)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing NZB: %v", err)
+	}
+	files := make([]string, len(nz.Files))
+	for i, f := range nz.Files {
+		files[i] = f.Subject
+	}
+	return files, nil
+}
+
+func countFiles(files []string, r *regexp.Regexp) int {
+	count := 0
+	for _, f := range files {
+		if r.MatchString(f) {
+			count++
+		}
+	}
+	return count
+}
+
+var (
+	nfoFileRegex  = regexp.MustCompile(`(?i)\.nfo\b`)
+	par2FileRegex = regexp.MustCompile(`(?i)\.par2\b`)
+	// Files that are only posted with passworded or malicious releases.
+	// Only whole file names count so releases named after a "Password" movie
+	// aren't hidden.
+	passwordFileRegex   = regexp.MustCompile(`(?i)(^|["/\\ ])(passwor[dt]|pw)\.txt\b`)
+	executableFileRegex = regexp.MustCompile(`(?i)\.(exe|lnk|scr|vbs)\b`)
+)
+
+// executablesExpected returns true for the categories whose releases may
+// contain executables.
+func executablesExpected(cat types.Category) bool {
+	parent := cat / 1000 * 1000
+	return parent == types.PC || parent == types.Console
+}
+
+func checkNFO(rel *types.Release) (string, error) {
+	files, err := releaseFiles(rel)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d nfo files", countFiles(files, nfoFileRegex)), nil
+}
+
+func checkPar2(rel *types.Release) (string, error) {
+	files, err := releaseFiles(rel)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d par2 files", countFiles(files, par2FileRegex)), nil
+}
+
+func checkPassword(rel *types.Release) (string, error) {
+	files, err := releaseFiles(rel)
+	if err != nil {
+		return "", err
+	}
+	exeOK := executablesExpected(rel.CategoryName())
+	for _, f := range files {
+		if passwordFileRegex.MatchString(f) || (!exeOK && executableFileRegex.MatchString(f)) {
+			return "", &HideError{Reason: fmt.Sprintf("suspicious file %q", f)}
+		}
+	}
+	return "no password", nil
+}
+
+func matchMetadata(rel *types.Release) (string, error) {
+	for _, m := range metadataMatchers {
+		note, ok, err := m(rel)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return note, nil
+		}
+	}
+	return "no metadata", nil
+}
+
+func matchTV(rel *types.Release) (string, bool, error) {
+	if rel.CategoryName().Parent() != types.TV {
+		return "", false, nil
+	}
+	res, err := ParseInfo(rel.Name)
+	if err != nil || res.Name == "" {
+		return "", false, nil
+	}
+	return fmt.Sprintf("tv show %q", res.Name), true, nil
+}
+
+func matchMovie(rel *types.Release) (string, bool, error) {
+	if rel.CategoryName().Parent() != types.Movies {
+		return "", false, nil
+	}
+	res, err := ParseMovieName(rel.Name)
+	if err != nil || res.Title == "" {
+		return "", false, nil
+	}
+	return fmt.Sprintf("movie %q (%d)", res.Title, res.Year), true, nil
+}
+
+func matchMusic(rel *types.Release) (string, bool, error) {
+	if rel.CategoryName().Parent() != types.Audio {
+		return "", false, nil
+	}
+	res, err := ParseMusicName(rel.Name)
+	if err != nil || res.Artist == "" {
+		return "", false, nil
+	}
+	return fmt.Sprintf("album %q by %q", res.Album, res.Artist), true, nil
+}
+
+func matchBook(rel *types.Release) (string, bool, error) {
+	if rel.CategoryName().Parent() != types.Books {
+		return "", false, nil
+	}
+	res, err := ParseBookName(rel.Name)
+	if err != nil || res.Title == "" {
+		return "", false, nil
+	}
+	kind := "book"
+	if res.IsAudiobook() {
+		kind = "audiobook"
+	}
+	return fmt.Sprintf("%s %q by %q", kind, res.Title, res.Author), true, nil
+}
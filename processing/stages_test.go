@@ -0,0 +1,107 @@
+package processing
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/hobeone/gonab/nzb"
+	"github.com/hobeone/gonab/types"
+)
+
+func releaseWithFiles(t *testing.T, cat types.Category, files ...string) *types.Release {
+	b := &types.Binary{Name: "test"}
+	for _, f := range files {
+		b.Parts = append(b.Parts, types.Part{Subject: f, GroupName: "misc.test"})
+	}
+	doc, err := nzb.WriteNZB(b)
+	if err != nil {
+		t.Fatalf("Error writing NZB: %v", err)
+	}
+	return &types.Release{
+		NZB:        doc,
+		CategoryID: sql.NullInt64{Int64: int64(cat), Valid: true},
+	}
+}
+
+func TestCheckNFOAndPar2(t *testing.T) {
+	rel := releaseWithFiles(t, types.TV_HD,
+		`Show [1/4] - "show.nfo" yEnc`,
+		`Show [2/4] - "show.par2" yEnc`,
+		`Show [3/4] - "show.vol00+01.par2" yEnc`,
+		`Show [4/4] - "show.mkv" yEnc`,
+	)
+	note, err := checkNFO(rel)
+	if err != nil || note != "1 nfo files" {
+		t.Errorf("Unexpected nfo check result: %q, %v", note, err)
+	}
+	note, err = checkPar2(rel)
+	if err != nil || note != "2 par2 files" {
+		t.Errorf("Unexpected par2 check result: %q, %v", note, err)
+	}
+
+	_, err = checkNFO(&types.Release{NZB: "not an nzb"})
+	if err == nil {
+		t.Errorf("Expected an error for a broken NZB")
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	tests := []struct {
+		Category types.Category
+		File     string
+		Hidden   bool
+	}{
+		{types.TV_HD, `Show [1/2] - "show.mkv" yEnc`, false},
+		{types.TV_HD, `Show [2/2] - "password.txt" yEnc`, true},
+		{types.Movie_HD, `Movie [2/2] - "movie.720p.exe" yEnc`, true},
+		{types.PC_0day, `App [2/2] - "setup.exe" yEnc`, false},
+		{types.Console_Xbox360, `Game [2/2] - "pw.txt" yEnc`, true},
+		{types.Movie_HD, `Movie [2/2] - "Passwort.TXT" yEnc`, true},
+		{types.Movie_HD, `Password [1/2] - "Password.2019.1080p.BluRay.x264.mkv" yEnc`, false},
+		{types.TV_HD, `Show [1/2] - "The.Password.S01E01.720p.HDTV.x264.mkv" yEnc`, false},
+	}
+	for _, tc := range tests {
+		_, err := checkPassword(releaseWithFiles(t, tc.Category, tc.File))
+		_, hidden := err.(*HideError)
+		if hidden != tc.Hidden {
+			t.Errorf("Expected %s in %s to be hidden: %v, got %v", tc.File, tc.Category, tc.Hidden, err)
+		}
+	}
+}
+
+func TestMatchMetadata(t *testing.T) {
+	tests := []struct {
+		Category types.Category
+		Name     string
+		Note     string
+	}{
+		{types.TV_HD, "Sleepy.Hollow.S02E05.720p.HDTV.x264-KILLERS", `tv show "Sleepy Hollow"`},
+		{types.Movie_HD, "Mad.Max.Fury.Road.2015.1080p.BluRay.x264-SPARKS", `movie "Mad Max Fury Road" (2015)`},
+		{types.Audio_MP3, "Radiohead-OK_Computer-1997-MP3-320kbps-GRP", `album "OK Computer" by "Radiohead"`},
+		{types.Book_Ebook, "Stephen King - It (1986) [EPUB]", `book "It" by "Stephen King"`},
+		{types.Other_Misc, "Sleepy.Hollow.S02E05.720p.HDTV.x264-KILLERS", "no metadata"},
+	}
+	for _, tc := range tests {
+		rel := &types.Release{
+			Name:       tc.Name,
+			CategoryID: sql.NullInt64{Int64: int64(tc.Category), Valid: true},
+		}
+		note, err := matchMetadata(rel)
+		if err != nil || note != tc.Note {
+			t.Errorf("Expected %s in %s to match %q, got %q, %v", tc.Name, tc.Category, tc.Note, note, err)
+		}
+	}
+}
+
+func TestStagesAreChained(t *testing.T) {
+	from := types.ReleaseNew
+	for _, s := range Stages() {
+		if s.From != from {
+			t.Errorf("Stage %s starts at %d, expected %d", s.Name, s.From, from)
+		}
+		from = s.To
+	}
+	if from != types.ReleaseMetadataMatched {
+		t.Errorf("Last stage ends at %d, expected %d", from, types.ReleaseMetadataMatched)
+	}
+}
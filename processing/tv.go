@@ -3,6 +3,7 @@ package processing
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -88,9 +89,9 @@ func cleanName(name string) string {
 
 var (
 	// S01E01-E02 and S01E01-02
-	seasonEpRegex1 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]s(?P<season>\d{1,2})[^a-z0-9]?e(?P<episode>\d{1,3})(?:[e-])(?P<lastepisode>\d{1,3})[^a-z0-9]`)
+	seasonEpRegex1 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]s(?P<season>\d{1,2})[^a-z0-9]?e(?P<episode>\d{1,3})(?:-?e|-)(?P<lastepisode>\d{1,3})[^a-z0-9]`)
 	//S01E0102 and S01E01E02 - lame no delimit numbering, regex would collide if there was ever 1000 ep season.
-	seasonEpRegex2 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]s(?P<season>\d{2})[^a-z0-9]?e(?P<episode>\d{2})e?(\d{2})[^a-z0-9]`)
+	seasonEpRegex2 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]s(?P<season>\d{2})[^a-z0-9]?e(?P<episode>\d{2})e?(?P<lastepisode>\d{2})[^a-z0-9]`)
 	// S01E01 and S01.E01
 	seasonEpRegex3 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]s(?P<season>\d{1,2})[^a-z0-9]?e(?P<episode>\d{1,3})[abr]?[^a-z0-9]`)
 	// S01
@@ -112,28 +113,75 @@ var (
 	// 01.01.09
 	seasonEpRegex9 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9](\d{2})[^a-z0-9](\d{2})[^a-z0-9](\d{2})[^a-z0-9]`)
 	// 2009.E01
-	seasonEpRegex10 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]20(\d{2})[^a-z0-9](\d{1,3})[^a-z0-9]`)
+	seasonEpRegex10 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9](20\d{2})[^a-z0-9]e?(\d{1,3})[^a-z0-9]`)
 	// 2009.Part1
-	seasonEpRegex11 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9](19|20)(\d{2})[^a-z0-9]Part(\d{1,2})[^a-z0-9]`)
+	seasonEpRegex11 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]((?:19|20)\d{2})[^a-z0-9]Part[^a-z0-9]?(\d{1,2})[^a-z0-9]`)
 
 	// Randos
 	// Part1/Pt1
-	seasonEpRegex12 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9](?:Part|Pt)[^a-z0-9](\d{1,2})[^a-z0-9]`)
+	seasonEpRegex12 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9](?:Part|Pt)[^a-z0-9]?(\d{1,2})[^a-z0-9]`)
 	//The.Pacific.Pt.VI.HDTV.XviD-XII / Part.IV
-	seasonEpRegex13 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9](?:Part|Pt)[^a-z0-9]([ivx]+)`)
+	seasonEpRegex13 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9](?:Part|Pt)[^a-z0-9]([ivx]+)[^a-z0-9]`)
 	// Band.Of.Brothers.EP06.Bastogne.DVDRiP.XviD-DEiTY
-	seasonEpRegex14 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]EP?[^a-z0-9]?(\d{1,3})`)
+	seasonEpRegex14 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]EP?[^a-z0-9]?(\d{1,3})[^a-z0-9]`)
 	// Season.1
-	seasonEpRegex15 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]Seasons?[^a-z0-9]?(\d{1,2})`)
+	seasonEpRegex15 = regexp.MustCompile(`(?i)^(.*?)[^a-z0-9]Seasons?[^a-z0-9]?(\d{1,2})[^a-z0-9]`)
 )
 
 type showSeasonEp struct {
 	Season      string
 	Episode     string
-	LastEpisode string
+	LastEpisode string // Last episode of a multi episode release.
 	Airdate     string
 }
 
+// makeAirdate returns the season and episode of a daily show aired on the
+// given date.  Returns nil if the date isn't valid.
+func makeAirdate(year, month, day string) *showSeasonEp {
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return nil
+	}
+	return &showSeasonEp{
+		Season:  year,
+		Episode: month + "/" + day,
+		Airdate: year + "." + month + "." + day,
+	}
+}
+
+// dayMonthAirdate returns the airdate of a DD.MM.YYYY date, or MM.DD.YYYY if
+// the second number can't be a month.
+func dayMonthAirdate(year, first, second string) *showSeasonEp {
+	if n, _ := strconv.Atoi(second); n > 12 {
+		return makeAirdate(year, first, second)
+	}
+	return makeAirdate(year, second, first)
+}
+
+var romanValues = map[rune]int{'i': 1, 'v': 5, 'x': 10, 'l': 50, 'c': 100}
+
+// romanToInt converts a roman numeral like VI to an integer.  Returns 0 if
+// it isn't one.
+func romanToInt(s string) int {
+	total := 0
+	prev := 0
+	runes := []rune(strings.ToLower(s))
+	for i := len(runes) - 1; i >= 0; i-- {
+		v, ok := romanValues[runes[i]]
+		if !ok {
+			return 0
+		}
+		if v < prev {
+			total -= v
+		} else {
+			total += v
+			prev = v
+		}
+	}
+	return total
+}
+
 func parseSeasonEp(name string) *showSeasonEp {
 	for _, r := range regularShowRegexes {
 		reu := types.RegexpUtil{Regex: r}
@@ -147,20 +195,56 @@ func parseSeasonEp(name string) *showSeasonEp {
 				sse.Episode = strings.TrimLeft(s, "0")
 			}
 			if s, ok := m["lastepisode"]; ok {
-				sse.LastEpisode = s
+				sse.LastEpisode = strings.TrimLeft(s, "0")
 			}
 			return sse
 		}
 	}
 	m := seasonEpRegex7.FindStringSubmatch(name)
 	if m != nil {
-		return &showSeasonEp{
-			Season:  m[3] + m[4],
-			Episode: m[5] + "/" + m[6],
-			Airdate: m[2],
+		if sse := makeAirdate(m[3]+m[4], m[5], m[6]); sse != nil {
+			return sse
+		}
+	}
+	m = seasonEpRegex8.FindStringSubmatch(name)
+	if m != nil {
+		if sse := dayMonthAirdate(m[5]+m[6], m[3], m[4]); sse != nil {
+			return sse
 		}
 	}
-	//TODO: add the rest
+	m = seasonEpRegex9.FindStringSubmatch(name)
+	if m != nil {
+		if sse := dayMonthAirdate("20"+m[4], m[2], m[3]); sse != nil {
+			return sse
+		}
+	}
+	m = seasonEpRegex10.FindStringSubmatch(name)
+	if m != nil {
+		return &showSeasonEp{Season: m[2], Episode: strings.TrimLeft(m[3], "0")}
+	}
+	m = seasonEpRegex11.FindStringSubmatch(name)
+	if m != nil {
+		return &showSeasonEp{Season: m[2], Episode: strings.TrimLeft(m[3], "0")}
+	}
+	// Parts and episodes without a season are counted as season 1.
+	m = seasonEpRegex12.FindStringSubmatch(name)
+	if m != nil {
+		return &showSeasonEp{Season: "1", Episode: strings.TrimLeft(m[2], "0")}
+	}
+	m = seasonEpRegex13.FindStringSubmatch(name)
+	if m != nil {
+		if n := romanToInt(m[2]); n > 0 {
+			return &showSeasonEp{Season: "1", Episode: strconv.Itoa(n)}
+		}
+	}
+	m = seasonEpRegex14.FindStringSubmatch(name)
+	if m != nil {
+		return &showSeasonEp{Season: "1", Episode: strings.TrimLeft(m[2], "0")}
+	}
+	m = seasonEpRegex15.FindStringSubmatch(name)
+	if m != nil {
+		return &showSeasonEp{Season: strings.TrimLeft(m[2], "0")}
+	}
 	return nil
 }
 
@@ -170,8 +254,17 @@ type TVNameParseResult struct {
 	CleanedName string
 	Country     string
 	Episode     string
+	LastEpisode string // Set for multi episode releases, e.g. S01E01-E02.
 	Season      string
-	Airdate     string
+	Airdate     string // YYYY.MM.DD for daily shows.
+	Year        string // Year in the name of shows that aren't daily.
+}
+
+// NameKey returns the key the names of shows, movies, artists, albums,
+// authors and books are matched and searched on: the name cleaned by
+// cleanName in lower case.
+func NameKey(name string) string {
+	return strings.ToLower(cleanName(name))
 }
 
 // ParseInfo returns information about the given tv show release
@@ -186,6 +279,7 @@ func ParseInfo(name string) (*TVNameParseResult, error) {
 	}
 	res.Season = sse.Season
 	res.Episode = sse.Episode
+	res.LastEpisode = sse.LastEpisode
 	res.Airdate = sse.Airdate
 
 	if res.Airdate == "" {
@@ -193,6 +287,7 @@ func ParseInfo(name string) (*TVNameParseResult, error) {
 		m := tvYearRegex.FindStringSubmatchMap(name)
 		if len(m) > 0 {
 			res.CleanedName = fmt.Sprintf("%s (%s)", res.CleanedName, m["year"])
+			res.Year = m["year"]
 		}
 	}
 	if (res.Season == "" && res.Episode == "") && res.Airdate == "" {
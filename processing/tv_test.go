@@ -76,3 +76,93 @@ func TestClean(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSeasonEp(t *testing.T) {
+	expectations := []struct {
+		name string
+		sse  *showSeasonEp
+	}{
+		// S01E01-E02 and S01E01-02
+		{"The.Flash.2014.S02E01-E02.720p.HDTV.x264-KILLERS", &showSeasonEp{Season: "2", Episode: "1", LastEpisode: "2"}},
+		{"Doctor.Who.2005.S09E11-12.Heaven.Sent.1080p.WEB-DL.DD5.1.H264-GRP", &showSeasonEp{Season: "9", Episode: "11", LastEpisode: "12"}},
+		// S01E0102 and S01E01E02
+		{"Lost.S06E17E18.The.End.720p.BluRay.x264-SiNNERS", &showSeasonEp{Season: "6", Episode: "17", LastEpisode: "18"}},
+		{"24.S08E0102.HDTV.XviD-2HD", &showSeasonEp{Season: "8", Episode: "1", LastEpisode: "2"}},
+		// S01E01 and S01.E01
+		{"Sleepy.Hollow.S03E01.720p.HDTV.x264-AVS", &showSeasonEp{Season: "3", Episode: "1"}},
+		{"Game.of.Thrones.S05E10.Mothers.Mercy.1080p.WEB-DL.DD5.1.H.264-NTb", &showSeasonEp{Season: "5", Episode: "10"}},
+		{"The.Simpsons.S27.E13.Gal.of.Constant.Sorrow.720p.WEB-DL.DD5.1.H.264-NTb", &showSeasonEp{Season: "27", Episode: "13"}},
+		{"Grey's.Anatomy.S12E09r.HDTV.x264-LOL", &showSeasonEp{Season: "12", Episode: "9"}},
+		{"One.Piece.S01E108.720p.WEB-DL.AAC2.0.H.264-GRP", &showSeasonEp{Season: "1", Episode: "108"}},
+		// S01
+		{"Breaking.Bad.S05.1080p.BluRay.x264-ROVERS", &showSeasonEp{Season: "5"}},
+		{"The.Wire.S01.Complete.DVDRip.XviD-GRP", &showSeasonEp{Season: "1"}},
+		// S01D1
+		{"Friends.S03D2.PAL.DVDR-GRP", &showSeasonEp{Season: "3"}},
+		// 1x01
+		{"Top.Gear.22x03.HDTV.x264-FoV", &showSeasonEp{Season: "22", Episode: "3"}},
+		{"Supernatural 11x12 Just My Imagination HDTV XviD-AFG", &showSeasonEp{Season: "11", Episode: "12"}},
+		// 2009.01.01 and 2009-01-01
+		{"NBC.Nightly.News.2016.02.17.WEB-DL.x264-2Maverick", &showSeasonEp{Season: "2016", Episode: "02/17", Airdate: "2016.02.17"}},
+		{"The.Daily.Show.with.Trevor.Noah.2016-02-08.Gillian.Jacobs.720p.CC.AAC2.0.x264-monkee", &showSeasonEp{Season: "2016", Episode: "02/08", Airdate: "2016.02.08"}},
+		{"Conan.2016.02.17.Jon.Heder.720p.HDTV.x264-CROOKS", &showSeasonEp{Season: "2016", Episode: "02/17", Airdate: "2016.02.17"}},
+		// 01.01.2009
+		{"Neighbours.17.02.2016.PDTV.x264-FQM", &showSeasonEp{Season: "2016", Episode: "02/17", Airdate: "2016.02.17"}},
+		{"EastEnders.02.03.2016.WEB-DL.x264-GRP", &showSeasonEp{Season: "2016", Episode: "03/02", Airdate: "2016.03.02"}},
+		{"Jeopardy.02.17.2016.HDTV.x264-NBS", &showSeasonEp{Season: "2016", Episode: "02/17", Airdate: "2016.02.17"}},
+		// 01.01.09
+		{"Coronation.Street.21.01.16.PDTV.x264-FQM", &showSeasonEp{Season: "2016", Episode: "01/21", Airdate: "2016.01.21"}},
+		{"Emmerdale.05.11.15.WEB-DL.x264-GRP", &showSeasonEp{Season: "2015", Episode: "11/05", Airdate: "2015.11.05"}},
+		// 2009.E01
+		{"Wimbledon.2015.E05.720p.HDTV.x264-GRP", &showSeasonEp{Season: "2015", Episode: "5"}},
+		{"Tour.de.France.2015.12.Stage.12.720p.HDTV.x264-GRP", &showSeasonEp{Season: "2015", Episode: "12"}},
+		// 2009.Part1
+		{"Planet.Earth.2006.Part3.DVDRip.XviD-GRP", &showSeasonEp{Season: "2006", Episode: "3"}},
+		{"The.Jinx.2015.Part.2.720p.HDTV.x264-GRP", &showSeasonEp{Season: "2015", Episode: "2"}},
+		// Part1/Pt1
+		{"Mark.Of.Cain.Part.1.PDTV.XviD-GRP", &showSeasonEp{Season: "1", Episode: "1"}},
+		{"The.Night.Manager.Pt2.720p.HDTV.x264-GRP", &showSeasonEp{Season: "1", Episode: "2"}},
+		// Part.IV
+		{"The.Pacific.Pt.VI.HDTV.XviD-XII", &showSeasonEp{Season: "1", Episode: "6"}},
+		{"Band.of.Brothers.Part.IV.DVDRip.XviD-GRP", &showSeasonEp{Season: "1", Episode: "4"}},
+		{"The.Lost.Kingdoms.Part.IX.Finale.HDTV.x264-GRP", &showSeasonEp{Season: "1", Episode: "9"}},
+		// EP06
+		{"Band.Of.Brothers.EP06.Bastogne.DVDRiP.XviD-DEiTY", &showSeasonEp{Season: "1", Episode: "6"}},
+		{"Naruto.Shippuden.E455.720p.HDTV.x264-GRP", &showSeasonEp{Season: "1", Episode: "455"}},
+		// Season.1
+		{"Fargo.Season.2.1080p.BluRay.x264-GRP", &showSeasonEp{Season: "2"}},
+		{"Seinfeld.Seasons.1.DVDRip.XviD-GRP", &showSeasonEp{Season: "1"}},
+		// Not TV
+		{"Some.Movie.2015.720p.BluRay.x264-GRP", nil},
+		{"Artist-Album-2015-FLAC", nil},
+		{"Part.Time.Lover.DVDRip.XviD-GRP", nil},
+	}
+	for _, ex := range expectations {
+		res := parseSeasonEp(ex.name)
+		if !reflect.DeepEqual(ex.sse, res) {
+			t.Errorf("Diff in parseSeasonEp output for %s", ex.name)
+			fmt.Println("Expected:")
+			spew.Dump(ex.sse)
+			fmt.Println("Got:")
+			spew.Dump(res)
+		}
+	}
+}
+
+func TestRomanToInt(t *testing.T) {
+	expectations := map[string]int{
+		"I":    1,
+		"iv":   4,
+		"VI":   6,
+		"IX":   9,
+		"XIV":  14,
+		"xxii": 22,
+		"XL":   40,
+		"abc":  0,
+	}
+	for s, n := range expectations {
+		if res := romanToInt(s); res != n {
+			t.Errorf("Expected %s to convert to %d got %d", s, n, res)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package types
 
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
 // Category represents what type of thing a release is
 type Category int
 
@@ -79,9 +85,9 @@ const (
 	XXX_SD       Category = 6080
 	XXX_WEBDL    Category = 6090
 
-	Book_Ebook     Category = 7010
-	Book_Comics    Category = 7020
-	Book_Magazines Category = 7030
+	Book_Magazines Category = 7010
+	Book_Ebook     Category = 7020
+	Book_Comics    Category = 7030
 	Book_Technical Category = 7040
 	Book_Other     Category = 7050
 	Book_Foreign   Category = 7060
@@ -150,9 +156,9 @@ var categoryMap = map[Category]string{
 	6070: "XXX_Packs",
 	6080: "XXX_SD",
 	6090: "XXX_WEBDL",
-	7010: "Book_Ebook",
-	7020: "Book_Comics",
-	7030: "Book_Magazines",
+	7010: "Book_Magazines",
+	7020: "Book_Ebook",
+	7030: "Book_Comics",
 	7040: "Book_Technical",
 	7050: "Book_Other",
 	7060: "Book_Foreign",
@@ -162,14 +168,95 @@ func (c Category) String() string {
 	if str, ok := categoryMap[c]; ok {
 		return str
 	}
+	customCategories.RLock()
+	defer customCategories.RUnlock()
+	if str, ok := customCategories.names[c]; ok {
+		return str
+	}
 	return "Unknown"
 }
 
 // CategoryFromInt returns a Category corresponding to the given int or Unknown
-// if there isn't one.
+// if there isn't one.  Registered custom categories are known too.
 func CategoryFromInt(i int64) Category {
 	if _, ok := categoryMap[Category(i)]; ok {
 		return Category(i)
 	}
+	customCategories.RLock()
+	defer customCategories.RUnlock()
+	if _, ok := customCategories.names[Category(i)]; ok {
+		return Category(i)
+	}
 	return Unknown
 }
+
+// AllCategories returns the Category constants in order.
+func AllCategories() []Category {
+	cats := make([]Category, 0, len(categoryMap))
+	for c := range categoryMap {
+		cats = append(cats, c)
+	}
+	sort.Sort(categorySlice(cats))
+	return cats
+}
+
+type categorySlice []Category
+
+func (s categorySlice) Len() int           { return len(s) }
+func (s categorySlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s categorySlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// IsKnown returns true if c is one of the Category constants.
+func (c Category) IsKnown() bool {
+	_, ok := categoryMap[c]
+	return ok
+}
+
+// Parent returns the parent category of c.  Misc and Hashed belong to Other.
+func (c Category) Parent() Category {
+	if c >= customCategoryMin {
+		return c / customCategoryFactor
+	}
+	return c / 1000 * 1000
+}
+
+// Site specific subcategories use the ID of their parent times 100 plus up
+// to 99, e.g. 500000-500099 for TV, so they can't clash with the constants
+// or categories newznab adds later.  Other can't have any.
+const (
+	customCategoryFactor = 100
+	customCategoryMin    = Console * customCategoryFactor
+)
+
+// CustomCategoryRange returns the lowest and highest ID a custom subcategory
+// of parent can have.
+func CustomCategoryRange(parent Category) (Category, Category, error) {
+	if parent == Other || parent.Parent() != parent || !parent.IsKnown() {
+		return Unknown, Unknown, fmt.Errorf("%d is not a parent category that can have custom subcategories", parent)
+	}
+	min := parent * customCategoryFactor
+	return min, min + customCategoryFactor - 1, nil
+}
+
+// IsCustom returns true if c is in the range of custom subcategories.
+func (c Category) IsCustom() bool {
+	min, max, err := CustomCategoryRange(c.Parent())
+	return err == nil && c >= min && c <= max
+}
+
+var customCategories = struct {
+	sync.RWMutex
+	names map[Category]string
+}{names: map[Category]string{}}
+
+// RegisterCustomCategory makes a custom subcategory known to String and
+// CategoryFromInt.
+func RegisterCustomCategory(c Category, name string) error {
+	if !c.IsCustom() {
+		return fmt.Errorf("%d is not in the range of custom categories", c)
+	}
+	customCategories.Lock()
+	defer customCategories.Unlock()
+	customCategories.names[c] = name
+	return nil
+}
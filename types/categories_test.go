@@ -11,3 +11,40 @@ func TestGen(t *testing.T) {
 		t.Fatalf("Expected string rep of -1 to be 'Unknown', got %s", unk)
 	}
 }
+
+func TestCustomCategories(t *testing.T) {
+	min, max, err := CustomCategoryRange(TV)
+	if err != nil || min != 500000 || max != 500099 {
+		t.Fatalf("Expected TV custom range 500000-500099, got %d-%d (%v)", min, max, err)
+	}
+	for _, c := range []Category{Other, TV_HD, Category(4500)} {
+		if _, _, err := CustomCategoryRange(c); err == nil {
+			t.Errorf("Expected %d not to have custom categories", c)
+		}
+	}
+	parents := map[Category]Category{
+		Other_Misc:       Other,
+		TV_HD:            TV,
+		Books:            Books,
+		Category(500042): TV,
+	}
+	for c, p := range parents {
+		if c.Parent() != p {
+			t.Errorf("Expected parent of %d to be %d, got %d", c, p, c.Parent())
+		}
+	}
+
+	uhd := Category(500010)
+	if CategoryFromInt(int64(uhd)) != Unknown {
+		t.Fatalf("Expected unregistered custom category to be unknown")
+	}
+	if err := RegisterCustomCategory(TV_HD, "TV_Nope"); err == nil {
+		t.Errorf("Expected registering a constant as custom category to fail")
+	}
+	if err := RegisterCustomCategory(uhd, "TV_UHD"); err != nil {
+		t.Fatalf("Error registering custom category: %v", err)
+	}
+	if CategoryFromInt(int64(uhd)) != uhd || uhd.String() != "TV_UHD" {
+		t.Errorf("Expected custom category %d to be TV_UHD, got %s", uhd, uhd)
+	}
+}
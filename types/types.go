@@ -15,6 +15,10 @@ type Group struct {
 	Name     string `sql:"unique"`
 	MinFiles int
 	MinSize  int64
+	MaxFiles int   // 0 means no limit
+	MaxSize  int64 // 0 means no limit
+	// Percentage of segments needed to make a release.  0 uses the default.
+	MinCompletion float64
 }
 
 //Release struct
@@ -27,17 +31,219 @@ type Release struct {
 	SearchName   string
 	OriginalName string
 	From         string
-	Status       int
+	Status       int // One of the Release states
 	Grabs        int
 	Size         int64
 	Group        Group
 	GroupID      sql.NullInt64
 	Category     DBCategory `gorm:"column:category"`
 	CategoryID   sql.NullInt64
-	NZB          string `sql:"size:0" gorm:"column:nzb"`
-	// Regex
+	NZB          string        `sql:"size:0" gorm:"column:nzb"` // Only set on releases made before ReleaseNZB.
+	RegexID      sql.NullInt64 // Release regex that named this release.
+	Completion   float64       // Percentage of segments available when released.
+	PreDBID      sql.NullInt64 `gorm:"column:predb_id"` // Pre the release was named from.
+	Nuked        bool
+	// Request ID from the subject in groups like alt.binaries.teevee, 0 if
+	// there isn't one.
+	RequestID     int64
+	RequestStatus int
+	// Recategorizing leaves the category of locked releases alone.
+	CategoryLocked bool
+	// Set when the name or status was edited by hand.  Renaming leaves the
+	// name of locked releases alone and post processing their status.
+	NameLocked   bool
+	StatusLocked bool
+	// Reposts and copies in other groups link to the first release made.
+	NormalizedName string        `sql:"index"`
+	Files          int           // Number of files in the NZB.
+	CanonicalID    sql.NullInt64 `sql:"index"`
 }
 
+// TVInfo is the show, season and episode parsed from the name of a TV
+// release.
+type TVInfo struct {
+	ID          int64
+	ReleaseID   int64         `sql:"unique"`
+	ShowName    string        // As it appears in the release name, e.g. Sleepy Hollow.
+	ShowKey     string        `sql:"index"` // ShowName as keyed by processing.NameKey.
+	Season      string        // Without leading zeros, the year for daily shows.
+	Episode     string        // Without leading zeros, MM/DD for daily shows.
+	LastEpisode int           // Last episode of a multi episode release, 0 otherwise.
+	Airdate     string        // YYYY-MM-DD for daily shows.
+	TVShowID    sql.NullInt64 `sql:"index" gorm:"column:tv_show_id"` // Show the name matched, if any.
+}
+
+//TableName sets the name of the table to use when querying the db
+func (t TVInfo) TableName() string {
+	return "tv_info"
+}
+
+// TVShow is a show imported from a TVMaze, TVDB or TVRage dump.  TV releases
+// are matched to it by its title and aliases.  IDs are 0 when unknown.
+type TVShow struct {
+	ID       int64
+	TVMazeID int64 `sql:"index" gorm:"column:tvmaze_id"`
+	TVDBID   int64 `sql:"index" gorm:"column:tvdb_id"`
+	TVRageID int64 `sql:"index" gorm:"column:tvrage_id"`
+	Title    string
+	TitleKey string   `sql:"index"` // Title as release names are matched on.
+	Country  string   // Two letter country code.
+	Year     int      // Year the show premiered.
+	Aliases  []string `sql:"-"` // Saved as TVShowAliases.
+}
+
+//TableName sets the name of the table to use when querying the db
+func (t TVShow) TableName() string {
+	return "tv_show"
+}
+
+// TVShowAlias is another title of a TVShow, e.g. its name in another country.
+type TVShowAlias struct {
+	ID       int64
+	TVShowID int64 `sql:"index" gorm:"column:tv_show_id"`
+	Alias    string
+	AliasKey string `sql:"index"`
+}
+
+//TableName sets the name of the table to use when querying the db
+func (t TVShowAlias) TableName() string {
+	return "tv_show_alias"
+}
+
+// MovieInfo is the title, year and quality parsed from the name of a movie
+// release.  Fields that aren't in the name are empty.
+type MovieInfo struct {
+	ID           int64
+	ReleaseID    int64 `sql:"unique"`
+	Title        string
+	TitleKey     string `sql:"index"` // Title as movies are matched on.
+	Year         int
+	Resolution   string        // e.g. 1080p.
+	Source       string        // e.g. BluRay.
+	Codec        string        // e.g. x264.
+	ReleaseGroup string        // Group that made the release, not the usenet group.
+	MovieID      sql.NullInt64 `sql:"index"` // Movie the title matched, if any.
+}
+
+//TableName sets the name of the table to use when querying the db
+func (m MovieInfo) TableName() string {
+	return "movie_info"
+}
+
+// MusicInfo is the artist, album and format parsed from the name of a music
+// release.  Fields that aren't in the name are empty.
+type MusicInfo struct {
+	ID           int64
+	ReleaseID    int64 `sql:"unique"`
+	Artist       string
+	ArtistKey    string `sql:"index"` // Artist as it's searched on.
+	Album        string
+	AlbumKey     string `sql:"index"` // Album as it's searched on.
+	Year         int
+	Format       string // e.g. MP3 or FLAC.
+	Bitrate      string // e.g. 320kbps, V0 or 24bit.
+	ReleaseGroup string // Group that made the release, not the usenet group.
+}
+
+//TableName sets the name of the table to use when querying the db
+func (m MusicInfo) TableName() string {
+	return "music_info"
+}
+
+// BookInfo is the author and title parsed from the name of an ebook or
+// audiobook release.  Fields that aren't in the name are empty.
+type BookInfo struct {
+	ID        int64
+	ReleaseID int64 `sql:"unique"`
+	Author    string
+	AuthorKey string `sql:"index"` // Author as it's searched on.
+	Title     string
+	TitleKey  string `sql:"index"` // Title as it's searched on.
+	Year      int
+	Format    string // e.g. EPUB, PDF or M4B.
+}
+
+//TableName sets the name of the table to use when querying the db
+func (b BookInfo) TableName() string {
+	return "book_info"
+}
+
+// Movie is a movie imported from an IMDB or TMDB dump.  Movie releases are
+// matched to it by title and year.  IDs are 0 when unknown.
+type Movie struct {
+	ID       int64
+	IMDBID   int64 `sql:"index" gorm:"column:imdb_id"`
+	TMDBID   int64 `sql:"index" gorm:"column:tmdb_id"`
+	Title    string
+	TitleKey string `sql:"index"`
+	Year     int
+}
+
+//TableName sets the name of the table to use when querying the db
+func (m Movie) TableName() string {
+	return "movie"
+}
+
+// DeletedRelease remembers a release deleted by hand so it isn't made again.
+type DeletedRelease struct {
+	ID        int64
+	Hash      string `sql:"unique"`
+	Name      string
+	CreatedAt time.Time // When the release was deleted.
+}
+
+// ReleaseNZB is the gzip compressed NZB of a release.  Data is empty when
+// NZBs are stored on disk, they're named after their Hash there.
+type ReleaseNZB struct {
+	ID        int64
+	ReleaseID int64  `sql:"unique"`
+	Hash      string `sql:"index"` // SHA1 of the uncompressed NZB.
+	Size      int64  // Uncompressed size.
+	Data      []byte `sql:"size:0"`
+}
+
+// Release states.  New releases go through the post processing stages in
+// this order until their metadata is matched or a stage fails or hides them.
+const (
+	ReleaseNew = iota
+	ReleaseNFOChecked
+	ReleasePar2Checked
+	ReleasePasswordChecked
+	ReleaseMetadataMatched
+	ReleaseFailed
+	ReleaseHidden
+)
+
+// ReleaseStatusNames maps the Release states to their names.
+var ReleaseStatusNames = map[int]string{
+	ReleaseNew:             "new",
+	ReleaseNFOChecked:      "nfo checked",
+	ReleasePar2Checked:     "par2 checked",
+	ReleasePasswordChecked: "password checked",
+	ReleaseMetadataMatched: "metadata matched",
+	ReleaseFailed:          "failed",
+	ReleaseHidden:          "hidden",
+}
+
+// ReleaseStage records the attempts to run a post processing stage on a
+// release.
+type ReleaseStage struct {
+	ID            int64
+	ReleaseID     int64 `sql:"index"`
+	Stage         string
+	Attempts      int
+	LastAttemptAt time.Time
+	CompletedAt   time.Time // Zero until the stage succeeded.
+	Result        string    // What the stage found or why it failed.
+}
+
+// Request ID states of a Release.
+const (
+	RequestIDNone       = iota // No request ID in the subject.
+	RequestIDUnresolved        // Not in the request ID table yet, retried later.
+	RequestIDResolved          // Named from the request ID table.
+)
+
 // CategoryName returns the constant Category of the Release's Category
 func (r *Release) CategoryName() Category {
 	if !r.CategoryID.Valid {
@@ -57,8 +263,8 @@ type Binary struct {
 	Xref       string `sql:"size:1024"`
 	GroupName  string
 	Parts      []Part
-	//Regex
-	//RegexID
+	RegexID    sql.NullInt64 // Collection regex that produced this binary.
+	CreatedAt  time.Time
 }
 
 // Size computes size of Binary
@@ -85,6 +291,7 @@ type Part struct {
 	Binary        Binary
 	BinaryID      sql.NullInt64
 	Segments      []Segment
+	CreatedAt     time.Time
 }
 
 //Segment struct
@@ -104,6 +311,7 @@ type MissedMessage struct {
 	MessageNumber int64
 	GroupName     string
 	Attempts      int
+	CreatedAt     time.Time
 }
 
 // Regex Comment
@@ -143,6 +351,90 @@ func (r Regex) TableName() string {
 	return "regex"
 }
 
+// CategoryRule puts releases whose name and group match its regexes and
+// whose size is in range in a category.  Enabled rules are tried in order of
+// Ordinal before the built in categorizer.
+type CategoryRule struct {
+	ID            int64
+	Ordinal       int    `sql:"index"`
+	NameRegex     string `sql:"size:2048"` // Empty matches every name.
+	GroupRegex    string // Empty matches every group.
+	MinSize       int64  // 0 means no limit
+	MaxSize       int64  // 0 means no limit
+	CategoryID    int64
+	Enabled       bool
+	Description   string
+	CompiledName  *regexp.Regexp `sql:"-"` // Ignore for DB
+	CompiledGroup *regexp.Regexp `sql:"-"` // Ignore for DB
+}
+
+// Compile compiles NameRegex and GroupRegex into CompiledName and
+// CompiledGroup.
+func (r *CategoryRule) Compile() error {
+	c, err := regexp.Compile(r.NameRegex)
+	if err != nil {
+		return err
+	}
+	r.CompiledName = c
+	c, err = regexp.Compile(r.GroupRegex)
+	if err != nil {
+		return err
+	}
+	r.CompiledGroup = c
+	return nil
+}
+
+// Matches returns true if a release with the given name, group and size
+// matches the rule.  The rule must be compiled.
+func (r *CategoryRule) Matches(name, group string, size int64) bool {
+	switch {
+	case r.MinSize > 0 && size < r.MinSize:
+		return false
+	case r.MaxSize > 0 && size > r.MaxSize:
+		return false
+	case !r.CompiledGroup.MatchString(group):
+		return false
+	}
+	return r.CompiledName.MatchString(name)
+}
+
+// RegexHit counts how often a regex of the given kind has matched.
+type RegexHit struct {
+	ID          int64
+	Kind        string `sql:"index"`
+	RegexID     int    `sql:"index"`
+	Hits        int64
+	LastMatched time.Time
+}
+
+// PreDB is a scene pre of a release.  Pres are imported from dumps and used
+// to give releases their proper names.
+type PreDB struct {
+	ID         int64
+	Title      string `sql:"unique"`
+	Category   string // Section the pre was made in, e.g. TV-X264.
+	Size       int64
+	Files      int
+	Nuked      bool
+	NukeReason string
+	PreTime    time.Time
+	Source     string
+}
+
+//TableName sets the name of the table to use when querying the db
+func (p PreDB) TableName() string {
+	return "predb"
+}
+
+// RequestID is the name of a release requested in a group.  Posts filling
+// the request only carry the ID in their subject.
+type RequestID struct {
+	ID        int64
+	RequestID int64
+	GroupName string
+	Title     string
+}
+
 // DBCategory maps category information from the DB to a struct.  Information
 // should be mirrored in the Category constants.
 type DBCategory struct {